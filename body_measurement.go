@@ -0,0 +1,85 @@
+package fwt
+
+import (
+	"context"
+	"time"
+)
+
+// BodyMeasurement is a snapshot of a user's body taken at MeasuredAt.
+// Weight is in kilograms, BodyFat in percent, girths in centimetres and
+// RestingHeartRate in beats per minute. Zero means not measured.
+//
+// The weight of the most recent measurement that records one is mirrored
+// onto Profile.Weight.
+type BodyMeasurement struct {
+	ID               uint      `json:"id"`
+	UserID           uint      `json:"user_id"`
+	MeasuredAt       time.Time `json:"measured_at"`
+	Weight           float64   `json:"weight"`
+	BodyFat          float64   `json:"body_fat"`
+	Waist            float64   `json:"waist"`
+	Chest            float64   `json:"chest"`
+	Arms             float64   `json:"arms"`
+	Thighs           float64   `json:"thighs"`
+	RestingHeartRate uint      `json:"resting_heart_rate"`
+	Notes            string    `json:"notes"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (m *BodyMeasurement) Validate() error {
+	if m.UserID == uint(0) {
+		return Errorf(EINVALID, "UserID is required.")
+	}
+
+	if m.MeasuredAt.IsZero() {
+		return Errorf(EINVALID, "Measured At is required.")
+	}
+
+	if m.Weight < 0 || m.Waist < 0 || m.Chest < 0 || m.Arms < 0 || m.Thighs < 0 {
+		return Errorf(EINVALID, "Measurements cannot be negative.")
+	}
+
+	if m.BodyFat < 0 || m.BodyFat > 100 {
+		return Errorf(EINVALID, "Body Fat is invalid.")
+	}
+
+	if m.Weight == 0 && m.BodyFat == 0 && m.Waist == 0 && m.Chest == 0 &&
+		m.Arms == 0 && m.Thighs == 0 && m.RestingHeartRate == 0 {
+		return Errorf(EINVALID, "Body Measurement must record at least one value.")
+	}
+
+	return nil
+}
+
+type BodyMeasurementService interface {
+	FindBodyMeasurementByID(ctx context.Context, id uint) (*BodyMeasurement, error)
+	FindBodyMeasurements(ctx context.Context, filter BodyMeasurementFilter) ([]*BodyMeasurement, int, error)
+	CreateBodyMeasurement(ctx context.Context, m *BodyMeasurement) error
+	UpdateBodyMeasurement(ctx context.Context, id uint, upd BodyMeasurementUpdate) (*BodyMeasurement, error)
+	DeleteBodyMeasurement(ctx context.Context, id uint) error
+}
+
+// BodyMeasurementFilter selects measurements. From and To bound MeasuredAt
+// inclusively. Results are ordered by MeasuredAt, oldest first.
+type BodyMeasurementFilter struct {
+	ID     *uint      `json:"id"`
+	UserID *uint      `json:"user_id"`
+	From   *time.Time `json:"from"`
+	To     *time.Time `json:"to"`
+
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+type BodyMeasurementUpdate struct {
+	MeasuredAt       *time.Time `json:"measured_at"`
+	Weight           *float64   `json:"weight"`
+	BodyFat          *float64   `json:"body_fat"`
+	Waist            *float64   `json:"waist"`
+	Chest            *float64   `json:"chest"`
+	Arms             *float64   `json:"arms"`
+	Thighs           *float64   `json:"thighs"`
+	RestingHeartRate *uint      `json:"resting_heart_rate"`
+	Notes            *string    `json:"notes"`
+}
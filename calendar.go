@@ -0,0 +1,107 @@
+package fwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// CalendarDay is a day of the calendar with the workouts scheduled on it.
+// Date is formatted as YYYY-MM-DD.
+type CalendarDay struct {
+	Date     string     `json:"date"`
+	Status   string     `json:"status"`
+	Workouts []*Workout `json:"workouts"`
+}
+
+// GroupWorkoutsByDay groups workouts by their scheduled date, oldest first.
+// Days without workouts are left out.
+func GroupWorkoutsByDay(workouts []*Workout) []*CalendarDay {
+	byDate := make(map[string]*CalendarDay)
+	days := make([]*CalendarDay, 0)
+	for _, w := range workouts {
+		date := w.ScheduledDate.UTC().Format(time.DateOnly)
+		day, ok := byDate[date]
+		if !ok {
+			day = &CalendarDay{Date: date}
+			byDate[date] = day
+			days = append(days, day)
+		}
+		day.Workouts = append(day.Workouts, w)
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	for _, day := range days {
+		day.Status = DayStatus(day.Workouts)
+	}
+
+	return days
+}
+
+// DayStatus summarises the statuses of the workouts of a day. A day is
+// completed or skipped when all of its workouts are, planned while none
+// of them has been finished or skipped, and partially completed otherwise.
+func DayStatus(workouts []*Workout) string {
+	var completed, skipped, open int
+	for _, w := range workouts {
+		switch w.Status {
+		case WorkoutStatusCompleted:
+			completed++
+		case WorkoutStatusSkipped:
+			skipped++
+		case WorkoutStatusPlanned, WorkoutStatusInProgress:
+			open++
+		}
+	}
+
+	switch len(workouts) {
+	case completed:
+		return WorkoutStatusCompleted
+	case skipped:
+		return WorkoutStatusSkipped
+	case open:
+		return WorkoutStatusPlanned
+	}
+	return WorkoutStatusPartiallyCompleted
+}
+
+// CalendarFeed gives access to a user's upcoming workouts through a secret
+// URL that calendar apps can subscribe to. Only a hash of the token is
+// stored, so Token is set just once, when the feed is rotated.
+type CalendarFeed struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Token     string    `json:"token,omitempty"`
+	TokenHash string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewCalendarFeedToken returns a random feed token and its hash.
+func NewCalendarFeedToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashCalendarFeedToken(token), nil
+}
+
+// HashCalendarFeedToken returns the hash a feed token is stored under.
+func HashCalendarFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type CalendarFeedService interface {
+	// FindCalendarFeedByToken needs no user in ctx, since the token is the
+	// only credential a calendar app has.
+	FindCalendarFeedByToken(ctx context.Context, token string) (*CalendarFeed, error)
+
+	// RotateCalendarFeed gives the current user a new feed token. Any
+	// previous token stops working.
+	RotateCalendarFeed(ctx context.Context) (*CalendarFeed, error)
+}
@@ -1,35 +1,405 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"maps"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/maliByatzes/fwt"
 	"github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/inmem"
 	"github.com/maliByatzes/fwt/postgres"
+	"github.com/maliByatzes/fwt/sqlite"
+	"github.com/maliByatzes/fwt/token"
+	"github.com/maliByatzes/fwt/tracing"
 )
 
+const usage = `Usage:
+  fwt [serve] [-auto-migrate] [-memory]
+  fwt migrate up
+  fwt migrate down N|-all
+  fwt migrate status
+  fwt migrate force VERSION
+`
+
+// database is implemented by every storage backend.
+type database interface {
+	Open() error
+	Close() error
+	MigrateUp(ctx context.Context) error
+	MigrateDown(ctx context.Context, steps int) error
+	MigrateDownAll(ctx context.Context) error
+	MigrateForce(ctx context.Context, version int) error
+	MigrationStatus(ctx context.Context) (fwt.MigrationStatus, error)
+	CheckSchema(ctx context.Context) error
+	fwt.TxRunner
+	http.HealthChecker
+}
+
+var (
+	_ database = (*postgres.DB)(nil)
+	_ database = (*sqlite.DB)(nil)
+)
+
+// newDatabase picks the storage backend from the DSN scheme:
+// postgres:// or postgresql:// for PostgreSQL, and sqlite:// or sqlite:
+// followed by a file path (or ":memory:") for SQLite.
+func newDatabase(dsn string, logger *slog.Logger) (database, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		db := postgres.NewDB(dsn)
+		db.Logger = logger
+		return db, nil
+	case strings.HasPrefix(dsn, "sqlite:"):
+		path := strings.TrimPrefix(strings.TrimPrefix(dsn, "sqlite:"), "//")
+		db := sqlite.NewDB(path)
+		db.Logger = logger
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unsupported database URL %q: expected a postgres:// or sqlite:// scheme", dsn)
+	}
+}
+
+// newServer creates the HTTP server backed by the services of db.
+func newServer(db database, opts http.Options) (*http.Server, error) {
+	opts.Health = db
+	opts.TxRunner = db
+
+	switch db := db.(type) {
+	case *postgres.DB:
+		opts.UserService = postgres.NewUserService(db)
+		opts.ProfileService = postgres.NewProfileService(db)
+		opts.WorkoutService = postgres.NewWorkoutService(db)
+		opts.ExerciseService = postgres.NewExerciseService(db)
+		opts.WorkoutExerciseService = postgres.NewWorkoutExerciseService(db)
+		opts.WEStatusService = postgres.NewWEStatusService(db)
+		opts.BodyMeasurementService = postgres.NewBodyMeasurementService(db)
+		opts.GoalService = postgres.NewGoalService(db)
+		opts.CalendarFeedService = postgres.NewCalendarFeedService(db)
+		opts.IdempotencyService = postgres.NewIdempotencyService(db)
+	case *sqlite.DB:
+		opts.UserService = sqlite.NewUserService(db)
+		opts.ProfileService = sqlite.NewProfileService(db)
+		opts.WorkoutService = sqlite.NewWorkoutService(db)
+		opts.ExerciseService = sqlite.NewExerciseService(db)
+		opts.WorkoutExerciseService = sqlite.NewWorkoutExerciseService(db)
+		opts.WEStatusService = sqlite.NewWEStatusService(db)
+		opts.BodyMeasurementService = sqlite.NewBodyMeasurementService(db)
+		opts.GoalService = sqlite.NewGoalService(db)
+		opts.CalendarFeedService = sqlite.NewCalendarFeedService(db)
+		opts.IdempotencyService = sqlite.NewIdempotencyService(db)
+	default:
+		return nil, fmt.Errorf("unsupported database %T", db)
+	}
+
+	return http.NewServer(opts)
+}
+
+// newMemoryServer creates an HTTP server backed by an in-memory store seeded
+// with the default exercise catalog.
+func newMemoryServer(opts http.Options) (*http.Server, error) {
+	db := inmem.NewDB()
+	if err := db.SeedExercises(); err != nil {
+		return nil, fmt.Errorf("cannot seed exercises: %w", err)
+	}
+
+	opts.UserService = inmem.NewUserService(db)
+	opts.ProfileService = inmem.NewProfileService(db)
+	opts.WorkoutService = inmem.NewWorkoutService(db)
+	opts.ExerciseService = inmem.NewExerciseService(db)
+	opts.WorkoutExerciseService = inmem.NewWorkoutExerciseService(db)
+	opts.WEStatusService = inmem.NewWEStatusService(db)
+	opts.BodyMeasurementService = inmem.NewBodyMeasurementService(db)
+	opts.GoalService = inmem.NewGoalService(db)
+	opts.CalendarFeedService = inmem.NewCalendarFeedService(db)
+	opts.IdempotencyService = inmem.NewIdempotencyService(db)
+	opts.TxRunner = db
+	opts.Health = db
+
+	return http.NewServer(opts)
+}
+
 type config struct {
-	port      string
-	dbURL     string
-	jwtSecret string
+	port        string
+	dbURL       string
+	jwtSecret   string
+	exportDir   string
+	exportKey   string
+	proxies     []string
+	shutdown    time.Duration
+	rateLimit   map[string]string
+	logLevel    slog.Level
+	metrics     string
+	tracing     string
+	autoMigrate bool
+	memory      bool
+	isolation   string
 }
 
 func main() {
+	cmd, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "serve":
+		err = runServe(args)
+	case "migrate":
+		err = runMigrate(args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runServe(args []string) error {
 	cfg := envConfig()
 
-	db := postgres.NewDB(cfg.dbURL)
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.BoolVar(&cfg.autoMigrate, "auto-migrate", cfg.autoMigrate, "apply pending migrations before serving")
+	fs.BoolVar(&cfg.memory, "memory", false, "keep all data in memory instead of DATABASE_URL")
+	fs.Parse(args)
+
+	logger := slog.New(fwt.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.logLevel})))
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.tracing,
+		ServiceName: "fwt",
+		Version:     fwt.ReadBuildInfo().Version,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	tokenMaker, err := token.NewJWTMaker(cfg.jwtSecret)
+	if err != nil {
+		return fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	policies := http.DefaultRateLimitPolicies()
+	for name, v := range cfg.rateLimit {
+		policy, err := http.ParseRateLimitPolicy(name, v)
+		if err != nil {
+			return fmt.Errorf("cannot configure rate limit: %w", err)
+		}
+		policies[name] = policy
+	}
+
+	opts := http.Options{
+		TokenMaker: tokenMaker,
+		ExportKey:  []byte(cfg.exportKey),
+		Logger:     logger,
+		Config:     http.Config{RateLimitPolicies: policies, ExportDir: cfg.exportDir, TrustedProxies: cfg.proxies, ShutdownDelay: cfg.shutdown},
+	}
+
+	var srv *http.Server
+	if cfg.memory {
+		logger.Warn("serving from memory, all data will be lost on exit")
+		if srv, err = newMemoryServer(opts); err != nil {
+			return fmt.Errorf("cannot create new server: %w", err)
+		}
+	} else {
+		if cfg.dbURL == "" {
+			return fmt.Errorf("DATABASE_URL is not set")
+		}
+
+		db, err := newDatabase(cfg.dbURL, logger)
+		if err != nil {
+			return err
+		}
+		if pg, ok := db.(*postgres.DB); ok && cfg.isolation != "" {
+			levels, err := postgres.ParseIsolationLevels(cfg.isolation)
+			if err != nil {
+				return fmt.Errorf("cannot configure isolation levels: %w", err)
+			}
+			maps.Copy(pg.Isolation, levels)
+		}
+		if err := db.Open(); err != nil {
+			return fmt.Errorf("cannot open database: %w", err)
+		}
+		defer db.Close()
+
+		if cfg.autoMigrate {
+			if err := db.MigrateUp(context.Background()); err != nil {
+				return fmt.Errorf("cannot apply migrations: %w", err)
+			}
+		}
+		if err := db.CheckSchema(context.Background()); err != nil {
+			return fmt.Errorf("refusing to serve: %w", err)
+		}
+
+		if srv, err = newServer(db, opts); err != nil {
+			return fmt.Errorf("cannot create new server: %w", err)
+		}
+	}
+	if cfg.metrics != "" {
+		go func() {
+			if err := srv.RunMetrics(cfg.metrics); err != nil && err != nethttp.ErrServerClosed {
+				log.Fatalf("cannot run metrics server: %v", err)
+			}
+		}()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go purgeDeletedUsers(ctx, srv.UserService, srv.RemoveUserExports, logger)
+	go sweepExports(ctx, srv)
+
+	go func() {
+		if err := srv.Run(cfg.port); err != nil && err != nethttp.ErrServerClosed {
+			log.Fatalf("cannot run server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down")
+	if err := srv.Close(); err != nil {
+		logger.Error("cannot shut down server cleanly", "error", err)
+	}
+	return nil
+}
+
+// purgeDeletedUsers removes the accounts whose deletion grace period has
+// ended, along with their exports, then again every hour until ctx is done.
+func purgeDeletedUsers(ctx context.Context, users fwt.UserService, removeExports func(userID uint) error, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		purgeDueUsers(ctx, users, removeExports, logger, time.Now().Add(-fwt.UserDeletionGracePeriod))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeDueUsers removes the users deleted before the given time one at a
+// time, so that a user who cannot be purged does not hold back the others.
+func purgeDueUsers(ctx context.Context, users fwt.UserService, removeExports func(userID uint) error, logger *slog.Logger, before time.Time) {
+	due, _, err := users.FindUsers(ctx, fwt.UserFilter{DeletedBefore: &before})
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("cannot find deleted users", "error", err)
+		}
+		return
+	}
+
+	n := 0
+	for _, user := range due {
+		if err := users.PurgeDeletedUser(ctx, user.ID, before); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error("cannot purge deleted user", "user_id", user.ID, "error", err)
+			continue
+		}
+		if err := removeExports(user.ID); err != nil {
+			logger.Warn("cannot remove exports of purged user", "user_id", user.ID, "error", err)
+		}
+		n++
+	}
+	if n > 0 {
+		logger.Info("purged deleted users", "count", n)
+	}
+}
+
+// sweepExports removes expired data exports every hour until ctx is done.
+func sweepExports(ctx context.Context, srv *http.Server) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		srv.SweepExports(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	dbURL, ok := os.LookupEnv("DATABASE_URL")
+	if !ok {
+		return fmt.Errorf("DATABASE_URL is not set")
+	}
+
+	db, err := newDatabase(dbURL, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	if err != nil {
+		return err
+	}
 	if err := db.Open(); err != nil {
-		log.Fatalf("cannot open database: %v", err)
+		return fmt.Errorf("cannot open database: %w", err)
 	}
 	defer db.Close()
 
-	srv, err := http.NewServer(db, cfg.jwtSecret)
-	if err != nil {
-		log.Fatalf("cannot create new server: %v", err)
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		return db.MigrateUp(ctx)
+	case "down":
+		// Rolling back everything drops the whole schema, so it must be
+		// asked for explicitly rather than by leaving out the count.
+		if len(args) < 2 {
+			return fmt.Errorf("migrate down requires a number of steps, or -all to roll back every migration")
+		}
+		if args[1] == "-all" {
+			return db.MigrateDownAll(ctx)
+		}
+		steps, err := strconv.Atoi(args[1])
+		if err != nil || steps <= 0 {
+			return fmt.Errorf("invalid number of steps: %q", args[1])
+		}
+		return db.MigrateDown(ctx, steps)
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("migrate force requires a version")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version: %q", args[1])
+		}
+		return db.MigrateForce(ctx, version)
+	case "status":
+		status, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version: %d\nlatest:  %d\ndirty:   %t\npending: %t\n", status.Version, status.Latest, status.Dirty, status.Pending())
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
 	}
-	defer srv.Close()
-	log.Fatal(srv.Run(cfg.port))
+	return nil
 }
 
 func envConfig() config {
@@ -38,15 +408,67 @@ func envConfig() config {
 		panic("PORT is not set!")
 	}
 
-	dbURL, ok := os.LookupEnv("DATABASE_URL")
-	if !ok {
-		panic("DATABASE_URL is not set!")
-	}
+	// DATABASE_URL is optional here as serve -memory does not need it.
+	dbURL := os.Getenv("DATABASE_URL")
 
 	jwtSecret, ok := os.LookupEnv("JWT_SECRET")
 	if !ok {
 		panic("JWT_SECRET is not set!")
 	}
 
-	return config{port: port, dbURL: dbURL, jwtSecret: jwtSecret}
+	// EXPORT_DIR and EXPORT_SECRET are optional: exports default to a
+	// temporary directory and download links to a key made at startup.
+	exportDir := os.Getenv("EXPORT_DIR")
+	exportKey := os.Getenv("EXPORT_SECRET")
+
+	// TRUSTED_PROXIES is a comma separated list of the reverse proxies
+	// allowed to set X-Forwarded-For.
+	var proxies []string
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		proxies = strings.Split(v, ",")
+	}
+
+	// SHUTDOWN_DELAY is how long the server keeps serving after it reports
+	// not ready, e.g. "10s". It should outlast the readiness probe period.
+	var shutdown time.Duration
+	if v, ok := os.LookupEnv("SHUTDOWN_DELAY"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			panic("SHUTDOWN_DELAY is invalid!")
+		}
+		shutdown = d
+	}
+
+	rateLimit := make(map[string]string)
+	if v, ok := os.LookupEnv("RATE_LIMIT_AUTH"); ok {
+		rateLimit[http.RateLimitPolicyAuth] = v
+	}
+	if v, ok := os.LookupEnv("RATE_LIMIT_API"); ok {
+		rateLimit[http.RateLimitPolicyAPI] = v
+	}
+
+	var logLevel slog.Level
+	if v, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		if err := logLevel.UnmarshalText([]byte(v)); err != nil {
+			panic("LOG_LEVEL is invalid!")
+		}
+	}
+
+	metrics := os.Getenv("METRICS_ADDR")
+	tracing := os.Getenv("OTEL_TRACES_EXPORTER")
+
+	autoMigrate := false
+	if v, ok := os.LookupEnv("AUTO_MIGRATE"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			panic("AUTO_MIGRATE is invalid!")
+		}
+		autoMigrate = b
+	}
+
+	// DB_ISOLATION overrides the isolation level of postgres service methods,
+	// e.g. "WorkoutService.UpdateWorkout=serializable".
+	isolation := os.Getenv("DB_ISOLATION")
+
+	return config{port: port, dbURL: dbURL, jwtSecret: jwtSecret, exportDir: exportDir, exportKey: exportKey, proxies: proxies, shutdown: shutdown, rateLimit: rateLimit, logLevel: logLevel, metrics: metrics, tracing: tracing, autoMigrate: autoMigrate, isolation: isolation}
 }
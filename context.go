@@ -6,6 +6,7 @@ type contextKey int
 
 const (
 	userContextKey = contextKey(iota + 1)
+	requestIDContextKey
 )
 
 func NewContextWithUser(ctx context.Context, user *User) context.Context {
@@ -23,3 +24,12 @@ func UserIDFromContext(ctx context.Context) uint {
 	}
 	return 0
 }
+
+func NewContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
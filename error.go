@@ -12,6 +12,7 @@ const (
 	ENOTFOUND       = "not_found"
 	ENOTIMPLEMENTED = "not_implemented"
 	ENOTAUTHORIZED  = "unauthorized"
+	ESTALE          = "stale"
 )
 
 type Error struct {
@@ -11,6 +11,11 @@ type Exercise struct {
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Status and Sets are the status and logged sets of the exercise
+	// within a workout. They are only set on the exercises of a workout.
+	Status *WEStatus `json:"status,omitempty"`
+	Sets   []*Set    `json:"sets,omitempty"`
 }
 
 func (e *Exercise) Validate() error {
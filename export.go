@@ -0,0 +1,216 @@
+package fwt
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusReady   = "ready"
+	ExportStatusFailed  = "failed"
+)
+
+// Export is an archive of everything a user owns, built in the background
+// so that the request asking for it returns straight away.
+type Export struct {
+	ID        string    `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	ReadyAt   time.Time `json:"ready_at"`
+}
+
+// ExportData is the data written to an export. Profile is nil when the
+// user has none. The statuses and sets come from the exercises of Workouts.
+type ExportData struct {
+	User             *User
+	Profile          *Profile
+	Workouts         []*Workout
+	WorkoutExercises []*WorkoutExercise
+	BodyMeasurements []*BodyMeasurement
+	Goals            []*Goal
+}
+
+// WriteExportArchive writes data as a zip archive holding each kind of
+// record twice: as a JSON array and as a CSV file with a header row.
+// Quantities are in the metric units they are stored in.
+func WriteExportArchive(w io.Writer, data *ExportData) error {
+	var (
+		profiles      []*Profile
+		statuses      []*WEStatus
+		sets          []*Set
+		exerciseNames = make(map[uint]string)
+	)
+	if data.Profile != nil {
+		profiles = append(profiles, data.Profile)
+	}
+	for _, workout := range data.Workouts {
+		for _, ex := range workout.Exercises {
+			exerciseNames[ex.ID] = ex.Name
+			if ex.Status != nil {
+				statuses = append(statuses, ex.Status)
+			}
+			sets = append(sets, ex.Sets...)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+
+	if err := writeExportFile(zw, "user", []*User{data.User},
+		[]string{"id", "username", "email", "created_at", "updated_at"},
+		func(u *User) []string {
+			return []string{formatUint(u.ID), u.Username, u.Email, formatExportTime(u.CreatedAt), formatExportTime(u.UpdatedAt)}
+		}); err != nil {
+		return err
+	}
+
+	if err := writeExportFile(zw, "profile", profiles,
+		[]string{"id", "first_name", "last_name", "dob", "gender", "height_cm", "weight_kg", "timezone", "created_at", "updated_at"},
+		func(p *Profile) []string {
+			return []string{
+				formatUint(p.ID), p.FirstName, p.LastName, formatExportDate(p.DateOfBirth), p.Gender,
+				formatFloat(p.Height), formatFloat(p.Weight), p.Timezone,
+				formatExportTime(p.CreatedAt), formatExportTime(p.UpdatedAt),
+			}
+		}); err != nil {
+		return err
+	}
+
+	if err := writeExportFile(zw, "workouts", data.Workouts,
+		[]string{"id", "name", "scheduled_date", "status", "started_at", "finished_at", "duration_seconds", "created_at", "updated_at"},
+		func(w *Workout) []string {
+			return []string{
+				formatUint(w.ID), w.Name, formatExportDate(w.ScheduledDate), w.Status,
+				formatExportTime(w.StartedAt), formatExportTime(w.FinishedAt),
+				strconv.FormatInt(int64(w.Duration().Seconds()), 10),
+				formatExportTime(w.CreatedAt), formatExportTime(w.UpdatedAt),
+			}
+		}); err != nil {
+		return err
+	}
+
+	if err := writeExportFile(zw, "workout_exercises", data.WorkoutExercises,
+		[]string{"id", "workout_id", "exercise_id", "exercise_name", "order", "created_at", "updated_at"},
+		func(we *WorkoutExercise) []string {
+			return []string{
+				formatUint(we.ID), formatUint(we.WorkoutID), formatUint(we.ExerciseID), exerciseNames[we.ExerciseID],
+				formatUint(we.Order), formatExportTime(we.CreatedAt), formatExportTime(we.UpdatedAt),
+			}
+		}); err != nil {
+		return err
+	}
+
+	if err := writeExportFile(zw, "workout_exercise_statuses", statuses,
+		[]string{"id", "workout_exercise_id", "status", "comments", "completed_at", "created_at", "updated_at"},
+		func(s *WEStatus) []string {
+			return []string{
+				formatUint(s.ID), formatUint(s.WorkoutExerciseID), s.Status, s.Comments,
+				formatExportTime(s.CompletedAt), formatExportTime(s.CreatedAt), formatExportTime(s.UpdatedAt),
+			}
+		}); err != nil {
+		return err
+	}
+
+	if err := writeExportFile(zw, "sets", sets,
+		[]string{"id", "workout_exercise_id", "reps", "load_kg", "created_at"},
+		func(s *Set) []string {
+			return []string{
+				formatUint(s.ID), formatUint(s.WorkoutExerciseID), formatUint(s.Reps),
+				formatFloat(s.Load), formatExportTime(s.CreatedAt),
+			}
+		}); err != nil {
+		return err
+	}
+
+	if err := writeExportFile(zw, "body_measurements", data.BodyMeasurements,
+		[]string{"id", "measured_at", "weight_kg", "body_fat_percent", "waist_cm", "chest_cm", "arms_cm", "thighs_cm", "resting_heart_rate", "notes", "created_at", "updated_at"},
+		func(m *BodyMeasurement) []string {
+			return []string{
+				formatUint(m.ID), formatExportTime(m.MeasuredAt), formatFloat(m.Weight), formatFloat(m.BodyFat),
+				formatFloat(m.Waist), formatFloat(m.Chest), formatFloat(m.Arms), formatFloat(m.Thighs),
+				formatUint(m.RestingHeartRate), m.Notes, formatExportTime(m.CreatedAt), formatExportTime(m.UpdatedAt),
+			}
+		}); err != nil {
+		return err
+	}
+
+	if err := writeExportFile(zw, "goals", data.Goals,
+		[]string{"id", "type", "exercise_id", "target", "start", "target_date", "archived_at", "created_at", "updated_at"},
+		func(g *Goal) []string {
+			exerciseID := ""
+			if g.ExerciseID != 0 {
+				exerciseID = formatUint(g.ExerciseID)
+			}
+			return []string{
+				formatUint(g.ID), g.Type, exerciseID,
+				formatFloat(g.Target), formatFloat(g.Start), formatExportDate(g.TargetDate),
+				formatExportTime(g.ArchivedAt), formatExportTime(g.CreatedAt), formatExportTime(g.UpdatedAt),
+			}
+		}); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeExportFile writes records to name.json and, one row each, to
+// name.csv.
+func writeExportFile[T any](zw *zip.Writer, name string, records []T, header []string, row func(T) []string) error {
+	if records == nil {
+		records = []T{}
+	}
+
+	f, err := zw.Create(name + ".json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return err
+	}
+
+	f, err = zw.Create(name + ".csv")
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write(row(r)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatUint(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatExportTime formats t as RFC 3339 in UTC, leaving zero times empty.
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatExportDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.DateOnly)
+}
@@ -0,0 +1,155 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testBodyMeasurementService(t *testing.T, s *Services) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 8, 0, 0, 0, time.UTC)
+	}
+
+	mustCreate := func(tb testing.TB, ctx context.Context, m *fwt.BodyMeasurement) *fwt.BodyMeasurement {
+		tb.Helper()
+		m.UserID = fwt.UserIDFromContext(ctx)
+		require.NoError(tb, s.BodyMeasurementService.CreateBodyMeasurement(ctx, m))
+		return m
+	}
+
+	t.Run("CreateBodyMeasurement", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		m := mustCreate(t, ctx, &fwt.BodyMeasurement{MeasuredAt: day(1), Weight: 80.5, BodyFat: 18, Waist: 84, Notes: "Morning"})
+		require.NotZero(t, m.ID)
+		require.Equal(t, user.ID, m.UserID)
+		require.False(t, m.CreatedAt.IsZero())
+
+		other, err := s.BodyMeasurementService.FindBodyMeasurementByID(ctx, m.ID)
+		require.NoError(t, err)
+		require.Equal(t, 80.5, other.Weight)
+		require.Equal(t, float64(18), other.BodyFat)
+		require.Equal(t, "Morning", other.Notes)
+		require.True(t, day(1).Equal(other.MeasuredAt))
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		err := s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{UserID: user.ID, MeasuredAt: day(1)})
+		requireCode(t, err, fwt.EINVALID)
+
+		err = s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{UserID: user.ID, MeasuredAt: day(1), Weight: -1})
+		requireCode(t, err, fwt.EINVALID)
+
+		err = s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{UserID: user.ID, MeasuredAt: day(1), BodyFat: 120})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrUnauthenticated", func(t *testing.T) {
+		err := s.BodyMeasurementService.CreateBodyMeasurement(context.Background(), &fwt.BodyMeasurement{MeasuredAt: day(1), Weight: 70})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("FindBodyMeasurements", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+
+		mustCreate(t, ctx, &fwt.BodyMeasurement{MeasuredAt: day(10), Weight: 79})
+		mustCreate(t, ctx, &fwt.BodyMeasurement{MeasuredAt: day(1), Weight: 81})
+		mustCreate(t, ctx, &fwt.BodyMeasurement{MeasuredAt: day(5), Weight: 80})
+		mustCreate(t, ctx1, &fwt.BodyMeasurement{MeasuredAt: day(5), Weight: 60})
+
+		ms, n, err := s.BodyMeasurementService.FindBodyMeasurements(ctx, fwt.BodyMeasurementFilter{UserID: &user.ID})
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+		require.Equal(t, []float64{81, 80, 79}, []float64{ms[0].Weight, ms[1].Weight, ms[2].Weight})
+
+		from, to := day(2), day(10)
+		ms, n, err = s.BodyMeasurementService.FindBodyMeasurements(ctx, fwt.BodyMeasurementFilter{UserID: &user.ID, From: &from, To: &to})
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, float64(80), ms[0].Weight)
+		require.Equal(t, float64(79), ms[1].Weight)
+
+		ms, n, err = s.BodyMeasurementService.FindBodyMeasurements(ctx, fwt.BodyMeasurementFilter{UserID: &user.ID, Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+		require.Len(t, ms, 1)
+		require.Equal(t, float64(80), ms[0].Weight)
+	})
+
+	t.Run("ProfileWeight", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		profile := &fwt.Profile{FirstName: randomString(8), Height: 180}
+		require.NoError(t, s.ProfileService.CreateProfile(ctx, profile))
+
+		older := mustCreate(t, ctx, &fwt.BodyMeasurement{MeasuredAt: day(1), Weight: 82})
+		latest := mustCreate(t, ctx, &fwt.BodyMeasurement{MeasuredAt: day(3), Weight: 80})
+		mustCreate(t, ctx, &fwt.BodyMeasurement{MeasuredAt: day(4), Waist: 86})
+
+		requireWeight := func(tb testing.TB, want float64) {
+			tb.Helper()
+			other, err := s.ProfileService.FindProfileByUserID(ctx, user.ID)
+			require.NoError(tb, err)
+			require.Equal(tb, want, other.Weight)
+		}
+		requireWeight(t, 80)
+
+		weight := 79.5
+		_, err := s.BodyMeasurementService.UpdateBodyMeasurement(ctx, latest.ID, fwt.BodyMeasurementUpdate{Weight: &weight})
+		require.NoError(t, err)
+		requireWeight(t, 79.5)
+
+		require.NoError(t, s.BodyMeasurementService.DeleteBodyMeasurement(ctx, latest.ID))
+		requireWeight(t, 82)
+
+		require.NoError(t, s.BodyMeasurementService.DeleteBodyMeasurement(ctx, older.ID))
+		requireWeight(t, 0)
+	})
+
+	t.Run("UpdateProfileRecordsWeight", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		profile := &fwt.Profile{FirstName: randomString(8), Weight: 70}
+		require.NoError(t, s.ProfileService.CreateProfile(ctx, profile))
+
+		weight := 72.0
+		updated, err := s.ProfileService.UpdateProfile(ctx, profile.ID, fwt.ProfileUpdate{Weight: &weight})
+		require.NoError(t, err)
+		require.Equal(t, weight, updated.Weight)
+
+		ms, n, err := s.BodyMeasurementService.FindBodyMeasurements(ctx, fwt.BodyMeasurementFilter{UserID: &user.ID})
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, float64(70), ms[0].Weight)
+		require.Equal(t, float64(72), ms[1].Weight)
+	})
+
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		_, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		m := mustCreate(t, ctx0, &fwt.BodyMeasurement{MeasuredAt: day(1), Weight: 70})
+
+		weight := 71.0
+		_, err := s.BodyMeasurementService.UpdateBodyMeasurement(ctx1, m.ID, fwt.BodyMeasurementUpdate{Weight: &weight})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		err = s.BodyMeasurementService.DeleteBodyMeasurement(ctx1, m.ID)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		_, err := s.BodyMeasurementService.FindBodyMeasurementByID(ctx, 1<<20)
+		requireCode(t, err, fwt.ENOTFOUND)
+
+		err = s.BodyMeasurementService.DeleteBodyMeasurement(ctx, 1<<20)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
@@ -0,0 +1,65 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testCalendarFeedService(t *testing.T, s *Services) {
+	t.Run("RotateCalendarFeed", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		feed, err := s.CalendarFeedService.RotateCalendarFeed(ctx)
+		require.NoError(t, err)
+		require.NotZero(t, feed.ID)
+		require.NotEmpty(t, feed.Token)
+		require.Equal(t, user.ID, feed.UserID)
+
+		found, err := s.CalendarFeedService.FindCalendarFeedByToken(context.Background(), feed.Token)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, found.UserID)
+		require.Empty(t, found.Token)
+
+		rotated, err := s.CalendarFeedService.RotateCalendarFeed(ctx)
+		require.NoError(t, err)
+		require.NotEqual(t, feed.Token, rotated.Token)
+
+		_, err = s.CalendarFeedService.FindCalendarFeedByToken(context.Background(), feed.Token)
+		requireCode(t, err, fwt.ENOTFOUND)
+
+		found, err = s.CalendarFeedService.FindCalendarFeedByToken(context.Background(), rotated.Token)
+		require.NoError(t, err)
+		require.Equal(t, rotated.ID, found.ID)
+	})
+
+	t.Run("SeparateUsers", func(t *testing.T) {
+		_, ctx0 := MustCreateUser(t, s)
+		user1, ctx1 := MustCreateUser(t, s)
+
+		_, err := s.CalendarFeedService.RotateCalendarFeed(ctx0)
+		require.NoError(t, err)
+		feed, err := s.CalendarFeedService.RotateCalendarFeed(ctx1)
+		require.NoError(t, err)
+
+		// Rotating one user's feed leaves the other's alone.
+		_, err = s.CalendarFeedService.RotateCalendarFeed(ctx0)
+		require.NoError(t, err)
+
+		found, err := s.CalendarFeedService.FindCalendarFeedByToken(context.Background(), feed.Token)
+		require.NoError(t, err)
+		require.Equal(t, user1.ID, found.UserID)
+	})
+
+	t.Run("ErrUnauthenticated", func(t *testing.T) {
+		_, err := s.CalendarFeedService.RotateCalendarFeed(context.Background())
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		_, err := s.CalendarFeedService.FindCalendarFeedByToken(context.Background(), "unknown")
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
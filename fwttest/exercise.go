@@ -0,0 +1,57 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testExerciseService(t *testing.T, s *Services) {
+	t.Run("CreateExercise", func(t *testing.T) {
+		exercise := MustCreateExercise(t, s)
+		require.NotZero(t, exercise.ID)
+
+		other, err := s.ExerciseService.FindExerciseByName(context.Background(), exercise.Name)
+		require.NoError(t, err)
+		require.Equal(t, exercise.ID, other.ID)
+		require.Equal(t, exercise.Description, other.Description)
+	})
+
+	t.Run("ErrNameRequired", func(t *testing.T) {
+		err := s.ExerciseService.CreateExercise(context.Background(), &fwt.Exercise{Description: "x"})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		_, err := s.ExerciseService.FindExerciseByName(context.Background(), randomString(12))
+		requireCode(t, err, fwt.ENOTFOUND)
+
+		_, err = s.ExerciseService.FindExerciseByID(context.Background(), 1<<30)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("FindExercises", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			MustCreateExercise(t, s)
+		}
+
+		all, total, err := s.ExerciseService.FindExercises(context.Background(), fwt.ExerciseFilter{})
+		require.NoError(t, err)
+		require.Len(t, all, total)
+		require.GreaterOrEqual(t, total, 3)
+
+		page, n, err := s.ExerciseService.FindExercises(context.Background(), fwt.ExerciseFilter{Limit: 1, Offset: 2})
+		require.NoError(t, err)
+		require.Equal(t, total, n)
+		require.Len(t, page, 1)
+		require.Equal(t, all[2].ID, page[0].ID)
+
+		page, _, err = s.ExerciseService.FindExercises(context.Background(), fwt.ExerciseFilter{Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		require.Equal(t, all[1].ID, page[0].ID)
+		require.Equal(t, all[2].ID, page[1].ID)
+	})
+}
@@ -0,0 +1,114 @@
+// Package fwttest provides a conformance suite that every storage backend
+// must pass, so that postgres, sqlite and any other implementation of the
+// fwt service interfaces behave the same way.
+package fwttest
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+// Services holds the service implementations of a single backend.
+type Services struct {
+	UserService            fwt.UserService
+	ProfileService         fwt.ProfileService
+	WorkoutService         fwt.WorkoutService
+	ExerciseService        fwt.ExerciseService
+	WorkoutExerciseService fwt.WorkoutExerciseService
+	WEStatusService        fwt.WEStatusService
+	BodyMeasurementService fwt.BodyMeasurementService
+	GoalService            fwt.GoalService
+	CalendarFeedService    fwt.CalendarFeedService
+	IdempotencyService     fwt.IdempotencyService
+
+	// TxRunner runs units of work against the same backend.
+	TxRunner fwt.TxRunner
+
+	// SetNow replaces the clock of the backend.
+	SetNow func(now func() time.Time)
+
+	// CountRows returns the number of rows in a table. It is optional and
+	// checks that deleting a user leaves no rows behind.
+	CountRows func(table string) (int, error)
+}
+
+// OpenFunc returns the services of a freshly migrated backend. It is called
+// once per service under test and should register any cleanup with tb.
+type OpenFunc func(tb testing.TB) *Services
+
+// TestServices runs the conformance suite against the backend returned by
+// open.
+func TestServices(t *testing.T, open OpenFunc) {
+	t.Run("UserService", func(t *testing.T) { testUserService(t, open(t)) })
+	t.Run("ProfileService", func(t *testing.T) { testProfileService(t, open(t)) })
+	t.Run("ExerciseService", func(t *testing.T) { testExerciseService(t, open(t)) })
+	t.Run("WorkoutService", func(t *testing.T) { testWorkoutService(t, open(t)) })
+	t.Run("WorkoutLifecycle", func(t *testing.T) { testWorkoutLifecycle(t, open(t)) })
+	t.Run("LoggedWorkouts", func(t *testing.T) { testLoggedWorkouts(t, open(t)) })
+	t.Run("BodyMeasurementService", func(t *testing.T) { testBodyMeasurementService(t, open(t)) })
+	t.Run("GoalService", func(t *testing.T) { testGoalService(t, open(t)) })
+	t.Run("CalendarFeedService", func(t *testing.T) { testCalendarFeedService(t, open(t)) })
+	t.Run("Timezones", func(t *testing.T) { testTimezones(t, open(t)) })
+	t.Run("WEStatusService", func(t *testing.T) { testWEStatusService(t, open(t)) })
+	t.Run("IdempotencyService", func(t *testing.T) { testIdempotencyService(t, open(t)) })
+	t.Run("TxRunner", func(t *testing.T) { testTxRunner(t, open(t)) })
+}
+
+// MustCreateUser creates a user with a random username and email and
+// returns it with a context authenticated as that user.
+func MustCreateUser(tb testing.TB, s *Services) (*fwt.User, context.Context) {
+	tb.Helper()
+
+	user := &fwt.User{
+		Username: randomString(10),
+		Email:    randomString(10) + "@email.com",
+	}
+	require.NoError(tb, user.SetPassword("password123"))
+	require.NoError(tb, s.UserService.CreateUser(context.Background(), user))
+
+	return user, fwt.NewContextWithUser(context.Background(), user)
+}
+
+// MustCreateExercise creates an exercise with a random name.
+func MustCreateExercise(tb testing.TB, s *Services) *fwt.Exercise {
+	tb.Helper()
+
+	exercise := &fwt.Exercise{Name: randomString(12), Description: randomString(40)}
+	require.NoError(tb, s.ExerciseService.CreateExercise(context.Background(), exercise))
+	return exercise
+}
+
+// MustCreateWorkout creates a workout scheduled for tomorrow containing the
+// given exercises.
+func MustCreateWorkout(tb testing.TB, ctx context.Context, s *Services, exercises ...*fwt.Exercise) *fwt.Workout {
+	tb.Helper()
+
+	workout := &fwt.Workout{
+		Name:          randomString(12),
+		ScheduledDate: time.Now().Add(48 * time.Hour),
+		Exercises:     exercises,
+	}
+	require.NoError(tb, s.WorkoutService.CreateWorkout(ctx, workout))
+	return workout
+}
+
+func requireCode(tb testing.TB, err error, code string) {
+	tb.Helper()
+	require.Error(tb, err)
+	require.Equal(tb, code, fwt.ErrorCode(err), "error: %v", err)
+}
+
+const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[rand.Intn(len(alphabet))]
+	}
+	return string(b)
+}
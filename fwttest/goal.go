@@ -0,0 +1,212 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testGoalService(t *testing.T, s *Services) {
+	// logSets records a finished workout of ex with sets.
+	logSets := func(tb testing.TB, ctx context.Context, ex *fwt.Exercise, finishedAt time.Time, sets ...*fwt.Set) {
+		tb.Helper()
+		workout := &fwt.Workout{
+			Name:          randomString(8),
+			ScheduledDate: finishedAt.UTC().Truncate(24 * time.Hour),
+			StartedAt:     finishedAt.Add(-time.Hour),
+			FinishedAt:    finishedAt,
+			Exercises:     []*fwt.Exercise{{Name: ex.Name, Sets: sets}},
+		}
+		require.NoError(tb, s.WorkoutService.LogWorkout(ctx, workout))
+	}
+
+	mustProgress := func(tb testing.TB, ctx context.Context, id uint) *fwt.GoalProgress {
+		tb.Helper()
+		p, err := s.GoalService.FindGoalProgress(ctx, id)
+		require.NoError(tb, err)
+		require.Equal(tb, id, p.GoalID)
+		return p
+	}
+
+	t.Run("BodyWeight", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		now := time.Now()
+
+		require.NoError(t, s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{MeasuredAt: now.Add(-48 * time.Hour), Weight: 90}))
+
+		goal := &fwt.Goal{Type: fwt.GoalTypeBodyWeight, Target: 80, TargetDate: now.AddDate(0, 0, 10)}
+		require.NoError(t, s.GoalService.CreateGoal(ctx, goal))
+		require.NotZero(t, goal.ID)
+		require.Equal(t, user.ID, goal.UserID)
+		require.Equal(t, float64(90), goal.Start)
+
+		p := mustProgress(t, ctx, goal.ID)
+		require.Equal(t, float64(90), p.Current)
+		require.Zero(t, p.Percent)
+		require.Nil(t, p.ProjectedAt)
+
+		measuredAt := goal.CreatedAt.Add(24 * time.Hour)
+		require.NoError(t, s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{MeasuredAt: measuredAt, Weight: 85}))
+
+		p = mustProgress(t, ctx, goal.ID)
+		require.Equal(t, float64(85), p.Current)
+		require.Equal(t, float64(50), p.Percent)
+		require.False(t, p.Achieved)
+		require.NotNil(t, p.ProjectedAt)
+		require.True(t, p.ProjectedAt.After(measuredAt))
+		require.True(t, p.OnTrack)
+	})
+
+	t.Run("BodyWeight/NoMeasurement", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+
+		// Without a measured weight there is nowhere to start from.
+		err := s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: fwt.GoalTypeBodyWeight, Target: 75})
+		requireCode(t, err, fwt.EINVALID)
+
+		require.NoError(t, s.ProfileService.CreateProfile(ctx, &fwt.Profile{
+			FirstName:   randomString(8),
+			LastName:    randomString(8),
+			DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+			Gender:      "Female",
+			Height:      170,
+			Weight:      90,
+		}))
+
+		goal := &fwt.Goal{Type: fwt.GoalTypeBodyWeight, Target: 75}
+		require.NoError(t, s.GoalService.CreateGoal(ctx, goal))
+		require.Equal(t, float64(90), goal.Start)
+
+		require.NoError(t, s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{MeasuredAt: goal.CreatedAt.Add(time.Hour), Weight: 87}))
+
+		p := mustProgress(t, ctx, goal.ID)
+		require.Equal(t, float64(87), p.Current)
+		require.Equal(t, float64(20), p.Percent)
+		require.False(t, p.Achieved)
+	})
+
+	t.Run("Lift", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		ex := MustCreateExercise(t, s)
+
+		logSets(t, ctx, ex, time.Now().Add(-24*time.Hour), &fwt.Set{Reps: 5, Load: 100})
+
+		goal := &fwt.Goal{Type: fwt.GoalTypeLift, ExerciseID: ex.ID, Target: 140}
+		require.NoError(t, s.GoalService.CreateGoal(ctx, goal))
+		require.InDelta(t, fwt.EstimateOneRepMax(5, 100), goal.Start, 1e-9)
+
+		p := mustProgress(t, ctx, goal.ID)
+		require.InDelta(t, goal.Start, p.Current, 1e-9)
+		require.Zero(t, p.Percent)
+
+		logSets(t, ctx, ex, time.Now(), &fwt.Set{Reps: 3, Load: 130})
+
+		p = mustProgress(t, ctx, goal.ID)
+		require.InDelta(t, fwt.EstimateOneRepMax(3, 130), p.Current, 1e-9)
+		require.Equal(t, float64(100), p.Percent)
+		require.True(t, p.Achieved)
+		require.True(t, p.OnTrack)
+		require.Nil(t, p.ProjectedAt)
+	})
+
+	t.Run("Frequency", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		ex := MustCreateExercise(t, s)
+
+		goal := &fwt.Goal{Type: fwt.GoalTypeFrequency, Target: 4}
+		require.NoError(t, s.GoalService.CreateGoal(ctx, goal))
+		require.Zero(t, goal.Start)
+
+		logSets(t, ctx, ex, time.Now(), &fwt.Set{Reps: 5, Load: 100})
+		logSets(t, ctx, ex, time.Now())
+
+		p := mustProgress(t, ctx, goal.ID)
+		require.Equal(t, float64(2), p.Current)
+		require.Equal(t, float64(50), p.Percent)
+		require.False(t, p.Achieved)
+	})
+
+	t.Run("Volume", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		ex := MustCreateExercise(t, s)
+
+		goal := &fwt.Goal{Type: fwt.GoalTypeVolume, Target: 2000}
+		require.NoError(t, s.GoalService.CreateGoal(ctx, goal))
+
+		logSets(t, ctx, ex, time.Now(), &fwt.Set{Reps: 5, Load: 100}, &fwt.Set{Reps: 5, Load: 50})
+
+		p := mustProgress(t, ctx, goal.ID)
+		require.Equal(t, float64(750), p.Current)
+		require.Equal(t, 37.5, p.Percent)
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+
+		err := s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: "marathon", Target: 42})
+		requireCode(t, err, fwt.EINVALID)
+
+		err = s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: fwt.GoalTypeLift, Target: 140})
+		requireCode(t, err, fwt.EINVALID)
+
+		err = s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: fwt.GoalTypeFrequency})
+		requireCode(t, err, fwt.EINVALID)
+
+		err = s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: fwt.GoalTypeLift, ExerciseID: 1 << 20, Target: 140})
+		requireCode(t, err, fwt.ENOTFOUND)
+
+		// A lift target must be above the best lift so far, or progress
+		// towards it could never be measured.
+		ex := MustCreateExercise(t, s)
+		logSets(t, ctx, ex, time.Now().Add(-time.Hour), &fwt.Set{Reps: 1, Load: 100})
+		err = s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: fwt.GoalTypeLift, ExerciseID: ex.ID, Target: 90})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrUnauthenticated", func(t *testing.T) {
+		err := s.GoalService.CreateGoal(context.Background(), &fwt.Goal{Type: fwt.GoalTypeFrequency, Target: 3})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("ArchiveGoal", func(t *testing.T) {
+		user, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+
+		goal := &fwt.Goal{Type: fwt.GoalTypeFrequency, Target: 3}
+		require.NoError(t, s.GoalService.CreateGoal(ctx0, goal))
+		require.NoError(t, s.GoalService.CreateGoal(ctx0, &fwt.Goal{Type: fwt.GoalTypeVolume, Target: 5000}))
+
+		_, err := s.GoalService.ArchiveGoal(ctx1, goal.ID)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		archived, err := s.GoalService.ArchiveGoal(ctx0, goal.ID)
+		require.NoError(t, err)
+		require.False(t, archived.ArchivedAt.IsZero())
+
+		_, err = s.GoalService.ArchiveGoal(ctx0, goal.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+
+		yes, no := true, false
+		a, n, err := s.GoalService.FindGoals(ctx0, fwt.GoalFilter{UserID: &user.ID, Archived: &yes})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, goal.ID, a[0].ID)
+
+		a, n, err = s.GoalService.FindGoals(ctx0, fwt.GoalFilter{UserID: &user.ID, Archived: &no})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, fwt.GoalTypeVolume, a[0].Type)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		_, err := s.GoalService.FindGoalByID(ctx, 1<<20)
+		requireCode(t, err, fwt.ENOTFOUND)
+
+		_, err = s.GoalService.FindGoalProgress(ctx, 1<<20)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
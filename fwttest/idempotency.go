@@ -0,0 +1,96 @@
+package fwttest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testIdempotencyService(t *testing.T, s *Services) {
+	newKey := func(expiresIn time.Duration) *fwt.IdempotencyKey {
+		return &fwt.IdempotencyKey{
+			Key:         randomString(16),
+			RequestHash: randomString(64),
+			ExpiresAt:   time.Now().Add(expiresIn),
+		}
+	}
+
+	t.Run("CreateFindUpdate", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		key := newKey(time.Hour)
+		require.NoError(t, s.IdempotencyService.CreateIdempotencyKey(ctx, key))
+		require.NotZero(t, key.ID)
+		require.Equal(t, user.ID, key.UserID)
+
+		found, err := s.IdempotencyService.FindIdempotencyKey(ctx, user.ID, key.Key)
+		require.NoError(t, err)
+		require.Equal(t, key.RequestHash, found.RequestHash)
+		require.False(t, found.Completed())
+
+		status, body := http.StatusCreated, []byte(`{"ok":true}`)
+		headers := map[string][]string{"Etag": {`"1"`}, "Location": {"/api/v1/goals/1"}}
+		expiresAt := key.ExpiresAt.Add(24 * time.Hour)
+		_, err = s.IdempotencyService.UpdateIdempotencyKey(ctx, key.ID, fwt.IdempotencyKeyUpdate{
+			ResponseStatus:  &status,
+			ResponseBody:    body,
+			ResponseHeaders: headers,
+			ExpiresAt:       &expiresAt,
+		})
+		require.NoError(t, err)
+
+		found, err = s.IdempotencyService.FindIdempotencyKey(ctx, user.ID, key.Key)
+		require.NoError(t, err)
+		require.True(t, found.Completed())
+		require.Equal(t, status, found.ResponseStatus)
+		require.Equal(t, body, found.ResponseBody)
+		require.Equal(t, headers, found.ResponseHeaders)
+		require.WithinDuration(t, expiresAt, found.ExpiresAt, time.Second)
+	})
+
+	t.Run("ErrConflict", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		key := newKey(time.Hour)
+		require.NoError(t, s.IdempotencyService.CreateIdempotencyKey(ctx, key))
+
+		dup := newKey(time.Hour)
+		dup.Key = key.Key
+		requireCode(t, s.IdempotencyService.CreateIdempotencyKey(ctx, dup), fwt.ECONFLICT)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		key := newKey(-time.Hour)
+		require.NoError(t, s.IdempotencyService.CreateIdempotencyKey(ctx, key))
+
+		_, err := s.IdempotencyService.FindIdempotencyKey(ctx, user.ID, key.Key)
+		requireCode(t, err, fwt.ENOTFOUND)
+
+		reused := newKey(time.Hour)
+		reused.Key = key.Key
+		require.NoError(t, s.IdempotencyService.CreateIdempotencyKey(ctx, reused))
+	})
+
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		_, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		key := newKey(time.Hour)
+		require.NoError(t, s.IdempotencyService.CreateIdempotencyKey(ctx0, key))
+
+		requireCode(t, s.IdempotencyService.DeleteIdempotencyKey(ctx1, key.ID), fwt.ENOTAUTHORIZED)
+		requireCode(t, s.IdempotencyService.CreateIdempotencyKey(context.Background(), newKey(time.Hour)), fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		key := newKey(time.Hour)
+		require.NoError(t, s.IdempotencyService.CreateIdempotencyKey(ctx, key))
+		require.NoError(t, s.IdempotencyService.DeleteIdempotencyKey(ctx, key.ID))
+
+		_, err := s.IdempotencyService.FindIdempotencyKey(ctx, user.ID, key.Key)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
@@ -0,0 +1,128 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testProfileService(t *testing.T, s *Services) {
+	newProfile := func() *fwt.Profile {
+		return &fwt.Profile{
+			FirstName:   randomString(8),
+			LastName:    randomString(8),
+			DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+			Gender:      "Female",
+			Height:      170,
+			Weight:      65,
+		}
+	}
+
+	t.Run("CreateProfile", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		profile := newProfile()
+		require.NoError(t, s.ProfileService.CreateProfile(ctx, profile))
+		require.NotZero(t, profile.ID)
+		require.Equal(t, user.ID, profile.UserID)
+		require.Equal(t, uint(1), profile.Version)
+
+		other, err := s.ProfileService.FindProfileByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, profile.ID, other.ID)
+		require.Equal(t, profile.FirstName, other.FirstName)
+		require.Equal(t, profile.Height, other.Height)
+		require.True(t, profile.DateOfBirth.Equal(other.DateOfBirth))
+	})
+
+	t.Run("ErrUnauthenticated", func(t *testing.T) {
+		err := s.ProfileService.CreateProfile(context.Background(), newProfile())
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("ErrAlreadyExists", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		require.NoError(t, s.ProfileService.CreateProfile(ctx, newProfile()))
+
+		err := s.ProfileService.CreateProfile(ctx, newProfile())
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		_, err := s.ProfileService.FindProfileByUserID(ctx, user.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("UpdateProfile", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		profile := newProfile()
+		require.NoError(t, s.ProfileService.CreateProfile(ctx, profile))
+
+		firstName, version := randomString(8), profile.Version
+		updated, err := s.ProfileService.UpdateProfile(ctx, profile.ID, fwt.ProfileUpdate{FirstName: &firstName, Version: &version})
+		require.NoError(t, err)
+		require.Equal(t, firstName, updated.FirstName)
+		require.Equal(t, uint(2), updated.Version)
+
+		other, err := s.ProfileService.FindProfileByID(ctx, profile.ID)
+		require.NoError(t, err)
+		require.Equal(t, firstName, other.FirstName)
+		require.Equal(t, uint(2), other.Version)
+
+		_, err = s.ProfileService.UpdateProfile(ctx, profile.ID, fwt.ProfileUpdate{FirstName: &firstName, Version: &version})
+		requireCode(t, err, fwt.ESTALE)
+	})
+
+	t.Run("Units", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		profile := newProfile()
+		profile.Units = fwt.Units{Weight: fwt.Pound}
+		require.NoError(t, s.ProfileService.CreateProfile(ctx, profile))
+		require.Equal(t, fwt.Units{Weight: fwt.Pound, Length: fwt.Centimetre, Distance: fwt.Kilometre}, profile.Units)
+
+		updated, err := s.ProfileService.UpdateProfile(ctx, profile.ID, fwt.ProfileUpdate{Units: &fwt.Units{Length: fwt.Inch}})
+		require.NoError(t, err)
+		require.Equal(t, fwt.Units{Weight: fwt.Pound, Length: fwt.Inch, Distance: fwt.Kilometre}, updated.Units)
+
+		other, err := s.ProfileService.FindProfileByID(ctx, profile.ID)
+		require.NoError(t, err)
+		require.Equal(t, updated.Units, other.Units)
+
+		_, err = s.ProfileService.UpdateProfile(ctx, profile.ID, fwt.ProfileUpdate{Units: &fwt.Units{Distance: "furlong"}})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrUpdateUnauthorized", func(t *testing.T) {
+		_, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		profile := newProfile()
+		require.NoError(t, s.ProfileService.CreateProfile(ctx0, profile))
+
+		firstName := randomString(8)
+		_, err := s.ProfileService.UpdateProfile(ctx1, profile.ID, fwt.ProfileUpdate{FirstName: &firstName})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("DeleteProfile", func(t *testing.T) {
+		_, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		profile := newProfile()
+		require.NoError(t, s.ProfileService.CreateProfile(ctx0, profile))
+
+		err := s.ProfileService.DeleteProfile(ctx1, profile.ID, nil)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		stale := profile.Version + 1
+		err = s.ProfileService.DeleteProfile(ctx0, profile.ID, &stale)
+		requireCode(t, err, fwt.ESTALE)
+
+		require.NoError(t, s.ProfileService.DeleteProfile(ctx0, profile.ID, &profile.Version))
+
+		_, err = s.ProfileService.FindProfileByID(ctx0, profile.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
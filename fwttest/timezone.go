@@ -0,0 +1,151 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testTimezones(t *testing.T, s *Services) {
+	// mustCreateUserIn creates a user whose profile is in timezone.
+	mustCreateUserIn := func(tb testing.TB, timezone string) (*fwt.User, context.Context) {
+		tb.Helper()
+		user, ctx := MustCreateUser(tb, s)
+		require.NoError(tb, s.ProfileService.CreateProfile(ctx, &fwt.Profile{
+			FirstName:   randomString(8),
+			LastName:    randomString(8),
+			DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+			Gender:      "Female",
+			Height:      170,
+			Weight:      65,
+			Timezone:    timezone,
+		}))
+		return user, ctx
+	}
+
+	// setNow fixes the clock of the backend for the rest of the test.
+	setNow := func(tb testing.TB, now time.Time) {
+		tb.Helper()
+		if s.SetNow == nil {
+			tb.Skip("backend clock cannot be replaced")
+		}
+		s.SetNow(func() time.Time { return now })
+		tb.Cleanup(func() { s.SetNow(time.Now) })
+	}
+
+	date := func(s string) time.Time {
+		t, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	t.Run("Profile/Default", func(t *testing.T) {
+		user, ctx := mustCreateUserIn(t, "")
+
+		profile, err := s.ProfileService.FindProfileByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.DefaultTimezone, profile.Timezone)
+	})
+
+	t.Run("Profile/Update", func(t *testing.T) {
+		user, ctx := mustCreateUserIn(t, "")
+		profile, err := s.ProfileService.FindProfileByUserID(ctx, user.ID)
+		require.NoError(t, err)
+
+		tz := "America/Los_Angeles"
+		profile, err = s.ProfileService.UpdateProfile(ctx, profile.ID, fwt.ProfileUpdate{Timezone: &tz})
+		require.NoError(t, err)
+		require.Equal(t, tz, profile.Timezone)
+
+		tz = "Mars/Olympus_Mons"
+		_, err = s.ProfileService.UpdateProfile(ctx, profile.ID, fwt.ProfileUpdate{Timezone: &tz})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("Profile/ErrInvalid", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		err := s.ProfileService.CreateProfile(ctx, &fwt.Profile{
+			FirstName:   randomString(8),
+			LastName:    randomString(8),
+			DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+			Gender:      "Female",
+			Height:      170,
+			Weight:      65,
+			Timezone:    "Mars/Olympus_Mons",
+		})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ScheduledDate", func(t *testing.T) {
+		// It is still the evening of March 5th in Los Angeles, but already
+		// the morning of March 6th in Tokyo.
+		setNow(t, time.Date(2024, 3, 6, 4, 0, 0, 0, time.UTC))
+
+		_, laCtx := mustCreateUserIn(t, "America/Los_Angeles")
+		_, utcCtx := mustCreateUserIn(t, "UTC")
+		_, tokyoCtx := mustCreateUserIn(t, "Asia/Tokyo")
+
+		newWorkout := func(date time.Time) *fwt.Workout {
+			return &fwt.Workout{
+				Name:          randomString(10),
+				ScheduledDate: date,
+				Exercises:     []*fwt.Exercise{MustCreateExercise(t, s)},
+			}
+		}
+
+		require.NoError(t, s.WorkoutService.CreateWorkout(laCtx, newWorkout(date("2024-03-05"))))
+		requireCode(t, s.WorkoutService.CreateWorkout(utcCtx, newWorkout(date("2024-03-05"))), fwt.EINVALID)
+		require.NoError(t, s.WorkoutService.CreateWorkout(utcCtx, newWorkout(date("2024-03-06"))))
+		requireCode(t, s.WorkoutService.CreateWorkout(tokyoCtx, newWorkout(date("2024-03-05"))), fwt.EINVALID)
+
+		// A logged workout may be dated today, but not tomorrow.
+		logged := newWorkout(date("2024-03-06"))
+		requireCode(t, s.WorkoutService.LogWorkout(laCtx, logged), fwt.EINVALID)
+		require.NoError(t, s.WorkoutService.LogWorkout(utcCtx, newWorkout(date("2024-03-06"))))
+
+		workout := &fwt.Workout{}
+		require.NoError(t, s.WorkoutService.QuickStartWorkout(laCtx, workout))
+		require.True(t, date("2024-03-05").Equal(workout.ScheduledDate), workout.ScheduledDate)
+
+		workout = &fwt.Workout{}
+		require.NoError(t, s.WorkoutService.QuickStartWorkout(tokyoCtx, workout))
+		require.True(t, date("2024-03-06").Equal(workout.ScheduledDate), workout.ScheduledDate)
+	})
+
+	t.Run("GoalWeek", func(t *testing.T) {
+		// A new week has begun in both timezones, but a workout finished at
+		// 5am UTC on Monday was finished on Sunday night in Los Angeles.
+		now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+		setNow(t, now)
+
+		for _, tt := range []struct {
+			timezone string
+			current  float64
+		}{
+			{"UTC", 1},
+			{"America/Los_Angeles", 0},
+		} {
+			_, ctx := mustCreateUserIn(t, tt.timezone)
+
+			goal := &fwt.Goal{Type: fwt.GoalTypeFrequency, Target: 3}
+			require.NoError(t, s.GoalService.CreateGoal(ctx, goal))
+
+			require.NoError(t, s.WorkoutService.LogWorkout(ctx, &fwt.Workout{
+				Name:          randomString(10),
+				ScheduledDate: date("2024-03-03"),
+				StartedAt:     now.Add(-6 * time.Hour),
+				FinishedAt:    now.Add(-5 * time.Hour),
+				Exercises:     []*fwt.Exercise{MustCreateExercise(t, s)},
+			}))
+
+			p, err := s.GoalService.FindGoalProgress(ctx, goal.ID)
+			require.NoError(t, err)
+			require.Equal(t, tt.current, p.Current, tt.timezone)
+		}
+	})
+}
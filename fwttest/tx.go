@@ -0,0 +1,87 @@
+package fwttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testTxRunner(t *testing.T, s *Services) {
+	if s.TxRunner == nil {
+		t.Skip("backend does not provide a TxRunner")
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		var user *fwt.User
+		err := s.TxRunner.RunInTx(context.Background(), func(ctx context.Context) error {
+			user = &fwt.User{Username: randomString(10), Email: randomString(10) + "@email.com"}
+			if err := s.UserService.CreateUser(ctx, user); err != nil {
+				return err
+			}
+
+			// Reads within the unit of work see its own writes.
+			_, err := s.UserService.FindUserByID(ctx, user.ID)
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = s.UserService.FindUserByID(context.Background(), user.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		errAbort := errors.New("abort")
+
+		var user *fwt.User
+		err := s.TxRunner.RunInTx(context.Background(), func(ctx context.Context) error {
+			user = &fwt.User{Username: randomString(10), Email: randomString(10) + "@email.com"}
+			if err := s.UserService.CreateUser(ctx, user); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+
+		_, err = s.UserService.FindUserByID(context.Background(), user.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("RollbackAcrossServices", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		err := s.TxRunner.RunInTx(ctx, func(ctx context.Context) error {
+			if err := s.ProfileService.CreateProfile(ctx, &fwt.Profile{FirstName: "Jane"}); err != nil {
+				return err
+			}
+			// Fails: the user now has a profile.
+			return s.ProfileService.CreateProfile(ctx, &fwt.Profile{FirstName: "Jane"})
+		})
+		requireCode(t, err, fwt.ECONFLICT)
+
+		_, err = s.ProfileService.FindProfileByUserID(ctx, user.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		errAbort := errors.New("abort")
+
+		var user *fwt.User
+		err := s.TxRunner.RunInTx(context.Background(), func(ctx context.Context) error {
+			if err := s.TxRunner.RunInTx(ctx, func(ctx context.Context) error {
+				user = &fwt.User{Username: randomString(10), Email: randomString(10) + "@email.com"}
+				return s.UserService.CreateUser(ctx, user)
+			}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+
+		// The inner unit of work joined the outer one and was rolled back.
+		_, err = s.UserService.FindUserByID(context.Background(), user.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
@@ -0,0 +1,336 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testUserService(t *testing.T, s *Services) {
+	t.Run("CreateUser", func(t *testing.T) {
+		user, _ := MustCreateUser(t, s)
+		require.NotZero(t, user.ID)
+		require.False(t, user.CreatedAt.IsZero())
+		require.Equal(t, user.CreatedAt, user.UpdatedAt)
+
+		other, err := s.UserService.FindUserByID(context.Background(), user.ID)
+		require.NoError(t, err)
+		require.Equal(t, user.Username, other.Username)
+		require.Equal(t, user.Email, other.Email)
+		require.True(t, user.CreatedAt.Equal(other.CreatedAt))
+	})
+
+	t.Run("ErrUsernameRequired", func(t *testing.T) {
+		err := s.UserService.CreateUser(context.Background(), &fwt.User{Email: "a@email.com"})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrDuplicateUsername", func(t *testing.T) {
+		user, _ := MustCreateUser(t, s)
+		err := s.UserService.CreateUser(context.Background(), &fwt.User{Username: user.Username, Email: randomString(8) + "@email.com"})
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("ErrDuplicateEmail", func(t *testing.T) {
+		user, _ := MustCreateUser(t, s)
+		err := s.UserService.CreateUser(context.Background(), &fwt.User{Username: randomString(10), Email: user.Email})
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		_, err := s.UserService.FindUserByID(context.Background(), 1<<30)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("FindUsers", func(t *testing.T) {
+		user, _ := MustCreateUser(t, s)
+		MustCreateUser(t, s)
+
+		users, n, err := s.UserService.FindUsers(context.Background(), fwt.UserFilter{Username: &user.Username})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Len(t, users, 1)
+		require.Equal(t, user.ID, users[0].ID)
+
+		all, total, err := s.UserService.FindUsers(context.Background(), fwt.UserFilter{})
+		require.NoError(t, err)
+		require.Len(t, all, total)
+
+		page, n, err := s.UserService.FindUsers(context.Background(), fwt.UserFilter{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Equal(t, total, n)
+		require.Len(t, page, 1)
+		require.Equal(t, all[1].ID, page[0].ID)
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		user, _ := MustCreateUser(t, s)
+
+		got, err := s.UserService.Authenticate(context.Background(), user.Username, "password123")
+		require.NoError(t, err)
+		require.Equal(t, user.ID, got.ID)
+
+		_, err = s.UserService.Authenticate(context.Background(), user.Username, "wrong")
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		_, err = s.UserService.Authenticate(context.Background(), randomString(10), "password123")
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+
+		username := randomString(10)
+		updated, err := s.UserService.UpdateUser(ctx, user.ID, fwt.UserUpdate{Username: &username})
+		require.NoError(t, err)
+		require.Equal(t, username, updated.Username)
+		require.Equal(t, user.Email, updated.Email)
+
+		other, err := s.UserService.FindUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, username, other.Username)
+	})
+
+	t.Run("ErrUpdateConflict", func(t *testing.T) {
+		user0, _ := MustCreateUser(t, s)
+		user1, ctx1 := MustCreateUser(t, s)
+
+		_, err := s.UserService.UpdateUser(ctx1, user1.ID, fwt.UserUpdate{Email: &user0.Email})
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("ErrUpdateUnauthorized", func(t *testing.T) {
+		user0, _ := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+
+		username := randomString(10)
+		_, err := s.UserService.UpdateUser(ctx1, user0.ID, fwt.UserUpdate{Username: &username})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		_, ctx1 := MustCreateUser(t, s)
+		counts := countRows(t, s)
+		user0, ctx0 := MustCreateUser(t, s)
+		data := mustCreateUserData(t, ctx0, s)
+
+		err := s.UserService.DeleteUser(ctx1, user0.ID)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		require.NoError(t, s.UserService.DeleteUser(ctx0, user0.ID))
+		requireUserDataDeleted(t, ctx0, s, user0, data)
+		require.Equal(t, counts, countRows(t, s))
+	})
+
+	t.Run("ScheduleUserDeletion", func(t *testing.T) {
+		user0, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		mustCreateUserData(t, ctx0, s)
+
+		_, err := s.UserService.ScheduleUserDeletion(ctx1, user0.ID)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		user, err := s.UserService.ScheduleUserDeletion(ctx0, user0.ID)
+		require.NoError(t, err)
+		require.True(t, user.Deleted())
+		require.True(t, user.PurgeAt().Equal(user.DeletedAt.Add(fwt.UserDeletionGracePeriod)))
+
+		_, err = s.UserService.ScheduleUserDeletion(ctx0, user0.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+
+		// Nothing is removed during the grace period.
+		other, err := s.UserService.FindUserByID(context.Background(), user0.ID)
+		require.NoError(t, err)
+		require.True(t, user.DeletedAt.Equal(other.DeletedAt))
+		_, err = s.ProfileService.FindProfileByUserID(ctx0, user0.ID)
+		require.NoError(t, err)
+
+		_, err = s.UserService.RestoreUser(ctx1, user0.ID)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		user, err = s.UserService.RestoreUser(ctx0, user0.ID)
+		require.NoError(t, err)
+		require.False(t, user.Deleted())
+
+		_, err = s.UserService.RestoreUser(ctx0, user0.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("RestoreUser/ErrGracePeriodEnded", func(t *testing.T) {
+		if s.SetNow == nil {
+			t.Skip("backend clock cannot be replaced")
+		}
+
+		user, ctx := MustCreateUser(t, s)
+		user, err := s.UserService.ScheduleUserDeletion(ctx, user.ID)
+		require.NoError(t, err)
+
+		s.SetNow(func() time.Time { return user.PurgeAt().Add(time.Second) })
+		t.Cleanup(func() { s.SetNow(time.Now) })
+
+		_, err = s.UserService.RestoreUser(ctx, user.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+
+		other, err := s.UserService.FindUserByID(context.Background(), user.ID)
+		require.NoError(t, err)
+		require.True(t, other.Deleted())
+
+		require.NoError(t, s.UserService.PurgeDeletedUser(context.Background(), user.ID, user.DeletedAt))
+	})
+
+	t.Run("PurgeDeletedUsers", func(t *testing.T) {
+		kept, keptCtx := MustCreateUser(t, s)
+		mustCreateUserData(t, keptCtx, s)
+
+		counts := countRows(t, s)
+		user, ctx := MustCreateUser(t, s)
+		data := mustCreateUserData(t, ctx, s)
+		user, err := s.UserService.ScheduleUserDeletion(ctx, user.ID)
+		require.NoError(t, err)
+
+		before := user.DeletedAt.Add(-time.Second)
+		due, n, err := s.UserService.FindUsers(context.Background(), fwt.UserFilter{DeletedBefore: &before})
+		require.NoError(t, err)
+		require.Zero(t, n)
+		require.Empty(t, due)
+
+		err = s.UserService.PurgeDeletedUser(context.Background(), user.ID, before)
+		requireCode(t, err, fwt.ECONFLICT)
+		err = s.UserService.PurgeDeletedUser(context.Background(), kept.ID, user.DeletedAt)
+		requireCode(t, err, fwt.ECONFLICT)
+
+		due, n, err = s.UserService.FindUsers(context.Background(), fwt.UserFilter{DeletedBefore: &user.DeletedAt})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, user.ID, due[0].ID)
+
+		require.NoError(t, s.UserService.PurgeDeletedUser(context.Background(), user.ID, user.DeletedAt))
+		requireUserDataDeleted(t, ctx, s, user, data)
+		require.Equal(t, counts, countRows(t, s))
+
+		_, err = s.UserService.FindUserByID(context.Background(), kept.ID)
+		require.NoError(t, err)
+		_, err = s.ProfileService.FindProfileByUserID(keptCtx, kept.ID)
+		require.NoError(t, err)
+	})
+}
+
+// userData is what mustCreateUserData creates for a user.
+type userData struct {
+	workouts       []*fwt.Workout
+	feedToken      string
+	idempotencyKey string
+}
+
+// mustCreateUserData gives the user of ctx a row in every table that holds
+// user data: a profile, a planned and a logged workout with sets, a body
+// measurement, a goal, a calendar feed and an idempotency key.
+func mustCreateUserData(tb testing.TB, ctx context.Context, s *Services) *userData {
+	tb.Helper()
+
+	require.NoError(tb, s.ProfileService.CreateProfile(ctx, &fwt.Profile{
+		FirstName:   randomString(8),
+		LastName:    randomString(8),
+		DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+		Gender:      "Female",
+		Height:      170,
+		Weight:      65,
+	}))
+
+	planned := MustCreateWorkout(tb, ctx, s, MustCreateExercise(tb, s))
+
+	now := time.Now()
+	logged := &fwt.Workout{
+		Name:          randomString(10),
+		ScheduledDate: now,
+		StartedAt:     now.Add(-time.Hour),
+		FinishedAt:    now,
+		Exercises:     []*fwt.Exercise{{Name: MustCreateExercise(tb, s).Name, Sets: []*fwt.Set{{Reps: 5, Load: 100}}}},
+	}
+	require.NoError(tb, s.WorkoutService.LogWorkout(ctx, logged))
+
+	require.NoError(tb, s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{MeasuredAt: now.Add(-time.Hour), Weight: 80}))
+	require.NoError(tb, s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: fwt.GoalTypeFrequency, Target: 3}))
+
+	feed, err := s.CalendarFeedService.RotateCalendarFeed(ctx)
+	require.NoError(tb, err)
+
+	key := &fwt.IdempotencyKey{Key: randomString(16), RequestHash: randomString(64), ExpiresAt: now.Add(time.Hour)}
+	require.NoError(tb, s.IdempotencyService.CreateIdempotencyKey(ctx, key))
+
+	return &userData{
+		workouts:       []*fwt.Workout{planned, logged},
+		feedToken:      feed.Token,
+		idempotencyKey: key.Key,
+	}
+}
+
+// requireUserDataDeleted checks that neither the user nor any of data can
+// be found anymore.
+func requireUserDataDeleted(tb testing.TB, ctx context.Context, s *Services, user *fwt.User, data *userData) {
+	tb.Helper()
+
+	_, err := s.UserService.FindUserByID(context.Background(), user.ID)
+	requireCode(tb, err, fwt.ENOTFOUND)
+	_, err = s.ProfileService.FindProfileByUserID(ctx, user.ID)
+	requireCode(tb, err, fwt.ENOTFOUND)
+
+	_, n, err := s.WorkoutService.FindWorkouts(ctx, fwt.WorkoutFilter{UserID: &user.ID})
+	require.NoError(tb, err)
+	require.Zero(tb, n, "workouts")
+	for _, w := range data.workouts {
+		_, n, err := s.WorkoutExerciseService.FindWorkoutExercises(ctx, fwt.WorkoutExerciseFilter{WorkoutID: &w.ID})
+		require.NoError(tb, err)
+		require.Zero(tb, n, "workout exercises")
+
+		_, n, err = s.WEStatusService.FindWEStatuses(ctx, fwt.WEStatusFilter{WorkoutID: &w.ID})
+		require.NoError(tb, err)
+		require.Zero(tb, n, "workout exercise statuses")
+	}
+
+	_, n, err = s.BodyMeasurementService.FindBodyMeasurements(ctx, fwt.BodyMeasurementFilter{UserID: &user.ID})
+	require.NoError(tb, err)
+	require.Zero(tb, n, "body measurements")
+	_, n, err = s.GoalService.FindGoals(ctx, fwt.GoalFilter{UserID: &user.ID})
+	require.NoError(tb, err)
+	require.Zero(tb, n, "goals")
+
+	_, err = s.CalendarFeedService.FindCalendarFeedByToken(ctx, data.feedToken)
+	requireCode(tb, err, fwt.ENOTFOUND)
+	_, err = s.IdempotencyService.FindIdempotencyKey(ctx, user.ID, data.idempotencyKey)
+	requireCode(tb, err, fwt.ENOTFOUND)
+}
+
+// userTables lists every table holding data owned by a user.
+var userTables = []string{
+	"user",
+	"profile",
+	"workout",
+	"workout_exercise",
+	"workout_exercise_status",
+	"exercise_set",
+	"body_measurement",
+	"goal",
+	"calendar_feed",
+	"idempotency_key",
+}
+
+// countRows returns the number of rows in each of userTables, or nil when
+// the backend cannot count them.
+func countRows(tb testing.TB, s *Services) map[string]int {
+	tb.Helper()
+
+	if s.CountRows == nil {
+		return nil
+	}
+	counts := make(map[string]int, len(userTables))
+	for _, table := range userTables {
+		n, err := s.CountRows(table)
+		require.NoError(tb, err)
+		counts[table] = n
+	}
+	return counts
+}
@@ -0,0 +1,85 @@
+package fwttest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testWEStatusService(t *testing.T, s *Services) {
+	t.Run("UpdateWEStatus", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s))
+
+		wes, _, err := s.WorkoutExerciseService.FindWorkoutExercises(ctx, fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID})
+		require.NoError(t, err)
+		require.Len(t, wes, 1)
+
+		status, err := s.WEStatusService.FindWEStatusByWEID(ctx, wes[0].ID)
+		require.NoError(t, err)
+
+		completed, comments := "completed", "felt strong"
+		completedAt := time.Now().UTC().Truncate(time.Second)
+		updated, err := s.WEStatusService.UpdateWEStatus(ctx, status.ID, fwt.WEStatusUpdate{
+			Status:      &completed,
+			Comments:    &comments,
+			CompletedAt: &completedAt,
+		})
+		require.NoError(t, err)
+		require.Equal(t, completed, updated.Status)
+
+		other, err := s.WEStatusService.FindWEStatusByID(ctx, status.ID)
+		require.NoError(t, err)
+		require.Equal(t, completed, other.Status)
+		require.Equal(t, comments, other.Comments)
+		require.True(t, completedAt.Equal(other.CompletedAt))
+
+		statuses, _, err := s.WEStatusService.FindWEStatuses(ctx, fwt.WEStatusFilter{WorkoutExerciseID: &wes[0].ID, Status: &completed})
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+	})
+
+	t.Run("BumpsWorkoutVersion", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s), MustCreateExercise(t, s))
+
+		statuses, _, err := s.WEStatusService.FindWEStatuses(ctx, fwt.WEStatusFilter{WorkoutID: &workout.ID})
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+
+		// Neither update completes the workout, yet both change what is
+		// returned with it.
+		version := workout.Version
+		partial, comments := fwt.WEStatusPartiallyCompleted, "too heavy"
+		for _, upd := range []fwt.WEStatusUpdate{{Status: &partial}, {Comments: &comments}} {
+			_, err := s.WEStatusService.UpdateWEStatus(ctx, statuses[0].ID, upd)
+			require.NoError(t, err)
+
+			other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+			require.NoError(t, err)
+			require.Greater(t, other.Version, version)
+			version = other.Version
+		}
+	})
+
+	t.Run("ErrInvalidStatus", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s))
+
+		statuses, _, err := s.WEStatusService.FindWEStatuses(ctx, fwt.WEStatusFilter{WorkoutID: &workout.ID})
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+
+		invalid := "done"
+		_, err = s.WEStatusService.UpdateWEStatus(ctx, statuses[0].ID, fwt.WEStatusUpdate{Status: &invalid})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		_, err := s.WEStatusService.FindWEStatusByID(ctx, 1<<30)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
@@ -0,0 +1,452 @@
+package fwttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testWorkoutService(t *testing.T, s *Services) {
+	t.Run("CreateWorkout", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		ex0, ex1 := MustCreateExercise(t, s), MustCreateExercise(t, s)
+
+		workout := MustCreateWorkout(t, ctx, s, ex0, ex1)
+		require.NotZero(t, workout.ID)
+		require.Equal(t, user.ID, workout.UserID)
+		require.Equal(t, uint(1), workout.Version)
+
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, workout.Name, other.Name)
+		require.ElementsMatch(t, []string{ex0.Name, ex1.Name}, exerciseNames(other))
+
+		wes, n, err := s.WorkoutExerciseService.FindWorkoutExercises(ctx, fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID})
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		for _, we := range wes {
+			status, err := s.WEStatusService.FindWEStatusByWEID(ctx, we.ID)
+			require.NoError(t, err)
+			require.Equal(t, "pending", status.Status)
+		}
+	})
+
+	t.Run("ErrUnauthenticated", func(t *testing.T) {
+		err := s.WorkoutService.CreateWorkout(context.Background(), &fwt.Workout{
+			Name:          randomString(8),
+			ScheduledDate: time.Now().Add(48 * time.Hour),
+			Exercises:     []*fwt.Exercise{MustCreateExercise(t, s)},
+		})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("ErrNameRequired", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		err := s.WorkoutService.CreateWorkout(ctx, &fwt.Workout{
+			ScheduledDate: time.Now().Add(48 * time.Hour),
+			Exercises:     []*fwt.Exercise{MustCreateExercise(t, s)},
+		})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrExerciseNotFound", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		err := s.WorkoutService.CreateWorkout(ctx, &fwt.Workout{
+			Name:          randomString(8),
+			ScheduledDate: time.Now().Add(48 * time.Hour),
+			Exercises:     []*fwt.Exercise{{Name: randomString(12)}},
+		})
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("FindWorkouts", func(t *testing.T) {
+		user0, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		ex := MustCreateExercise(t, s)
+
+		w0 := MustCreateWorkout(t, ctx0, s, ex)
+		w1 := MustCreateWorkout(t, ctx0, s, ex)
+		w2 := MustCreateWorkout(t, ctx1, s, ex)
+
+		workouts, _, err := s.WorkoutService.FindWorkouts(ctx0, fwt.WorkoutFilter{UserID: &user0.ID})
+		require.NoError(t, err)
+		require.Len(t, workouts, 2)
+		require.Equal(t, w0.ID, workouts[0].ID)
+		require.Equal(t, w1.ID, workouts[1].ID)
+
+		_, err = s.WorkoutService.FindWorkoutByIDUserID(ctx0, w2.ID, user0.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("UpdateWorkout", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s))
+
+		name, version := randomString(8), workout.Version
+		updated, err := s.WorkoutService.UpdateWorkout(ctx, workout.ID, fwt.WorkoutUpdate{Name: &name, Version: &version})
+		require.NoError(t, err)
+		require.Equal(t, name, updated.Name)
+		require.Equal(t, uint(2), updated.Version)
+
+		_, err = s.WorkoutService.UpdateWorkout(ctx, workout.ID, fwt.WorkoutUpdate{Name: &name, Version: &version})
+		requireCode(t, err, fwt.ESTALE)
+	})
+
+	t.Run("ErrUpdateUnauthorized", func(t *testing.T) {
+		_, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx0, s, MustCreateExercise(t, s))
+
+		name := randomString(8)
+		_, err := s.WorkoutService.UpdateWorkout(ctx1, workout.ID, fwt.WorkoutUpdate{Name: &name})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		_, err = s.WorkoutService.AddExercisesToWorkout(ctx1, workout.ID, []string{MustCreateExercise(t, s).Name})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		err = s.WorkoutService.DeleteWorkout(ctx1, workout.ID, nil)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+
+	t.Run("AddRemoveExercises", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		ex0, ex1, ex2 := MustCreateExercise(t, s), MustCreateExercise(t, s), MustCreateExercise(t, s)
+		workout := MustCreateWorkout(t, ctx, s, ex0, ex1)
+
+		updated, err := s.WorkoutService.AddExercisesToWorkout(ctx, workout.ID, []string{ex1.Name, ex2.Name})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{ex0.Name, ex1.Name, ex2.Name}, exerciseNames(updated))
+		require.Greater(t, updated.Version, workout.Version)
+
+		updated, err = s.WorkoutService.RemoveExercisesFromWorkout(ctx, workout.ID, []string{ex0.Name})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{ex1.Name, ex2.Name}, exerciseNames(updated))
+
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{ex1.Name, ex2.Name}, exerciseNames(other))
+		require.Equal(t, updated.Version, other.Version)
+
+		_, err = s.WorkoutService.AddExercisesToWorkout(ctx, workout.ID, []string{randomString(12)})
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("ExerciseStatuses", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		ex0, ex1 := MustCreateExercise(t, s), MustCreateExercise(t, s)
+		workout := MustCreateWorkout(t, ctx, s, ex0)
+
+		_, err := s.WorkoutService.AddExercisesToWorkout(ctx, workout.ID, []string{ex1.Name})
+		require.NoError(t, err)
+
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Len(t, other.Exercises, 2)
+		for _, ex := range other.Exercises {
+			require.NotNil(t, ex.Status, ex.Name)
+			require.Equal(t, fwt.WEStatusPending, ex.Status.Status)
+			require.NotZero(t, ex.Status.WorkoutExerciseID)
+		}
+
+		err = s.WEStatusService.CreateWEStatus(ctx, &fwt.WEStatus{
+			WorkoutExerciseID: other.Exercises[0].Status.WorkoutExerciseID,
+			Status:            fwt.WEStatusPending,
+		})
+		requireCode(t, err, fwt.ECONFLICT)
+
+		removed := other.Exercises[1].Status
+		_, err = s.WorkoutService.RemoveExercisesFromWorkout(ctx, workout.ID, []string{ex1.Name})
+		require.NoError(t, err)
+
+		_, err = s.WEStatusService.FindWEStatusByWEID(ctx, removed.WorkoutExerciseID)
+		requireCode(t, err, fwt.ENOTFOUND)
+		_, err = s.WEStatusService.FindWEStatusByID(ctx, removed.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+
+	t.Run("DeleteWorkout", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s))
+
+		stale := workout.Version + 1
+		err := s.WorkoutService.DeleteWorkout(ctx, workout.ID, &stale)
+		requireCode(t, err, fwt.ESTALE)
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Len(t, other.Exercises, 1)
+
+		require.NoError(t, s.WorkoutService.DeleteWorkout(ctx, workout.ID, &workout.Version))
+
+		_, err = s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		requireCode(t, err, fwt.ENOTFOUND)
+
+		_, n, err := s.WorkoutExerciseService.FindWorkoutExercises(ctx, fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID})
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("FindWorkouts/DateRange", func(t *testing.T) {
+		user, ctx := MustCreateUser(t, s)
+		ex := MustCreateExercise(t, s)
+
+		for _, day := range []int{1, 15, 31, 32} {
+			require.NoError(t, s.WorkoutService.LogWorkout(ctx, &fwt.Workout{
+				Name:          randomString(8),
+				ScheduledDate: time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC),
+				Exercises:     []*fwt.Exercise{{Name: ex.Name}},
+			}))
+		}
+
+		from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+		a, _, err := s.WorkoutService.FindWorkouts(ctx, fwt.WorkoutFilter{UserID: &user.ID, From: &from, To: &to})
+		require.NoError(t, err)
+		require.Len(t, a, 3)
+		for _, w := range a {
+			require.Equal(t, time.March, w.ScheduledDate.Month())
+		}
+	})
+}
+
+func testWorkoutLifecycle(t *testing.T, s *Services) {
+	t.Run("StartFinish", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s), MustCreateExercise(t, s))
+		require.Equal(t, fwt.WorkoutStatusPlanned, workout.Status)
+
+		started, err := s.WorkoutService.StartWorkout(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusInProgress, started.Status)
+		require.False(t, started.StartedAt.IsZero())
+		require.Greater(t, started.Version, workout.Version)
+
+		_, err = s.WorkoutService.StartWorkout(ctx, workout.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+
+		mustCompleteExercises(t, ctx, s, workout, 1)
+
+		finished, err := s.WorkoutService.FinishWorkout(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusPartiallyCompleted, finished.Status)
+		require.False(t, finished.FinishedAt.IsZero())
+		require.GreaterOrEqual(t, finished.Duration(), time.Duration(0))
+
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusPartiallyCompleted, other.Status)
+		require.True(t, finished.StartedAt.Equal(other.StartedAt))
+		require.True(t, finished.FinishedAt.Equal(other.FinishedAt))
+
+		status := fwt.WorkoutStatusPartiallyCompleted
+		workouts, _, err := s.WorkoutService.FindWorkouts(ctx, fwt.WorkoutFilter{ID: &workout.ID, Status: &status})
+		require.NoError(t, err)
+		require.Len(t, workouts, 1)
+	})
+
+	t.Run("SkipWorkout", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s))
+
+		skipped, err := s.WorkoutService.SkipWorkout(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusSkipped, skipped.Status)
+		require.True(t, skipped.StartedAt.IsZero())
+
+		_, err = s.WorkoutService.StartWorkout(ctx, workout.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+		_, err = s.WorkoutService.FinishWorkout(ctx, workout.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("AutoComplete", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx, s, MustCreateExercise(t, s), MustCreateExercise(t, s))
+
+		_, err := s.WorkoutService.StartWorkout(ctx, workout.ID)
+		require.NoError(t, err)
+
+		mustCompleteExercises(t, ctx, s, workout, 1)
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusInProgress, other.Status)
+
+		mustCompleteExercises(t, ctx, s, workout, 2)
+		other, err = s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusCompleted, other.Status)
+		require.False(t, other.FinishedAt.IsZero())
+
+		_, err = s.WorkoutService.SkipWorkout(ctx, workout.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		_, ctx0 := MustCreateUser(t, s)
+		_, ctx1 := MustCreateUser(t, s)
+		workout := MustCreateWorkout(t, ctx0, s, MustCreateExercise(t, s))
+
+		_, err := s.WorkoutService.StartWorkout(ctx1, workout.ID)
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+
+		_, err = s.WorkoutService.SkipWorkout(ctx0, 1<<30)
+		requireCode(t, err, fwt.ENOTFOUND)
+	})
+}
+
+func testLoggedWorkouts(t *testing.T, s *Services) {
+	t.Run("LogWorkout", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		ex0, ex1 := MustCreateExercise(t, s), MustCreateExercise(t, s)
+
+		yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		workout := &fwt.Workout{
+			Name:          "Leg day",
+			ScheduledDate: yesterday,
+			StartedAt:     yesterday.Add(18 * time.Hour),
+			FinishedAt:    yesterday.Add(19 * time.Hour),
+			Exercises: []*fwt.Exercise{
+				{Name: ex0.Name, Sets: []*fwt.Set{{Reps: 5, Load: 100}, {Reps: 5, Load: 105}}},
+				{Name: ex1.Name, Status: &fwt.WEStatus{Status: fwt.WEStatusSkipped, Comments: "knee"}},
+			},
+		}
+		require.NoError(t, s.WorkoutService.LogWorkout(ctx, workout))
+		require.NotZero(t, workout.ID)
+		require.Equal(t, fwt.WorkoutStatusPartiallyCompleted, workout.Status)
+
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusPartiallyCompleted, other.Status)
+		require.Equal(t, time.Hour, other.Duration())
+		require.Len(t, other.Exercises, 2)
+
+		require.Equal(t, ex0.Name, other.Exercises[0].Name)
+		require.Equal(t, fwt.WEStatusCompleted, other.Exercises[0].Status.Status)
+		require.Len(t, other.Exercises[0].Sets, 2)
+		require.Equal(t, uint(5), other.Exercises[0].Sets[1].Reps)
+		require.Equal(t, 105.0, other.Exercises[0].Sets[1].Load)
+		require.Equal(t, other.Exercises[0].Status.WorkoutExerciseID, other.Exercises[0].Sets[0].WorkoutExerciseID)
+
+		require.Equal(t, fwt.WEStatusSkipped, other.Exercises[1].Status.Status)
+		require.Equal(t, "knee", other.Exercises[1].Status.Comments)
+		require.Empty(t, other.Exercises[1].Sets)
+
+		name := "Leg day (renamed)"
+		_, err = s.WorkoutService.UpdateWorkout(ctx, workout.ID, fwt.WorkoutUpdate{Name: &name})
+		require.NoError(t, err)
+
+		_, err = s.WorkoutService.RemoveExercisesFromWorkout(ctx, workout.ID, []string{ex0.Name})
+		require.NoError(t, err)
+		other, err = s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Len(t, other.Exercises, 1)
+	})
+
+	t.Run("LogWorkoutCompleted", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := &fwt.Workout{
+			Name:          "Today",
+			ScheduledDate: time.Now().UTC().Truncate(24 * time.Hour),
+			Exercises:     []*fwt.Exercise{{Name: MustCreateExercise(t, s).Name}},
+		}
+		require.NoError(t, s.WorkoutService.LogWorkout(ctx, workout))
+		require.Equal(t, fwt.WorkoutStatusCompleted, workout.Status)
+
+		_, err := s.WorkoutService.StartWorkout(ctx, workout.ID)
+		requireCode(t, err, fwt.ECONFLICT)
+	})
+
+	t.Run("ErrLogFuture", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		err := s.WorkoutService.LogWorkout(ctx, &fwt.Workout{
+			Name:          "Tomorrow",
+			ScheduledDate: time.Now().AddDate(0, 0, 2),
+			Exercises:     []*fwt.Exercise{{Name: MustCreateExercise(t, s).Name}},
+		})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("ErrLogInvalidSet", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		name := randomString(8)
+		err := s.WorkoutService.LogWorkout(ctx, &fwt.Workout{
+			Name:          name,
+			ScheduledDate: time.Now().AddDate(0, 0, -1),
+			Exercises: []*fwt.Exercise{
+				{Name: MustCreateExercise(t, s).Name, Sets: []*fwt.Set{{Reps: 5}, {Reps: 0}}},
+			},
+		})
+		requireCode(t, err, fwt.EINVALID)
+
+		// Nothing is stored when any part of the log is invalid.
+		_, n, err := s.WorkoutService.FindWorkouts(ctx, fwt.WorkoutFilter{Name: &name})
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("ErrLogPending", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		err := s.WorkoutService.LogWorkout(ctx, &fwt.Workout{
+			Name:          "Pending",
+			ScheduledDate: time.Now().AddDate(0, 0, -1),
+			Exercises: []*fwt.Exercise{
+				{Name: MustCreateExercise(t, s).Name, Status: &fwt.WEStatus{Status: fwt.WEStatusPending}},
+			},
+		})
+		requireCode(t, err, fwt.EINVALID)
+	})
+
+	t.Run("QuickStart", func(t *testing.T) {
+		_, ctx := MustCreateUser(t, s)
+		workout := &fwt.Workout{}
+		require.NoError(t, s.WorkoutService.QuickStartWorkout(ctx, workout))
+		require.Equal(t, fwt.WorkoutStatusInProgress, workout.Status)
+		require.Equal(t, "Quick workout", workout.Name)
+		require.False(t, workout.StartedAt.IsZero())
+
+		other, err := s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusInProgress, other.Status)
+		require.Empty(t, other.Exercises)
+		require.True(t, time.Now().UTC().Truncate(24*time.Hour).Equal(other.ScheduledDate.UTC()))
+
+		ex := MustCreateExercise(t, s)
+		_, err = s.WorkoutService.AddExercisesToWorkout(ctx, workout.ID, []string{ex.Name})
+		require.NoError(t, err)
+		mustCompleteExercises(t, ctx, s, workout, 1)
+
+		other, err = s.WorkoutService.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Equal(t, fwt.WorkoutStatusCompleted, other.Status)
+	})
+
+	t.Run("ErrQuickStartUnauthenticated", func(t *testing.T) {
+		err := s.WorkoutService.QuickStartWorkout(context.Background(), &fwt.Workout{})
+		requireCode(t, err, fwt.ENOTAUTHORIZED)
+	})
+}
+
+// mustCompleteExercises marks the first n exercises of workout as completed.
+func mustCompleteExercises(tb testing.TB, ctx context.Context, s *Services, workout *fwt.Workout, n int) {
+	tb.Helper()
+
+	statuses, _, err := s.WEStatusService.FindWEStatuses(ctx, fwt.WEStatusFilter{WorkoutID: &workout.ID})
+	require.NoError(tb, err)
+	require.GreaterOrEqual(tb, len(statuses), n)
+
+	completed := fwt.WEStatusCompleted
+	for _, status := range statuses[:n] {
+		_, err := s.WEStatusService.UpdateWEStatus(ctx, status.ID, fwt.WEStatusUpdate{Status: &completed})
+		require.NoError(tb, err)
+	}
+}
+
+func exerciseNames(w *fwt.Workout) []string {
+	names := make([]string, 0, len(w.Exercises))
+	for _, e := range w.Exercises {
+		names = append(names, e.Name)
+	}
+	return names
+}
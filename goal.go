@@ -0,0 +1,221 @@
+package fwt
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	GoalTypeBodyWeight = "body_weight"
+	GoalTypeLift       = "lift"
+	GoalTypeFrequency  = "frequency"
+	GoalTypeVolume     = "volume"
+)
+
+// Goal is a target a user works towards. What Target measures depends on
+// Type:
+//
+//   - body_weight: a body weight in kilograms.
+//   - lift: an estimated one repetition maximum in kilograms on ExerciseID.
+//   - frequency: finished workouts per week, starting on Monday.
+//   - volume: kilograms lifted per calendar month, summing reps times load
+//     over the sets of finished workouts.
+//
+// Weeks and months follow the user's timezone.
+//
+// Start is the value at the time the goal was created, so that body weight
+// and lift goals measure progress from where the user began. TargetDate is
+// optional and only used to tell whether the user is on track.
+type Goal struct {
+	ID         uint      `json:"id"`
+	UserID     uint      `json:"user_id"`
+	Type       string    `json:"type"`
+	ExerciseID uint      `json:"exercise_id,omitempty"`
+	Target     float64   `json:"target"`
+	Start      float64   `json:"start"`
+	TargetDate time.Time `json:"target_date"`
+	ArchivedAt time.Time `json:"archived_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (g *Goal) Validate() error {
+	if g.UserID == uint(0) {
+		return Errorf(EINVALID, "UserID is required.")
+	}
+
+	switch g.Type {
+	case GoalTypeBodyWeight, GoalTypeFrequency, GoalTypeVolume:
+	case GoalTypeLift:
+		if g.ExerciseID == uint(0) {
+			return Errorf(EINVALID, "Exercise is required for a lift goal.")
+		}
+	default:
+		return Errorf(EINVALID, "Type is invalid.")
+	}
+
+	if g.Target <= 0 {
+		return Errorf(EINVALID, "Target must be positive.")
+	}
+
+	return nil
+}
+
+// ValidateStart checks the goal against its Start, once it is known, so that
+// progress can be measured from one to the other.
+func (g *Goal) ValidateStart() error {
+	switch g.Type {
+	case GoalTypeBodyWeight:
+		if g.Start <= 0 {
+			return Errorf(EINVALID, "A body weight must be recorded before setting a body weight goal.")
+		}
+	case GoalTypeLift:
+		if g.Target <= g.Start {
+			return Errorf(EINVALID, "Target must be above the best lift so far.")
+		}
+	}
+	return nil
+}
+
+// Periodic reports whether the goal is measured afresh every period rather
+// than accumulated since it was created.
+func (g *Goal) Periodic() bool {
+	return g.Type == GoalTypeFrequency || g.Type == GoalTypeVolume
+}
+
+// Period returns the week or month containing now that a periodic goal is
+// measured over, as a half-open interval. Weeks and months begin at
+// midnight in the location of now, which should be the user's timezone.
+func (g *Goal) Period(now time.Time) (start, end time.Time) {
+	if g.Type == GoalTypeVolume {
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	start = time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 0, 7)
+}
+
+// SampleRange returns the half-open interval of time the samples passed to
+// Evaluate must come from. A zero end leaves the interval open.
+func (g *Goal) SampleRange(now time.Time) (start, end time.Time) {
+	if g.Periodic() {
+		return g.Period(now)
+	}
+	return g.CreatedAt, time.Time{}
+}
+
+// GoalSample is one observation towards a goal: a measured body weight, the
+// estimated one repetition maximum of a set, a finished workout counted as
+// one, or the volume of a set.
+type GoalSample struct {
+	At    time.Time
+	Value float64
+}
+
+// GoalProgress is how far a goal has come. Current is in the unit of the
+// goal's Target. ProjectedAt is when the target will be reached if the pace
+// so far is kept, and is nil when it is reached already or cannot be
+// reached that way.
+type GoalProgress struct {
+	GoalID      uint       `json:"goal_id"`
+	Current     float64    `json:"current"`
+	Percent     float64    `json:"percent"`
+	Achieved    bool       `json:"achieved"`
+	OnTrack     bool       `json:"on_track"`
+	ProjectedAt *time.Time `json:"projected_at"`
+}
+
+// maxProjection bounds projections, past which a pace is taken to be too
+// slow to ever reach the target.
+const maxProjection = 100 * 365 * 24 * time.Hour
+
+// Evaluate returns the progress of the goal at now given the samples taken
+// over SampleRange, ordered by time.
+func (g *Goal) Evaluate(samples []GoalSample, now time.Time) *GoalProgress {
+	p := &GoalProgress{GoalID: g.ID}
+
+	if g.Periodic() {
+		start, end := g.Period(now)
+		for _, s := range samples {
+			p.Current += s.Value
+		}
+		p.Percent = progressPercent(p.Current, g.Target)
+		p.Achieved = p.Percent >= 100
+
+		// Project the pace of the period so far onto the rest of it.
+		if elapsed := now.Sub(start); !p.Achieved && p.Current > 0 && elapsed > 0 {
+			need := time.Duration(float64(elapsed) * g.Target / p.Current)
+			if t := start.Add(need); t.Before(end) {
+				p.ProjectedAt = &t
+			}
+		}
+		p.OnTrack = p.Achieved || p.ProjectedAt != nil
+		return p
+	}
+
+	// Body weight follows the latest measurement, a lift the best set.
+	p.Current = g.Start
+	reachedAt := g.CreatedAt
+	for _, s := range samples {
+		if g.Type == GoalTypeBodyWeight || s.Value > p.Current {
+			p.Current, reachedAt = s.Value, s.At
+		}
+	}
+	p.Percent = progressPercent(p.Current-g.Start, g.Target-g.Start)
+	p.Achieved = p.Percent >= 100
+
+	// Extend the pace from Start to Current on to Target.
+	if elapsed := reachedAt.Sub(g.CreatedAt); !p.Achieved && elapsed > 0 && p.Current != g.Start {
+		remaining := float64(elapsed) * (g.Target - p.Current) / (p.Current - g.Start)
+		if remaining > 0 && remaining < float64(maxProjection) {
+			t := reachedAt.Add(time.Duration(remaining))
+			p.ProjectedAt = &t
+		}
+	}
+	p.OnTrack = p.Achieved || (p.ProjectedAt != nil && (g.TargetDate.IsZero() || !p.ProjectedAt.After(g.TargetDate)))
+
+	return p
+}
+
+// progressPercent returns done as a percentage of total, clamped to 0-100
+// and rounded to one decimal.
+func progressPercent(done, total float64) float64 {
+	if total == 0 {
+		return 100
+	}
+	v := math.Round(done/total*1000) / 10
+	return math.Max(0, math.Min(100, v))
+}
+
+// EstimateOneRepMax estimates the heaviest load that could be lifted once
+// from a set of reps with load, using the Epley formula.
+func EstimateOneRepMax(reps uint, load float64) float64 {
+	if reps <= 1 {
+		return load
+	}
+	return load * (1 + float64(reps)/30)
+}
+
+type GoalService interface {
+	FindGoalByID(ctx context.Context, id uint) (*Goal, error)
+	FindGoals(ctx context.Context, filter GoalFilter) ([]*Goal, int, error)
+	CreateGoal(ctx context.Context, goal *Goal) error
+	ArchiveGoal(ctx context.Context, id uint) (*Goal, error)
+
+	// FindGoalProgress evaluates the goal against the user's body
+	// measurements and finished workouts.
+	FindGoalProgress(ctx context.Context, id uint) (*GoalProgress, error)
+}
+
+type GoalFilter struct {
+	ID       *uint   `json:"id"`
+	UserID   *uint   `json:"user_id"`
+	Type     *string `json:"type"`
+	Archived *bool   `json:"archived"`
+
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
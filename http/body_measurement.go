@@ -0,0 +1,344 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+func (s *Server) createBodyMeasurement() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Measurement struct {
+				MeasuredAt       time.Time `json:"measured_at"`
+				Weight           float64   `json:"weight"`
+				BodyFat          float64   `json:"body_fat"`
+				Waist            float64   `json:"waist"`
+				Chest            float64   `json:"chest"`
+				Arms             float64   `json:"arms"`
+				Thighs           float64   `json:"thighs"`
+				RestingHeartRate uint      `json:"resting_heart_rate"`
+				Notes            string    `json:"notes"`
+			} `json:"measurement"`
+			Unit string `json:"unit"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, req.Unit)
+		if !ok {
+			return
+		}
+
+		m := fwt.BodyMeasurement{
+			UserID:           user.ID,
+			MeasuredAt:       req.Measurement.MeasuredAt,
+			Weight:           units.Weight.ToKilograms(req.Measurement.Weight),
+			BodyFat:          req.Measurement.BodyFat,
+			Waist:            units.Length.ToCentimetres(req.Measurement.Waist),
+			Chest:            units.Length.ToCentimetres(req.Measurement.Chest),
+			Arms:             units.Length.ToCentimetres(req.Measurement.Arms),
+			Thighs:           units.Length.ToCentimetres(req.Measurement.Thighs),
+			RestingHeartRate: req.Measurement.RestingHeartRate,
+			Notes:            req.Measurement.Notes,
+		}
+
+		if err := s.BodyMeasurementService.CreateBodyMeasurement(c.Request.Context(), &m); err != nil {
+			if fwt.ErrorCode(err) == fwt.EINVALID {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
+			s.Logger.ErrorContext(c.Request.Context(), "error in create body measurement handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"measurement": bodyMeasurementInUnits(&m, units),
+			"units":       units,
+		})
+	}
+}
+
+// getBodyMeasurements lists the measurements of the current user, oldest
+// first. The optional from and to query parameters bound the measurement
+// time and accept either RFC 3339 times or dates; a date given as to
+// includes the whole day.
+func (s *Server) getBodyMeasurements() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
+		filter := fwt.BodyMeasurementFilter{UserID: &user.ID}
+		if v := c.Query("from"); v != "" {
+			from, err := parseTimeParam(v, false)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid from query param",
+				})
+				return
+			}
+			filter.From = &from
+		}
+		if v := c.Query("to"); v != "" {
+			to, err := parseTimeParam(v, true)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid to query param",
+				})
+				return
+			}
+			filter.To = &to
+		}
+		if v := c.Query("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid limit query param",
+				})
+				return
+			}
+			filter.Limit = limit
+		}
+		if v := c.Query("offset"); v != "" {
+			offset, err := strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid offset query param",
+				})
+				return
+			}
+			filter.Offset = offset
+		}
+
+		measurements, n, err := s.BodyMeasurementService.FindBodyMeasurements(c.Request.Context(), filter)
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get body measurements handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"count":        n,
+			"measurements": bodyMeasurementsInUnits(measurements, units),
+			"units":        units,
+		})
+	}
+}
+
+func (s *Server) getOneBodyMeasurement() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid measurement id param",
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
+		measurementID := uint(id)
+		measurements, _, err := s.BodyMeasurementService.FindBodyMeasurements(c.Request.Context(), fwt.BodyMeasurementFilter{ID: &measurementID, UserID: &user.ID})
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get body measurement handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		} else if len(measurements) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Body Measurement not found.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"measurement": bodyMeasurementInUnits(measurements[0], units),
+			"units":       units,
+		})
+	}
+}
+
+func (s *Server) updateBodyMeasurement() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid measurement id param",
+			})
+			return
+		}
+
+		var req struct {
+			Measurement fwt.BodyMeasurementUpdate `json:"measurement"`
+			Unit        string                    `json:"unit"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, req.Unit)
+		if !ok {
+			return
+		}
+
+		upd := req.Measurement
+		if v := upd.Weight; v != nil {
+			weight := units.Weight.ToKilograms(*v)
+			upd.Weight = &weight
+		}
+		for _, v := range []**float64{&upd.Waist, &upd.Chest, &upd.Arms, &upd.Thighs} {
+			if *v != nil {
+				length := units.Length.ToCentimetres(**v)
+				*v = &length
+			}
+		}
+
+		m, err := s.BodyMeasurementService.UpdateBodyMeasurement(c.Request.Context(), uint(id), upd)
+		if err != nil {
+			switch fwt.ErrorCode(err) {
+			case fwt.EINVALID:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTFOUND:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTAUTHORIZED:
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			default:
+				s.Logger.ErrorContext(c.Request.Context(), "error in update body measurement handler", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "measurement updated successfully",
+			"measurement": bodyMeasurementInUnits(m, units),
+			"units":       units,
+		})
+	}
+}
+
+func (s *Server) deleteBodyMeasurement() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid measurement id param",
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		if err := s.BodyMeasurementService.DeleteBodyMeasurement(c.Request.Context(), uint(id)); err != nil {
+			switch fwt.ErrorCode(err) {
+			case fwt.ENOTFOUND:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTAUTHORIZED:
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			default:
+				s.Logger.ErrorContext(c.Request.Context(), "error in delete body measurement handler", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "measurement deleted successfully",
+		})
+	}
+}
+
+// parseTimeParam parses a query parameter holding an RFC 3339 time or a
+// date. With endOfDay set, a date stands for the last instant of that day.
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
+}
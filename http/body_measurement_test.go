@@ -0,0 +1,170 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyMeasurementHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+
+	m := &fwt.BodyMeasurement{UserID: jane.ID, MeasuredAt: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), Weight: 82}
+	ctx := fwt.NewContextWithUser(context.Background(), jane)
+	require.NoError(t, s.BodyMeasurementService.CreateBodyMeasurement(ctx, m))
+	path := fmt.Sprintf("/api/v1/measurements/%d", m.ID)
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Create/ErrNoToken",
+			method: http.MethodPost,
+			path:   "/api/v1/measurements",
+			body:   `{"measurement":{"weight":80}}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Create/ErrInvalid",
+			method: http.MethodPost,
+			path:   "/api/v1/measurements",
+			token:  janeToken,
+			body:   `{"measurement":{"measured_at":"2024-03-05T08:00:00Z"}}`,
+			status: http.StatusBadRequest,
+			error:  "Body Measurement must record at least one value.",
+		},
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			path:   "/api/v1/measurements",
+			token:  janeToken,
+			body:   `{"measurement":{"measured_at":"2024-03-05T08:00:00Z","weight":80,"body_fat":17.5}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				m := body["measurement"].(map[string]any)
+				require.Equal(t, float64(80), m["weight"])
+				require.Equal(t, 17.5, m["body_fat"])
+			},
+		},
+		{
+			name:   "Profile/WeightFromLatest",
+			method: http.MethodPost,
+			path:   "/api/v1/profile/create",
+			token:  janeToken,
+			body:   `{"profile":{"first_name":"Jane"}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(80), body["profile"].(map[string]any)["weight"])
+			},
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			path:   "/api/v1/measurements",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(2), body["count"])
+				ms := body["measurements"].([]any)
+				require.Equal(t, float64(82), ms[0].(map[string]any)["weight"])
+				require.Equal(t, float64(80), ms[1].(map[string]any)["weight"])
+			},
+		},
+		{
+			name:   "List/Range",
+			method: http.MethodGet,
+			path:   "/api/v1/measurements?from=2024-03-02&to=2024-03-05",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(1), body["count"])
+			},
+		},
+		{
+			name:   "List/ErrInvalidRange",
+			method: http.MethodGet,
+			path:   "/api/v1/measurements?from=march",
+			token:  janeToken,
+			status: http.StatusBadRequest,
+			error:  "Invalid from query param",
+		},
+		{
+			name:   "List/OtherUser",
+			method: http.MethodGet,
+			path:   "/api/v1/measurements",
+			token:  johnToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(0), body["count"])
+			},
+		},
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			path:   path,
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(82), body["measurement"].(map[string]any)["weight"])
+			},
+		},
+		{
+			name:   "Get/ErrOtherUser",
+			method: http.MethodGet,
+			path:   path,
+			token:  johnToken,
+			status: http.StatusNotFound,
+			error:  "Body Measurement not found.",
+		},
+		{
+			name:   "Update/ErrUnauthorized",
+			method: http.MethodPatch,
+			path:   path,
+			token:  johnToken,
+			body:   `{"measurement":{"weight":90}}`,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to update this body measurement.",
+		},
+		{
+			name:   "Update",
+			method: http.MethodPatch,
+			path:   path,
+			token:  janeToken,
+			body:   `{"measurement":{"waist":85}}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				m := body["measurement"].(map[string]any)
+				require.Equal(t, float64(82), m["weight"])
+				require.Equal(t, float64(85), m["waist"])
+			},
+		},
+		{
+			name:   "Delete/ErrUnauthorized",
+			method: http.MethodDelete,
+			path:   path,
+			token:  johnToken,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to delete this body measurement.",
+		},
+		{
+			name:   "Delete",
+			method: http.MethodDelete,
+			path:   path,
+			token:  janeToken,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Delete/ErrNotFound",
+			method: http.MethodDelete,
+			path:   path,
+			token:  janeToken,
+			status: http.StatusNotFound,
+			error:  "Body Measurement not found.",
+		},
+	})
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+// getCalendar returns the workouts of the current user scheduled in the
+// month given by the month query parameter as YYYY-MM, grouped by day. It
+// defaults to the current month in the user's timezone.
+func (s *Server) getCalendar() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		loc, err := s.userLocation(c.Request.Context(), user.ID)
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get calendar handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		month := s.Now().In(loc)
+		if v := c.Query("month"); v != "" {
+			t, err := time.Parse("2006-01", v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid month query param",
+				})
+				return
+			}
+			month = t
+		}
+
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
+		from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 1, 0).Add(-time.Second)
+		workouts, _, err := s.WorkoutService.FindWorkouts(c.Request.Context(), fwt.WorkoutFilter{
+			UserID: &user.ID,
+			From:   &from,
+			To:     &to,
+		})
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get calendar handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"month": from.Format("2006-01"),
+			"days":  fwt.GroupWorkoutsByDay(workoutsInUnits(workouts, units)),
+			"units": units,
+		})
+	}
+}
+
+// rotateCalendarFeed gives the current user a new secret feed URL. The
+// previous URL stops working.
+func (s *Server) rotateCalendarFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		feed, err := s.CalendarFeedService.RotateCalendarFeed(c.Request.Context())
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in rotate calendar feed handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"feed": feed,
+			"url":  requestBaseURL(c) + "/api/v1/calendar/feed/" + feed.Token + ".ics",
+		})
+	}
+}
+
+// getCalendarFeed serves the upcoming planned workouts of the owner of the
+// feed token as an iCalendar file. The token is the only credential, so the
+// route is not authenticated.
+func (s *Server) getCalendarFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+		feed, err := s.CalendarFeedService.FindCalendarFeedByToken(c.Request.Context(), token)
+		if err != nil {
+			if fwt.ErrorCode(err) == fwt.ENOTFOUND {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
+			s.Logger.ErrorContext(c.Request.Context(), "error in get calendar feed handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		// The feed of an account scheduled for deletion is served no
+		// more, as the account cannot be signed in to either.
+		user, err := s.UserService.FindUserByID(c.Request.Context(), feed.UserID)
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get calendar feed handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+		if user.Deleted() {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Calendar feed not found.",
+			})
+			return
+		}
+
+		loc, err := s.userLocation(c.Request.Context(), feed.UserID)
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get calendar feed handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		now := s.Now()
+		today := fwt.Date(now.In(loc))
+		status := fwt.WorkoutStatusPlanned
+		workouts, _, err := s.WorkoutService.FindWorkouts(c.Request.Context(), fwt.WorkoutFilter{
+			UserID: &feed.UserID,
+			From:   &today,
+			Status: &status,
+		})
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get calendar feed handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.Data(http.StatusOK, calendarContentType, encodeICS(workouts, now))
+	}
+}
+
+// userLocation returns the timezone of the user's profile, or UTC when the
+// user has no profile yet.
+func (s *Server) userLocation(ctx context.Context, userID uint) (*time.Location, error) {
+	profile, err := s.ProfileService.FindProfileByUserID(ctx, userID)
+	if fwt.ErrorCode(err) == fwt.ENOTFOUND {
+		return time.UTC, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return profile.Location(), nil
+}
+
+// requestBaseURL returns the scheme and host the request was made to.
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// encodeICS encodes workouts as an iCalendar (RFC 5545) file of all-day
+// events, listing the exercises of each workout in its description.
+func encodeICS(workouts []*fwt.Workout, now time.Time) []byte {
+	var b bytes.Buffer
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//fwt//Workouts//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:Workouts")
+
+	for _, w := range workouts {
+		names := make([]string, 0, len(w.Exercises))
+		for _, ex := range w.Exercises {
+			names = append(names, ex.Name)
+		}
+		day := w.ScheduledDate.UTC()
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, fmt.Sprintf("UID:workout-%d@fwt", w.ID))
+		writeICSLine(&b, "DTSTAMP:"+now.UTC().Format("20060102T150405Z"))
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+day.Format("20060102"))
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(w.Name))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICSText(strings.Join(names, "\n")))
+		if !w.UpdatedAt.IsZero() {
+			writeICSLine(&b, "LAST-MODIFIED:"+w.UpdatedAt.UTC().Format("20060102T150405Z"))
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+// writeICSLine writes a content line ended by CRLF, folding it so that no
+// line is longer than 75 octets without splitting a UTF-8 sequence.
+func writeICSLine(b *bytes.Buffer, line string) {
+	n := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	b.WriteString("\r\n")
+}
+
+var icsTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
@@ -0,0 +1,197 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+	ctx := fwt.NewContextWithUser(context.Background(), jane)
+
+	planned := &fwt.Workout{
+		Name:          "Legs, glutes; core – a long session before the weekend with extra mobility work",
+		ScheduledDate: time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 2),
+		Exercises:     []*fwt.Exercise{{Name: "Push-up"}, {Name: "Squat"}},
+	}
+	require.NoError(t, s.WorkoutService.CreateWorkout(ctx, planned))
+
+	logged := &fwt.Workout{
+		Name:          "Done",
+		ScheduledDate: time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1),
+		Exercises:     []*fwt.Exercise{{Name: "Squat"}},
+	}
+	require.NoError(t, s.WorkoutService.LogWorkout(ctx, logged))
+
+	// dayOf returns the calendar day of date from a calendar response.
+	dayOf := func(t *testing.T, body map[string]any, date time.Time) map[string]any {
+		for _, v := range body["days"].([]any) {
+			if day := v.(map[string]any); day["date"] == date.Format(time.DateOnly) {
+				return day
+			}
+		}
+		t.Fatalf("no calendar day %s", date.Format(time.DateOnly))
+		return nil
+	}
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Month/Planned",
+			method: http.MethodGet,
+			path:   "/api/v1/calendar?month=" + planned.ScheduledDate.Format("2006-01"),
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, planned.ScheduledDate.Format("2006-01"), body["month"])
+				day := dayOf(t, body, planned.ScheduledDate)
+				require.Equal(t, fwt.WorkoutStatusPlanned, day["status"])
+				require.Len(t, day["workouts"], 1)
+			},
+		},
+		{
+			name:   "Month/Completed",
+			method: http.MethodGet,
+			path:   "/api/v1/calendar?month=" + logged.ScheduledDate.Format("2006-01"),
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				day := dayOf(t, body, logged.ScheduledDate)
+				require.Equal(t, fwt.WorkoutStatusCompleted, day["status"])
+			},
+		},
+		{
+			name:   "Month/Empty",
+			method: http.MethodGet,
+			path:   "/api/v1/calendar?month=2001-01",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Empty(t, body["days"])
+			},
+		},
+		{
+			name:   "Month/OtherUser",
+			method: http.MethodGet,
+			path:   "/api/v1/calendar?month=" + planned.ScheduledDate.Format("2006-01"),
+			token:  johnToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Empty(t, body["days"])
+			},
+		},
+		{
+			name:   "Month/ErrInvalid",
+			method: http.MethodGet,
+			path:   "/api/v1/calendar?month=march",
+			token:  janeToken,
+			status: http.StatusBadRequest,
+			error:  "Invalid month query param",
+		},
+		{
+			name:   "Feed/ErrNoToken",
+			method: http.MethodPost,
+			path:   "/api/v1/calendar/feed",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Feed/ErrNotFound",
+			method: http.MethodGet,
+			path:   "/api/v1/calendar/feed/unknown.ics",
+			status: http.StatusNotFound,
+			error:  "Calendar feed not found.",
+		},
+	})
+
+	// rotate returns the path of a new feed URL for jane.
+	rotate := func(t *testing.T) string {
+		w := doRequest(s.Router, http.MethodPost, "/api/v1/calendar/feed", janeToken, "", nil)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var body struct {
+			URL string `json:"url"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		i := strings.Index(body.URL, "/api/v1/calendar/feed/")
+		require.NotEqual(t, -1, i, body.URL)
+		return body.URL[i:]
+	}
+
+	t.Run("Feed", func(t *testing.T) {
+		path := rotate(t)
+
+		w := doRequest(s.Router, http.MethodGet, path, "", "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+
+		ics := w.Body.String()
+		require.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+		require.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+		for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+			require.LessOrEqual(t, len(line), 75, line)
+		}
+
+		// Unfold the lines folded to fit the limit.
+		ics = strings.ReplaceAll(ics, "\r\n ", "")
+
+		require.Contains(t, ics, fmt.Sprintf("UID:workout-%d@fwt\r\n", planned.ID))
+		require.Contains(t, ics, "DTSTART;VALUE=DATE:"+planned.ScheduledDate.Format("20060102")+"\r\n")
+		require.Contains(t, ics, `SUMMARY:Legs\, glutes\; core – a long session before the weekend with extra mobility work`+"\r\n")
+		require.Contains(t, ics, `DESCRIPTION:Push-up\nSquat`+"\r\n")
+		require.NotContains(t, ics, fmt.Sprintf("UID:workout-%d@fwt", logged.ID))
+	})
+
+	t.Run("Feed/Rotate", func(t *testing.T) {
+		old := rotate(t)
+		path := rotate(t)
+		require.NotEqual(t, old, path)
+
+		w := doRequest(s.Router, http.MethodGet, old, "", "", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(s.Router, http.MethodGet, path, "", "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("Feed/TokenNotLogged", func(t *testing.T) {
+		exporter := MustInstallTracer(t)
+		var buf bytes.Buffer
+		logger := s.Logger
+		s.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+		t.Cleanup(func() { s.Logger = logger })
+
+		path := rotate(t)
+		token := strings.TrimSuffix(strings.TrimPrefix(path, "/api/v1/calendar/feed/"), ".ics")
+
+		w := doRequest(s.Router, http.MethodGet, path, "", "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		require.Contains(t, buf.String(), `"path":"/api/v1/calendar/feed/:token"`)
+		require.NotContains(t, buf.String(), token)
+		for _, span := range exporter.GetSpans() {
+			for _, attr := range span.Attributes {
+				require.NotContains(t, attr.Value.Emit(), token, attr.Key)
+			}
+		}
+	})
+
+	t.Run("Feed/ErrDeletedUser", func(t *testing.T) {
+		path := rotate(t)
+
+		_, err := s.UserService.ScheduleUserDeletion(ctx, jane.ID)
+		require.NoError(t, err)
+
+		w := doRequest(s.Router, http.MethodGet, path, "", "", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
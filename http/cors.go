@@ -1,7 +1,7 @@
 package http
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"slices"
@@ -24,8 +24,9 @@ func CORSMiddleware() gin.HandlerFunc {
 		}
 
 		ctx.Header("Access-Control-Allow-Credentials", "true")
-		ctx.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		ctx.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		ctx.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, If-Match, If-None-Match, Idempotency-Key")
+		ctx.Header("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed, X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
+		ctx.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if ctx.Request.Method == "OPTIONS" {
 			ctx.AbortWithStatus(204)
@@ -37,6 +38,6 @@ func CORSMiddleware() gin.HandlerFunc {
 }
 
 func getAllowedOrigins() []string {
-	log.Print(os.Getenv("ALLOWED_ORIGINS"))
+	slog.Debug("allowed origins", "origins", os.Getenv("ALLOWED_ORIGINS"))
 	return strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",")
 }
@@ -12,6 +12,7 @@ import (
 )
 
 func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
 	os.Setenv("ALLOWED_ORIGINS", "https://example.com")
 	code := m.Run()
 	os.Unsetenv("ALLOWED_ORIGINS")
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+// etag formats a strong entity tag for a resource version rendered in
+// units, such as "3-kg-cm-km". The units are part of the tag because the
+// same version reads differently in kilograms and in pounds.
+func etag(version uint, units fwt.Units) string {
+	return fmt.Sprintf(`"%d-%s-%s-%s"`, version, units.Weight, units.Length, units.Distance)
+}
+
+// etagMatches reports whether an If-None-Match header value matches the
+// given version rendered in units. Weak tags are compared by their opaque
+// value.
+func etagMatches(header string, version uint, units fwt.Units) bool {
+	want := etag(version, units)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion extracts the expected version from the If-Match header. It
+// returns nil when the header is absent or "*", and ok=false when the header
+// cannot be parsed as a single entity tag produced by etag(). Only the
+// version is compared, since a change of units does not change the
+// resource, and a bare version is accepted too.
+func ifMatchVersion(c *gin.Context) (version *uint, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	tag, _, _ = strings.Cut(tag, "-")
+	v, err := strconv.ParseUint(tag, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	version = new(uint)
+	*version = uint(v)
+	return version, true
+}
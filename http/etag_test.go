@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	require.Equal(t, `"3-kg-cm-km"`, etag(3, fwt.MetricUnits))
+	require.Equal(t, `"3-lb-in-mi"`, etag(3, fwt.ImperialUnits))
+}
+
+func TestETagMatches(t *testing.T) {
+	require.True(t, etagMatches(`"3-kg-cm-km"`, 3, fwt.MetricUnits))
+	require.True(t, etagMatches(`W/"3-kg-cm-km"`, 3, fwt.MetricUnits))
+	require.True(t, etagMatches(`"1-kg-cm-km", "3-kg-cm-km"`, 3, fwt.MetricUnits))
+	require.True(t, etagMatches(`*`, 3, fwt.MetricUnits))
+	require.False(t, etagMatches(`"2-kg-cm-km"`, 3, fwt.MetricUnits))
+	require.False(t, etagMatches(`"3-kg-cm-km"`, 3, fwt.ImperialUnits))
+	require.False(t, etagMatches(`"3"`, 3, fwt.MetricUnits))
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	newContext := func(header string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/", nil)
+		if header != "" {
+			c.Request.Header.Set("If-Match", header)
+		}
+		return c
+	}
+
+	t.Run("Absent", func(t *testing.T) {
+		version, ok := ifMatchVersion(newContext(""))
+		require.True(t, ok)
+		require.Nil(t, version)
+	})
+
+	t.Run("Wildcard", func(t *testing.T) {
+		version, ok := ifMatchVersion(newContext("*"))
+		require.True(t, ok)
+		require.Nil(t, version)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		version, ok := ifMatchVersion(newContext(`"7"`))
+		require.True(t, ok)
+		require.Equal(t, uint(7), *version)
+	})
+
+	t.Run("WithUnits", func(t *testing.T) {
+		version, ok := ifMatchVersion(newContext(`"7-lb-in-mi"`))
+		require.True(t, ok)
+		require.Equal(t, uint(7), *version)
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		_, ok := ifMatchVersion(newContext(`"abc"`))
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,391 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+const (
+	DefaultExportLinkTTL = 15 * time.Minute
+
+	// exportRetention is how long an export is kept on disk, during which
+	// new download links can be asked for.
+	exportRetention = 24 * time.Hour
+
+	// exportTimeout bounds the time spent building an export.
+	exportTimeout = 5 * time.Minute
+)
+
+// createExport starts building an archive of all the data of the current
+// user. The export is built in the background; its status, and once ready
+// a download link, are returned by getExport.
+func (s *Server) createExport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		id, err := newExportID()
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in create export handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		now := s.Now()
+		s.exportsMu.Lock()
+		// Building the same data twice at once is wasted work, so a pending
+		// export is returned instead of starting another one.
+		for _, e := range s.exports {
+			if e.UserID == user.ID && e.Status == fwt.ExportStatusPending {
+				export := *e
+				s.exportsMu.Unlock()
+				c.JSON(http.StatusAccepted, gin.H{
+					"export": export,
+				})
+				return
+			}
+		}
+		export := &fwt.Export{
+			ID:        id,
+			UserID:    user.ID,
+			Status:    fwt.ExportStatusPending,
+			CreatedAt: now,
+		}
+		s.exports[id] = export
+		s.exportWG.Add(1)
+		other := *export
+		s.exportsMu.Unlock()
+
+		// The request context is done once the response is written, but
+		// its values, such as the user and request ID, are still needed.
+		go s.buildExport(context.WithoutCancel(c.Request.Context()), id, user.ID)
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"export": other,
+		})
+	}
+}
+
+// getExport returns the status of an export of the current user and, once
+// it is ready, a signed link to download it that expires shortly.
+func (s *Server) getExport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		export := s.findExport(c.Param("id"))
+		if export == nil || export.UserID != user.ID {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Export not found.",
+			})
+			return
+		}
+
+		resp := gin.H{"export": export}
+		if export.Status == fwt.ExportStatusReady {
+			expiresAt := s.Now().Add(s.ExportLinkTTL).Truncate(time.Second)
+			q := url.Values{}
+			q.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+			q.Set("signature", s.signExport(export.ID, expiresAt.Unix()))
+
+			resp["url"] = requestBaseURL(c) + "/api/v1/users/export/" + export.ID + "/download?" + q.Encode()
+			resp["url_expires_at"] = expiresAt
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// downloadExport serves an export archive. The signed link is the only
+// credential, so the route is not authenticated.
+func (s *Server) downloadExport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if err != nil || !hmac.Equal([]byte(c.Query("signature")), []byte(s.signExport(id, expires))) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Invalid download link.",
+			})
+			return
+		}
+
+		if s.Now().Unix() > expires {
+			c.JSON(http.StatusGone, gin.H{
+				"error": "Download link has expired.",
+			})
+			return
+		}
+
+		// The id is safe to use in a path, as only ids made by
+		// newExportID are ever signed.
+		path, err := s.findExportFile(id)
+		var info fs.FileInfo
+		if err == nil {
+			info, err = os.Stat(path)
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Export not found.",
+				})
+				return
+			}
+
+			s.Logger.ErrorContext(c.Request.Context(), "error in download export handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.FileAttachment(path, "fwt-export-"+info.ModTime().UTC().Format(time.DateOnly)+".zip")
+	}
+}
+
+// buildExport writes the archive of the export with the given id and
+// records whether it succeeded.
+func (s *Server) buildExport(ctx context.Context, id string, userID uint) {
+	defer s.exportWG.Done()
+
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	status := fwt.ExportStatusReady
+	if err := s.writeExport(ctx, id, userID); err != nil {
+		s.Logger.ErrorContext(ctx, "cannot build export", "export_id", id, "error", err)
+		status = fwt.ExportStatusFailed
+	}
+
+	s.exportsMu.Lock()
+	defer s.exportsMu.Unlock()
+	export, ok := s.exports[id]
+	if !ok {
+		// The exports of the user were removed while this one was being
+		// built, so its archive must not outlive them.
+		os.Remove(s.exportPath(userID, id))
+		return
+	}
+	export.Status = status
+	if status == fwt.ExportStatusReady {
+		export.ReadyAt = s.Now()
+	}
+}
+
+// exportTx reads all of the data of an export from the same snapshot, so
+// that records read by different statements agree with each other.
+var exportTx = fwt.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true, Retry: true}
+
+// writeExport reads the data of the user from a single snapshot, so the
+// archive is consistent, and writes it to the export directory. The archive
+// is only renamed into place once complete.
+func (s *Server) writeExport(ctx context.Context, id string, userID uint) error {
+	data := &fwt.ExportData{}
+	err := s.TxRunner.RunInTxOptions(ctx, exportTx, func(ctx context.Context) error {
+		user, err := s.UserService.FindUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		data.User = user
+
+		profile, err := s.ProfileService.FindProfileByUserID(ctx, userID)
+		if err != nil && fwt.ErrorCode(err) != fwt.ENOTFOUND {
+			return err
+		}
+		data.Profile = profile
+
+		workouts, _, err := s.WorkoutService.FindWorkouts(ctx, fwt.WorkoutFilter{UserID: &userID})
+		if err != nil {
+			return err
+		}
+		data.Workouts = workouts
+
+		for _, w := range workouts {
+			wes, _, err := s.WorkoutExerciseService.FindWorkoutExercises(ctx, fwt.WorkoutExerciseFilter{WorkoutID: &w.ID})
+			if err != nil {
+				return err
+			}
+			data.WorkoutExercises = append(data.WorkoutExercises, wes...)
+		}
+
+		measurements, _, err := s.BodyMeasurementService.FindBodyMeasurements(ctx, fwt.BodyMeasurementFilter{UserID: &userID})
+		if err != nil {
+			return err
+		}
+		data.BodyMeasurements = measurements
+
+		goals, _, err := s.GoalService.FindGoals(ctx, fwt.GoalFilter{UserID: &userID})
+		if err != nil {
+			return err
+		}
+		data.Goals = goals
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read data: %w", err)
+	}
+
+	dir := s.userExportDir(userID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, id+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := fwt.WriteExportArchive(f, data); err != nil {
+		f.Close()
+		return fmt.Errorf("write archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.exportPath(userID, id))
+}
+
+// findExport returns a copy of the export with the given id, or nil.
+func (s *Server) findExport(id string) *fwt.Export {
+	s.exportsMu.Lock()
+	defer s.exportsMu.Unlock()
+
+	export, ok := s.exports[id]
+	if !ok {
+		return nil
+	}
+	other := *export
+	return &other
+}
+
+// SweepExports forgets exports older than the retention period and removes
+// their archives, including those left behind by a previous process. It is
+// meant to be called periodically.
+func (s *Server) SweepExports(ctx context.Context) {
+	now := s.Now()
+
+	s.exportsMu.Lock()
+	for id, e := range s.exports {
+		if e.Status != fwt.ExportStatusPending && now.Sub(e.CreatedAt) > exportRetention {
+			delete(s.exports, id)
+		}
+	}
+	s.exportsMu.Unlock()
+
+	dirs, err := os.ReadDir(s.ExportDir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			s.Logger.WarnContext(ctx, "cannot sweep exports", "error", err)
+		}
+		return
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		dirPath := filepath.Join(s.ExportDir, dir.Name())
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			s.Logger.WarnContext(ctx, "cannot sweep exports", "error", err)
+			continue
+		}
+		for _, entry := range entries {
+			if filepath.Ext(entry.Name()) != ".zip" {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || now.Sub(info.ModTime()) <= exportRetention {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dirPath, entry.Name())); err != nil {
+				s.Logger.WarnContext(ctx, "cannot remove export", "error", err)
+			}
+		}
+		// Only succeeds once the directory is empty.
+		os.Remove(dirPath)
+	}
+}
+
+// RemoveUserExports forgets the exports of a user and removes their
+// archives, so that none are left once the user is deleted. An export still
+// being built removes its own archive when done.
+func (s *Server) RemoveUserExports(userID uint) error {
+	s.exportsMu.Lock()
+	for id, e := range s.exports {
+		if e.UserID == userID {
+			delete(s.exports, id)
+		}
+	}
+	s.exportsMu.Unlock()
+
+	return os.RemoveAll(s.userExportDir(userID))
+}
+
+// userExportDir returns the directory holding the archives of a user.
+func (s *Server) userExportDir(userID uint) string {
+	return filepath.Join(s.ExportDir, strconv.FormatUint(uint64(userID), 10))
+}
+
+func (s *Server) exportPath(userID uint, id string) string {
+	return filepath.Join(s.userExportDir(userID), id+".zip")
+}
+
+// findExportFile returns the path of the archive of export id, which may
+// have been built by a previous process.
+func (s *Server) findExportFile(id string) (string, error) {
+	if export := s.findExport(id); export != nil {
+		return s.exportPath(export.UserID, id), nil
+	}
+	matches, err := filepath.Glob(filepath.Join(s.ExportDir, "*", id+".zip"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fs.ErrNotExist
+	}
+	return matches[0], nil
+}
+
+// signExport returns the signature of a link to export id that expires at
+// the given Unix time.
+func (s *Server) signExport(id string, expires int64) string {
+	mac := hmac.New(sha256.New, s.ExportKey)
+	fmt.Fprintf(mac, "%s\n%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newExportID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
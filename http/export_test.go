@@ -0,0 +1,223 @@
+package http_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/maliByatzes/fwt/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+	ctx := fwt.NewContextWithUser(context.Background(), jane)
+
+	require.NoError(t, s.ProfileService.CreateProfile(ctx, &fwt.Profile{FirstName: "Jane", Height: 170}))
+	now := time.Now()
+	require.NoError(t, s.WorkoutService.LogWorkout(ctx, &fwt.Workout{
+		Name:          "Legs, heavy",
+		ScheduledDate: now.UTC().Truncate(24 * time.Hour),
+		StartedAt:     now.Add(-time.Hour),
+		FinishedAt:    now,
+		Exercises:     []*fwt.Exercise{{Name: "Squat", Sets: []*fwt.Set{{Reps: 5, Load: 100}, {Reps: 5, Load: 105}}}},
+	}))
+	s.MustCreateWorkout(t, jane, "Push-up", "Squat")
+	require.NoError(t, s.BodyMeasurementService.CreateBodyMeasurement(ctx, &fwt.BodyMeasurement{MeasuredAt: now.Add(-time.Hour), Weight: 70, BodyFat: 20}))
+	require.NoError(t, s.GoalService.CreateGoal(ctx, &fwt.Goal{Type: fwt.GoalTypeBodyWeight, Target: 65}))
+
+	tx := &mock.TxRunner{RunInTxOptionsFn: s.DB.RunInTxOptions}
+	s.TxRunner = tx
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Create/ErrNoToken",
+			method: http.MethodPost,
+			path:   "/api/v1/users/export",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Get/ErrNotFound",
+			method: http.MethodGet,
+			path:   "/api/v1/users/export/unknown",
+			token:  janeToken,
+			status: http.StatusNotFound,
+			error:  "Export not found.",
+		},
+		{
+			name:   "Download/ErrUnsigned",
+			method: http.MethodGet,
+			path:   "/api/v1/users/export/unknown/download",
+			status: http.StatusForbidden,
+			error:  "Invalid download link.",
+		},
+	})
+
+	w := doRequest(s.Router, http.MethodPost, "/api/v1/users/export", janeToken, "", nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created struct {
+		Export fwt.Export `json:"export"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Export.ID)
+	require.Equal(t, jane.ID, created.Export.UserID)
+	statusPath := "/api/v1/users/export/" + created.Export.ID
+
+	// The export is built in the background, so poll until it is ready.
+	var ready struct {
+		Export fwt.Export `json:"export"`
+		URL    string     `json:"url"`
+	}
+	require.Eventually(t, func() bool {
+		w := doRequest(s.Router, http.MethodGet, statusPath, janeToken, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ready))
+		require.NotEqual(t, fwt.ExportStatusFailed, ready.Export.Status)
+		return ready.Export.Status == fwt.ExportStatusReady
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// All of the data is read from one snapshot.
+	tx.AssertCalled(t, "RunInTxOptions", fwt.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true, Retry: true})
+
+	i := strings.Index(ready.URL, "/api/v1/users/export/")
+	require.NotEqual(t, -1, i, ready.URL)
+	downloadPath := ready.URL[i:]
+
+	t.Run("Get/ErrOtherUser", func(t *testing.T) {
+		w := doRequest(s.Router, http.MethodGet, statusPath, johnToken, "", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		w := doRequest(s.Router, http.MethodGet, downloadPath, "", "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+		body := w.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+
+		files := make(map[string][]byte)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			rc.Close()
+			files[f.Name] = b
+		}
+		for _, name := range []string{"user", "profile", "workouts", "workout_exercises", "workout_exercise_statuses", "sets", "body_measurements", "goals"} {
+			require.Contains(t, files, name+".json")
+			require.Contains(t, files, name+".csv")
+		}
+
+		// readCSV returns the rows of a CSV file without its header.
+		readCSV := func(t *testing.T, name string) [][]string {
+			rows, err := csv.NewReader(bytes.NewReader(files[name])).ReadAll()
+			require.NoError(t, err)
+			require.NotEmpty(t, rows)
+			return rows[1:]
+		}
+
+		var users []map[string]any
+		require.NoError(t, json.Unmarshal(files["user.json"], &users))
+		require.Len(t, users, 1)
+		require.Equal(t, "janedoe", users[0]["username"])
+		require.NotContains(t, string(files["user.json"]), jane.HashedPassword)
+
+		var workouts []*fwt.Workout
+		require.NoError(t, json.Unmarshal(files["workouts.json"], &workouts))
+		require.Len(t, workouts, 2)
+		require.Len(t, readCSV(t, "workouts.csv"), 2)
+		require.Equal(t, "Legs, heavy", readCSV(t, "workouts.csv")[0][1])
+
+		require.Len(t, readCSV(t, "profile.csv"), 1)
+		require.Len(t, readCSV(t, "workout_exercises.csv"), 3)
+		require.Len(t, readCSV(t, "workout_exercise_statuses.csv"), 3)
+
+		sets := readCSV(t, "sets.csv")
+		require.Len(t, sets, 2)
+		require.Equal(t, []string{"5", "105"}, sets[1][2:4])
+
+		measurements := readCSV(t, "body_measurements.csv")
+		require.Len(t, measurements, 1)
+		require.Equal(t, []string{"70", "20"}, measurements[0][2:4])
+
+		goals := readCSV(t, "goals.csv")
+		require.Len(t, goals, 1)
+		require.Equal(t, []string{fwt.GoalTypeBodyWeight, "", "65", "70"}, goals[0][1:5])
+	})
+
+	t.Run("Download/ErrTampered", func(t *testing.T) {
+		w := doRequest(s.Router, http.MethodGet, strings.Replace(downloadPath, "expires=", "expires=9", 1), "", "", nil)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Download/ErrExpired", func(t *testing.T) {
+		s.Now = func() time.Time { return time.Now().Add(time.Hour) }
+		t.Cleanup(func() { s.Now = time.Now })
+
+		w := doRequest(s.Router, http.MethodGet, downloadPath, "", "", nil)
+		require.Equal(t, http.StatusGone, w.Code)
+	})
+
+	t.Run("Sweep", func(t *testing.T) {
+		t.Cleanup(func() { s.Now = time.Now })
+
+		s.SweepExports(context.Background())
+		require.Len(t, mustGlobExports(t, s), 1)
+
+		s.Now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+		s.SweepExports(context.Background())
+		require.Empty(t, mustGlobExports(t, s))
+
+		w := doRequest(s.Router, http.MethodGet, statusPath, janeToken, "", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestExportHandlers_DeleteUser(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	_, janeToken := s.MustCreateUser(t, "janedoe")
+
+	w := doRequest(s.Router, http.MethodPost, "/api/v1/users/export", janeToken, "", nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created struct {
+		Export fwt.Export `json:"export"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Eventually(t, func() bool {
+		w := doRequest(s.Router, http.MethodGet, "/api/v1/users/export/"+created.Export.ID, janeToken, "", nil)
+		return strings.Contains(w.Body.String(), fwt.ExportStatusReady)
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, mustGlobExports(t, s), 1)
+
+	// Deleting the account removes its archives straight away rather
+	// than leaving them until they expire.
+	w = doRequest(s.Router, http.MethodDelete, "/api/v1/users/delete", janeToken, `{"password":"password123"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Empty(t, mustGlobExports(t, s))
+}
+
+// mustGlobExports returns the paths of the export archives of s.
+func mustGlobExports(tb testing.TB, s *MemoryServer) []string {
+	tb.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(s.ExportDir, "*", "*.zip"))
+	require.NoError(tb, err)
+	return matches
+}
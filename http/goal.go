@@ -0,0 +1,258 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+func (s *Server) createGoal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Goal struct {
+				Type       string    `json:"type"`
+				Exercise   string    `json:"exercise"`
+				Target     float64   `json:"target"`
+				TargetDate time.Time `json:"target_date"`
+			} `json:"goal"`
+			Unit string `json:"unit"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, req.Unit)
+		if !ok {
+			return
+		}
+
+		goal := fwt.Goal{
+			UserID:     user.ID,
+			Type:       req.Goal.Type,
+			Target:     req.Goal.Target,
+			TargetDate: req.Goal.TargetDate,
+		}
+		if goalInKilograms(&goal) {
+			goal.Target = units.Weight.ToKilograms(goal.Target)
+		}
+
+		if req.Goal.Exercise != "" {
+			exercise, err := s.ExerciseService.FindExerciseByName(c.Request.Context(), req.Goal.Exercise)
+			if err != nil {
+				if fwt.ErrorCode(err) == fwt.ENOTFOUND {
+					c.JSON(http.StatusNotFound, gin.H{
+						"error": fwt.ErrorMessage(err),
+					})
+					return
+				}
+
+				s.Logger.ErrorContext(c.Request.Context(), "error in create goal handler", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
+				return
+			}
+			goal.ExerciseID = exercise.ID
+		}
+
+		if err := s.GoalService.CreateGoal(c.Request.Context(), &goal); err != nil {
+			switch fwt.ErrorCode(err) {
+			case fwt.EINVALID:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTFOUND:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			default:
+				s.Logger.ErrorContext(c.Request.Context(), "error in create goal handler", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
+			}
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"goal":  goalInUnits(&goal, units),
+			"units": units,
+		})
+	}
+}
+
+// getGoals lists the goals of the current user. The optional archived
+// query parameter limits the list to archived or to active goals.
+func (s *Server) getGoals() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
+		filter := fwt.GoalFilter{UserID: &user.ID}
+		if v := c.Query("archived"); v != "" {
+			archived, err := strconv.ParseBool(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid archived query param",
+				})
+				return
+			}
+			filter.Archived = &archived
+		}
+
+		goals, n, err := s.GoalService.FindGoals(c.Request.Context(), filter)
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get goals handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		for i, goal := range goals {
+			goals[i] = goalInUnits(goal, units)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"count": n,
+			"goals": goals,
+			"units": units,
+		})
+	}
+}
+
+// getOneGoal returns a goal of the current user with its progress.
+func (s *Server) getOneGoal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid goal id param",
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
+		goalID := uint(id)
+		goals, _, err := s.GoalService.FindGoals(c.Request.Context(), fwt.GoalFilter{ID: &goalID, UserID: &user.ID})
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get goal handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		} else if len(goals) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Goal not found.",
+			})
+			return
+		}
+
+		progress, err := s.GoalService.FindGoalProgress(c.Request.Context(), goalID)
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in get goal handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"goal":     goalInUnits(goals[0], units),
+			"progress": goalProgressInUnits(progress, goals[0], units),
+			"units":    units,
+		})
+	}
+}
+
+func (s *Server) archiveGoal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid goal id param",
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
+		goal, err := s.GoalService.ArchiveGoal(c.Request.Context(), uint(id))
+		if err != nil {
+			switch fwt.ErrorCode(err) {
+			case fwt.ENOTFOUND:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTAUTHORIZED:
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ECONFLICT:
+				c.JSON(http.StatusConflict, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			default:
+				s.Logger.ErrorContext(c.Request.Context(), "error in archive goal handler", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "goal archived successfully",
+			"goal":    goalInUnits(goal, units),
+			"units":   units,
+		})
+	}
+}
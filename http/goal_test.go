@@ -0,0 +1,164 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+	ctx := fwt.NewContextWithUser(context.Background(), jane)
+
+	goal := &fwt.Goal{Type: fwt.GoalTypeFrequency, Target: 4}
+	require.NoError(t, s.GoalService.CreateGoal(ctx, goal))
+	path := fmt.Sprintf("/api/v1/goals/%d", goal.ID)
+
+	now := time.Now()
+	require.NoError(t, s.WorkoutService.LogWorkout(ctx, &fwt.Workout{
+		Name:          "Legs",
+		ScheduledDate: now.UTC().Truncate(24 * time.Hour),
+		StartedAt:     now.Add(-time.Hour),
+		FinishedAt:    now,
+		Exercises:     []*fwt.Exercise{{Name: "Squat", Sets: []*fwt.Set{{Reps: 5, Load: 100}}}},
+	}))
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Create/ErrNoToken",
+			method: http.MethodPost,
+			path:   "/api/v1/goals",
+			body:   `{"goal":{"type":"frequency","target":3}}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Create/ErrInvalid",
+			method: http.MethodPost,
+			path:   "/api/v1/goals",
+			token:  janeToken,
+			body:   `{"goal":{"type":"lift","target":140}}`,
+			status: http.StatusBadRequest,
+			error:  "Exercise is required for a lift goal.",
+		},
+		{
+			name:   "Create/ErrUnknownExercise",
+			method: http.MethodPost,
+			path:   "/api/v1/goals",
+			token:  janeToken,
+			body:   `{"goal":{"type":"lift","exercise":"Moon Press","target":140}}`,
+			status: http.StatusNotFound,
+			error:  "Exercise not found.",
+		},
+		{
+			name:   "Create/Pounds",
+			method: http.MethodPost,
+			path:   "/api/v1/goals",
+			token:  janeToken,
+			body:   `{"goal":{"type":"lift","exercise":"Squat","target":315},"unit":"lb"}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				g := body["goal"].(map[string]any)
+				require.Equal(t, float64(315), g["target"])
+				require.Equal(t, "lb", body["units"].(map[string]any)["weight"])
+
+				stored, err := s.GoalService.FindGoalByID(ctx, uint(g["id"].(float64)))
+				require.NoError(t, err)
+				require.InDelta(t, 142.88, stored.Target, 0.01)
+				require.InDelta(t, fwt.EstimateOneRepMax(5, 100), stored.Start, 1e-9)
+			},
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			path:   "/api/v1/goals",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(2), body["count"])
+			},
+		},
+		{
+			name:   "List/OtherUser",
+			method: http.MethodGet,
+			path:   "/api/v1/goals",
+			token:  johnToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(0), body["count"])
+			},
+		},
+		{
+			name:   "List/ErrInvalidArchived",
+			method: http.MethodGet,
+			path:   "/api/v1/goals?archived=maybe",
+			token:  janeToken,
+			status: http.StatusBadRequest,
+			error:  "Invalid archived query param",
+		},
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			path:   path,
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				progress := body["progress"].(map[string]any)
+				require.Equal(t, float64(1), progress["current"])
+				require.Equal(t, float64(25), progress["percent"])
+				require.Equal(t, false, progress["achieved"])
+			},
+		},
+		{
+			name:   "Get/ErrOtherUser",
+			method: http.MethodGet,
+			path:   path,
+			token:  johnToken,
+			status: http.StatusNotFound,
+			error:  "Goal not found.",
+		},
+		{
+			name:   "Archive/ErrUnauthorized",
+			method: http.MethodPost,
+			path:   path + "/archive",
+			token:  johnToken,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to archive this goal.",
+		},
+		{
+			name:   "Archive",
+			method: http.MethodPost,
+			path:   path + "/archive",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.NotEqual(t, "0001-01-01T00:00:00Z", body["goal"].(map[string]any)["archived_at"])
+			},
+		},
+		{
+			name:   "Archive/ErrConflict",
+			method: http.MethodPost,
+			path:   path + "/archive",
+			token:  janeToken,
+			status: http.StatusConflict,
+			error:  "Goal is already archived.",
+		},
+		{
+			name:   "List/Active",
+			method: http.MethodGet,
+			path:   "/api/v1/goals?archived=false",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(1), body["count"])
+				require.Equal(t, fwt.GoalTypeLift, body["goals"].([]any)[0].(map[string]any)["type"])
+			},
+		},
+	})
+}
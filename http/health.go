@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+const DefaultReadinessTimeout = 2 * time.Second
+
+// HealthChecker reports on the dependencies the server needs to serve
+// traffic. It is implemented by every storage backend.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// livez reports that the process is up. It never checks dependencies so
+// that a database outage does not cause the server to be restarted.
+func (s *Server) livez() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status": "ok",
+		})
+	}
+}
+
+// readyz reports whether the server should receive traffic: the database
+// must answer within ReadinessTimeout, the schema must not be dirty, and the
+// server must not be shutting down.
+func (s *Server) readyz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "shutting down",
+			})
+			return
+		}
+
+		if s.Health == nil {
+			c.JSON(http.StatusOK, gin.H{
+				"status": "ready",
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), s.ReadinessTimeout)
+		defer cancel()
+
+		checks := gin.H{"database": "ok"}
+		body := gin.H{"status": "ready", "checks": checks}
+		status := http.StatusOK
+
+		if err := s.Health.Ping(ctx); err != nil {
+			s.Logger.ErrorContext(ctx, "readiness check: database ping failed", "error", err)
+			checks["database"] = "unavailable"
+			status = http.StatusServiceUnavailable
+		} else if version, dirty, err := s.Health.SchemaVersion(ctx); err != nil {
+			s.Logger.ErrorContext(ctx, "readiness check: cannot read schema version", "error", err)
+			checks["migrations"] = "unavailable"
+			status = http.StatusServiceUnavailable
+		} else {
+			body["schema_version"] = version
+			checks["migrations"] = "ok"
+			if dirty {
+				checks["migrations"] = "dirty"
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		if status != http.StatusOK {
+			body["status"] = "not ready"
+		}
+		c.JSON(status, body)
+	}
+}
+
+func (s *Server) version() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, fwt.ReadBuildInfo())
+	}
+}
@@ -0,0 +1,170 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/stretchr/testify/require"
+)
+
+type HealthChecker struct {
+	PingFn          func(ctx context.Context) error
+	SchemaVersionFn func(ctx context.Context) (uint, bool, error)
+}
+
+func (h *HealthChecker) Ping(ctx context.Context) error {
+	return h.PingFn(ctx)
+}
+
+func (h *HealthChecker) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return h.SchemaVersionFn(ctx)
+}
+
+func TestLivez(t *testing.T) {
+	s := MustNewServer(t, fwthttp.Options{})
+
+	for _, path := range []string{"/livez", "/api/v1/healthchecker"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		s.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	readyz := func(s *fwthttp.Server) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+		s.Router.ServeHTTP(w, req)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+		s.Health = &HealthChecker{
+			PingFn:          func(ctx context.Context) error { return nil },
+			SchemaVersionFn: func(ctx context.Context) (uint, bool, error) { return 6, false, nil },
+		}
+
+		code, body := readyz(s)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "ready", body["status"])
+		require.Equal(t, float64(6), body["schema_version"])
+	})
+
+	t.Run("ErrDatabaseDown", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{
+			Health: &HealthChecker{
+				PingFn: func(ctx context.Context) error { return errors.New("connection refused") },
+			},
+		})
+
+		code, body := readyz(s)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Equal(t, "not ready", body["status"])
+		require.Equal(t, "unavailable", body["checks"].(map[string]any)["database"])
+	})
+
+	t.Run("ErrTimeout", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+		s.ReadinessTimeout = 0
+		s.Health = &HealthChecker{
+			PingFn: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+
+		code, _ := readyz(s)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+	})
+
+	t.Run("ErrDirtySchema", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+		s.Health = &HealthChecker{
+			PingFn:          func(ctx context.Context) error { return nil },
+			SchemaVersionFn: func(ctx context.Context) (uint, bool, error) { return 6, true, nil },
+		}
+
+		code, body := readyz(s)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Equal(t, "dirty", body["checks"].(map[string]any)["migrations"])
+	})
+
+	t.Run("ErrSchemaVersion", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+		s.Health = &HealthChecker{
+			PingFn:          func(ctx context.Context) error { return nil },
+			SchemaVersionFn: func(ctx context.Context) (uint, bool, error) { return 0, false, errors.New("boom") },
+		}
+
+		code, _ := readyz(s)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+	})
+
+	t.Run("ShuttingDown", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{Config: fwthttp.Config{ShutdownDelay: time.Minute}})
+		s.Health = &HealthChecker{
+			PingFn:          func(ctx context.Context) error { return nil },
+			SchemaVersionFn: func(ctx context.Context) (uint, bool, error) { return 6, false, nil },
+		}
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go s.Server.Serve(ln)
+
+		s.ShutdownDelay = 500 * time.Millisecond
+		closed := make(chan error, 1)
+		go func() { closed <- s.Close() }()
+
+		// The server keeps answering during the drain delay, so probes
+		// see that it is no longer ready.
+		require.Eventually(t, func() bool {
+			resp, err := http.Get("http://" + ln.Addr().String() + "/readyz")
+			if err != nil {
+				return false
+			}
+			defer resp.Body.Close()
+			return resp.StatusCode == http.StatusServiceUnavailable
+		}, time.Second, 10*time.Millisecond)
+
+		code, body := readyz(s)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Equal(t, "shutting down", body["status"])
+
+		require.NoError(t, <-closed)
+	})
+
+	t.Run("NoHealthChecker", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+
+		code, body := readyz(s)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "ready", body["status"])
+	})
+}
+
+func TestVersion(t *testing.T) {
+	s := MustNewServer(t, fwthttp.Options{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/version", nil)
+	s.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var info fwt.BuildInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	require.NotEmpty(t, info.Version)
+	require.NotEmpty(t, info.GoVersion)
+}
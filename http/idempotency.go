@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+	// DefaultIdempotencyLockTTL bounds how long a key stays in progress, so
+	// that a request that never completes, such as one whose process
+	// crashed, does not lock its key until it expires. It is well above
+	// TimeOut, after which the request has been abandoned anyway.
+	DefaultIdempotencyLockTTL = time.Minute
+
+	maxIdempotencyKeyLength = 255
+	jsonContentType         = "application/json; charset=utf-8"
+)
+
+// idempotency replays the stored response when a mutating request is retried
+// with the same Idempotency-Key. It must run after authenticate() since keys
+// are scoped to the current user.
+func (s *Server) idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "Idempotency-Key must be at most 255 characters",
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		user := fwt.UserFromContext(ctx)
+		if user == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "Unable to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := requestFingerprint(c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery, body)
+
+		existing, err := s.IdempotencyService.FindIdempotencyKey(ctx, user.ID, key)
+		if err != nil && fwt.ErrorCode(err) != fwt.ENOTFOUND {
+			s.Logger.ErrorContext(c.Request.Context(), "error in idempotency middleware", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		if existing != nil {
+			if existing.RequestHash != hash {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error": "Idempotency-Key has already been used with a different request",
+				})
+				return
+			}
+
+			if !existing.Completed() {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error": "A request with this Idempotency-Key is still being processed",
+				})
+				return
+			}
+
+			for name, values := range existing.ResponseHeaders {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(existing.ResponseStatus, jsonContentType, existing.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		record := &fwt.IdempotencyKey{
+			UserID:      user.ID,
+			Key:         key,
+			RequestHash: hash,
+			ExpiresAt:   s.Now().Add(s.IdempotencyLockTTL),
+		}
+		if err := s.IdempotencyService.CreateIdempotencyKey(ctx, record); err != nil {
+			if fwt.ErrorCode(err) == fwt.ECONFLICT {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error": "A request with this Idempotency-Key is still being processed",
+				})
+				return
+			}
+			s.Logger.ErrorContext(c.Request.Context(), "error in idempotency middleware", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		before := c.Writer.Header().Clone()
+		w := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		// The outcome must be recorded even when the client has gone away,
+		// or the key stays in progress and every retry is refused.
+		ctx = context.WithoutCancel(ctx)
+
+		// Server errors are not stored so that the client can retry them.
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			if err := s.IdempotencyService.DeleteIdempotencyKey(ctx, record.ID); err != nil {
+				s.Logger.ErrorContext(ctx, "error in idempotency middleware", "error", err)
+			}
+			return
+		}
+
+		expiresAt := s.Now().Add(s.IdempotencyKeyTTL)
+		if _, err := s.IdempotencyService.UpdateIdempotencyKey(ctx, record.ID, fwt.IdempotencyKeyUpdate{
+			ResponseStatus:  &status,
+			ResponseBody:    w.body.Bytes(),
+			ResponseHeaders: handlerHeaders(before, w.Header()),
+			ExpiresAt:       &expiresAt,
+		}); err != nil {
+			s.Logger.ErrorContext(ctx, "error in idempotency middleware", "error", err)
+		}
+	}
+}
+
+// handlerHeaders returns the headers set after the before snapshot was taken,
+// leaving out those set by earlier middleware, such as the request ID, which
+// are set afresh on a replay.
+func handlerHeaders(before, after http.Header) map[string][]string {
+	headers := make(map[string][]string)
+	for name, values := range after {
+		if name == "Content-Type" || name == "Content-Length" {
+			continue
+		}
+		if slices.Equal(before[name], values) {
+			continue
+		}
+		headers[name] = values
+	}
+	return headers
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint hashes what decides the meaning of a request. The query
+// is included as it can, like ?unit=, change how the body is read.
+func requestFingerprint(method, path, query string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write([]byte(query))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder copies everything written to the response so it can be stored
+// alongside the idempotency key.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,235 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	t.Run("ReplaysResponse", func(t *testing.T) {
+		s, token := newIdempotencyServer(t)
+
+		calls := 0
+		s.UserService.(*mock.UserService).UpdateUserFn = func(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+			calls++
+			return &fwt.User{ID: id, Username: *upd.Username}, nil
+		}
+
+		body := `{"user":{"username":"janedoe"}}`
+		w1 := doIdempotentRequest(s, token, "key-1", body)
+		w2 := doIdempotentRequest(s, token, "key-1", body)
+
+		require.Equal(t, http.StatusOK, w1.Code)
+		require.Equal(t, http.StatusOK, w2.Code)
+		require.Equal(t, w1.Body.String(), w2.Body.String())
+		require.Equal(t, "true", w2.Header().Get(fwthttp.IdempotentReplayedHeader))
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("ErrDifferentPayload", func(t *testing.T) {
+		s, token := newIdempotencyServer(t)
+
+		calls := 0
+		s.UserService.(*mock.UserService).UpdateUserFn = func(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+			calls++
+			return &fwt.User{ID: id, Username: *upd.Username}, nil
+		}
+
+		w1 := doIdempotentRequest(s, token, "key-1", `{"user":{"username":"janedoe"}}`)
+		w2 := doIdempotentRequest(s, token, "key-1", `{"user":{"username":"johndoe"}}`)
+
+		require.Equal(t, http.StatusOK, w1.Code)
+		require.Equal(t, http.StatusConflict, w2.Code)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("ErrDifferentQuery", func(t *testing.T) {
+		s, token := newIdempotencyServer(t)
+
+		calls := 0
+		s.UserService.(*mock.UserService).UpdateUserFn = func(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+			calls++
+			return &fwt.User{ID: id, Username: *upd.Username}, nil
+		}
+
+		body := `{"user":{"username":"janedoe"}}`
+		w1 := doIdempotentRequestTo(s, http.MethodPatch, "/api/v1/users/update?unit=metric", token, "key-1", body)
+		w2 := doIdempotentRequestTo(s, http.MethodPatch, "/api/v1/users/update?unit=imperial", token, "key-1", body)
+
+		require.Equal(t, http.StatusOK, w1.Code)
+		require.Equal(t, http.StatusConflict, w2.Code)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("ServerErrorNotStored", func(t *testing.T) {
+		s, token := newIdempotencyServer(t)
+
+		calls := 0
+		s.UserService.(*mock.UserService).UpdateUserFn = func(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+			calls++
+			if calls == 1 {
+				return nil, context.DeadlineExceeded
+			}
+			return &fwt.User{ID: id, Username: *upd.Username}, nil
+		}
+
+		body := `{"user":{"username":"janedoe"}}`
+		w1 := doIdempotentRequest(s, token, "key-1", body)
+		w2 := doIdempotentRequest(s, token, "key-1", body)
+
+		require.Equal(t, http.StatusInternalServerError, w1.Code)
+		require.Equal(t, http.StatusOK, w2.Code)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("ReplaysHeaders", func(t *testing.T) {
+		s, token := newIdempotencyServer(t)
+
+		profile := &fwt.Profile{ID: 1, UserID: 1, FirstName: "Jane", Units: fwt.MetricUnits, Version: 1}
+		s.ProfileService = &mock.ProfileService{
+			FindProfileByUserIDFn: func(ctx context.Context, userID uint) (*fwt.Profile, error) {
+				return profile, nil
+			},
+			UpdateProfileFn: func(ctx context.Context, id uint, upd fwt.ProfileUpdate) (*fwt.Profile, error) {
+				other := *profile
+				other.FirstName, other.Version = *upd.FirstName, profile.Version+1
+				return &other, nil
+			},
+		}
+
+		body := `{"profile":{"first_name":"Janet"}}`
+		w1 := doIdempotentRequestTo(s, http.MethodPatch, "/api/v1/profile/update", token, "key-1", body)
+		w2 := doIdempotentRequestTo(s, http.MethodPatch, "/api/v1/profile/update", token, "key-1", body)
+
+		require.Equal(t, http.StatusOK, w1.Code, w1.Body.String())
+		require.Equal(t, `"2-kg-cm-km"`, w1.Header().Get("ETag"))
+		require.Equal(t, "true", w2.Header().Get(fwthttp.IdempotentReplayedHeader))
+		require.Equal(t, w1.Header().Get("ETag"), w2.Header().Get("ETag"))
+		require.Equal(t, w1.Header().Get("Content-Type"), w2.Header().Get("Content-Type"))
+		require.NotEqual(t, w1.Header().Get(fwthttp.RequestIDHeader), w2.Header().Get(fwthttp.RequestIDHeader))
+	})
+
+	t.Run("CompletesAfterClientDisconnects", func(t *testing.T) {
+		s, token := newIdempotencyServer(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		s.UserService.(*mock.UserService).UpdateUserFn = func(_ context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+			calls++
+			cancel()
+			return &fwt.User{ID: id, Username: *upd.Username}, nil
+		}
+
+		body := `{"user":{"username":"janedoe"}}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPatch, "/api/v1/users/update", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(fwthttp.IdempotencyKeyHeader, "key-1")
+		s.Router.ServeHTTP(w, req)
+
+		w2 := doIdempotentRequest(s, token, "key-1", body)
+		require.Equal(t, http.StatusOK, w2.Code, w2.Body.String())
+		require.Equal(t, "true", w2.Header().Get(fwthttp.IdempotentReplayedHeader))
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("InProgressKeyExpiresSoon", func(t *testing.T) {
+		s, token := newIdempotencyServer(t)
+		now := time.Now()
+		s.Now = func() time.Time { return now }
+
+		var created *fwt.IdempotencyKey
+		create := s.IdempotencyService.(*mock.IdempotencyService).CreateIdempotencyKeyFn
+		s.IdempotencyService.(*mock.IdempotencyService).CreateIdempotencyKeyFn = func(ctx context.Context, key *fwt.IdempotencyKey) error {
+			created = key
+			require.Equal(t, now.Add(s.IdempotencyLockTTL), key.ExpiresAt)
+			return create(ctx, key)
+		}
+		s.UserService.(*mock.UserService).UpdateUserFn = func(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+			return &fwt.User{ID: id, Username: *upd.Username}, nil
+		}
+
+		w := doIdempotentRequest(s, token, "key-1", `{"user":{"username":"janedoe"}}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, now.Add(s.IdempotencyKeyTTL), created.ExpiresAt)
+	})
+}
+
+func newIdempotencyServer(tb testing.TB) (*fwthttp.Server, string) {
+	tb.Helper()
+
+	s := MustNewServer(tb, fwthttp.Options{})
+
+	user := &fwt.User{ID: 1, Username: "janedoe", Email: "jane@email.com"}
+	s.UserService = &mock.UserService{
+		FindUserbyIDFn: func(ctx context.Context, id uint) (*fwt.User, error) {
+			return user, nil
+		},
+	}
+
+	keys := make(map[string]*fwt.IdempotencyKey)
+	s.IdempotencyService = &mock.IdempotencyService{
+		FindIdempotencyKeyFn: func(ctx context.Context, userID uint, key string) (*fwt.IdempotencyKey, error) {
+			if k, ok := keys[key]; ok && k.UserID == userID {
+				return k, nil
+			}
+			return nil, fwt.Errorf(fwt.ENOTFOUND, "Idempotency key not found.")
+		},
+		CreateIdempotencyKeyFn: func(ctx context.Context, key *fwt.IdempotencyKey) error {
+			key.ID = uint(len(keys) + 1)
+			keys[key.Key] = key
+			return nil
+		},
+		UpdateIdempotencyKeyFn: func(ctx context.Context, id uint, upd fwt.IdempotencyKeyUpdate) (*fwt.IdempotencyKey, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for _, k := range keys {
+				if k.ID == id {
+					k.ResponseStatus, k.ResponseBody, k.ResponseHeaders = *upd.ResponseStatus, upd.ResponseBody, upd.ResponseHeaders
+					k.ExpiresAt = *upd.ExpiresAt
+					return k, nil
+				}
+			}
+			return nil, fwt.Errorf(fwt.ENOTFOUND, "Idempotency key not found.")
+		},
+		DeleteIdempotencyKeyFn: func(ctx context.Context, id uint) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for name, k := range keys {
+				if k.ID == id {
+					delete(keys, name)
+				}
+			}
+			return nil
+		},
+	}
+
+	token, _, err := s.TokenMaker.CreateToken(user.ID, user.Username, time.Minute)
+	require.NoError(tb, err)
+
+	return s, token
+}
+
+func doIdempotentRequest(s *fwthttp.Server, token, key, body string) *httptest.ResponseRecorder {
+	return doIdempotentRequestTo(s, http.MethodPatch, "/api/v1/users/update", token, key, body)
+}
+
+func doIdempotentRequestTo(s *fwthttp.Server, method, path, token, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(fwthttp.IdempotencyKeyHeader, key)
+	s.Router.ServeHTTP(w, req)
+	return w
+}
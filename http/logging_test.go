@@ -0,0 +1,103 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Run("Generated", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/logout", nil)
+		s.Router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, w.Header().Get(fwthttp.RequestIDHeader), 32)
+	})
+
+	t.Run("Accepted", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/logout", nil)
+		req.Header.Set(fwthttp.RequestIDHeader, "abc-123")
+		s.Router.ServeHTTP(w, req)
+
+		require.Equal(t, "abc-123", w.Header().Get(fwthttp.RequestIDHeader))
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	s := MustNewServer(t, fwthttp.Options{
+		Logger: slog.New(fwt.NewLogHandler(slog.NewJSONHandler(&buf, nil))),
+	})
+
+	user := &fwt.User{ID: 7, Username: "janedoe"}
+	s.UserService = &mock.UserService{
+		FindUserbyIDFn: func(ctx context.Context, id uint) (*fwt.User, error) {
+			return user, nil
+		},
+	}
+	token, _, err := s.TokenMaker.CreateToken(user.ID, user.Username, time.Minute)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(fwthttp.RequestIDHeader, "abc-123")
+	s.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var line struct {
+		Msg       string  `json:"msg"`
+		Method    string  `json:"method"`
+		Route     string  `json:"route"`
+		Status    int     `json:"status"`
+		LatencyMS float64 `json:"latency_ms"`
+		RequestID string  `json:"request_id"`
+		UserID    uint    `json:"user_id"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "http request", line.Msg)
+	require.Equal(t, http.MethodGet, line.Method)
+	require.Equal(t, "/api/v1/users/me", line.Route)
+	require.Equal(t, http.StatusOK, line.Status)
+	require.Equal(t, "abc-123", line.RequestID)
+	require.Equal(t, uint(7), line.UserID)
+
+	// The handler is wrapped once, so the IDs are not repeated.
+	require.Equal(t, 1, bytes.Count(buf.Bytes(), []byte(`"request_id"`)))
+	require.Equal(t, 1, bytes.Count(buf.Bytes(), []byte(`"user_id"`)))
+}
+
+func TestAccessLog_DefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(fwt.NewLogHandler(slog.NewJSONHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	s := MustNewServer(t, fwthttp.Options{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/logout", nil)
+	req.Header.Set(fwthttp.RequestIDHeader, "abc-123")
+	s.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The default handler is already wrapped, so the ID is added once.
+	require.Equal(t, 1, bytes.Count(buf.Bytes(), []byte(`"request_id"`)))
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt/metrics"
+)
+
+// instrument records request counts, latencies and in-flight requests. The
+// route label is the gin route template so that IDs do not explode the
+// label cardinality.
+func (s *Server) instrument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RunMetrics serves the Prometheus endpoint on its own listener so it can be
+// kept off the public API port.
+func (s *Server) RunMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	s.MetricsServer = &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  TimeOut,
+		WriteTimeout: TimeOut,
+		IdleTimeout:  TimeOut,
+	}
+	s.Logger.Info("📈 Metrics server starting", "addr", addr)
+	return s.MetricsServer.ListenAndServe()
+}
+
+func (s *Server) closeMetrics(ctx context.Context) error {
+	if s.MetricsServer == nil {
+		return nil
+	}
+	return s.MetricsServer.Shutdown(ctx)
+}
@@ -0,0 +1,31 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	s := MustNewServer(t, fwthttp.Options{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/logout", nil)
+	s.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+	metrics.Handler().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	require.Contains(t, body, `fwt_http_requests_total{method="POST",route="/api/v1/users/logout",status="200"}`)
+	require.Contains(t, body, `fwt_http_request_duration_seconds_bucket{method="POST",route="/api/v1/users/logout",status="200"`)
+	require.Contains(t, body, `fwt_http_requests_in_flight 0`)
+	require.Contains(t, body, `fwt_workouts_created_total`)
+}
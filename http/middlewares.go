@@ -52,6 +52,14 @@ func (s *Server) authenticate() gin.HandlerFunc {
 			return
 		}
 
+		if user.Deleted() {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized - account is scheduled for deletion",
+			})
+			c.Abort()
+			return
+		}
+
 		ctx := fwt.NewContextWithUser(c.Request.Context(), user)
 		c.Request = c.Request.WithContext(ctx)
 
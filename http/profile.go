@@ -1,7 +1,6 @@
 package http
 
 import (
-	"log"
 	"net/http"
 	"time"
 
@@ -10,18 +9,21 @@ import (
 )
 
 func (s *Server) createProfile() gin.HandlerFunc {
-	var req struct {
-		Profile struct {
-			FirstName   string    `json:"first_name"`
-			LastName    string    `json:"last_name"`
-			DateOfBirth time.Time `json:"dob"`
-			Gender      string    `json:"gender"`
-			Height      float64   `json:"height"`
-			Weight      float64   `json:"weight"`
-		} `json:"profile"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			Profile struct {
+				FirstName   string    `json:"first_name"`
+				LastName    string    `json:"last_name"`
+				DateOfBirth time.Time `json:"dob"`
+				Gender      string    `json:"gender"`
+				Height      float64   `json:"height"`
+				Weight      float64   `json:"weight"`
+				Units       fwt.Units `json:"units"`
+				Timezone    string    `json:"timezone"`
+			} `json:"profile"`
+			Unit string `json:"unit"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -37,14 +39,21 @@ func (s *Server) createProfile() gin.HandlerFunc {
 			return
 		}
 
+		units, ok := resolveUnits(c, fwt.MetricUnits.Merge(req.Profile.Units), req.Unit)
+		if !ok {
+			return
+		}
+
 		newProfile := fwt.Profile{
 			UserID:      user.ID,
 			FirstName:   req.Profile.FirstName,
 			LastName:    req.Profile.LastName,
 			DateOfBirth: req.Profile.DateOfBirth,
 			Gender:      req.Profile.Gender,
-			Height:      req.Profile.Height,
-			Weight:      req.Profile.Weight,
+			Height:      units.Length.ToCentimetres(req.Profile.Height),
+			Weight:      units.Weight.ToKilograms(req.Profile.Weight),
+			Units:       req.Profile.Units,
+			Timezone:    req.Profile.Timezone,
 		}
 
 		if err := s.ProfileService.CreateProfile(c.Request.Context(), &newProfile); err != nil {
@@ -62,7 +71,7 @@ func (s *Server) createProfile() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in create profile handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in create profile handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -70,7 +79,8 @@ func (s *Server) createProfile() gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
-			"profile": newProfile,
+			"profile": profileInUnits(&newProfile, units),
+			"units":   units,
 		})
 	}
 }
@@ -93,32 +103,47 @@ func (s *Server) getUserProfile() gin.HandlerFunc {
 				})
 				return
 			}
-			log.Printf("error in get user profile handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in get user profile handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
+		units, ok := resolveUnits(c, profile.Units, "")
+		if !ok {
+			return
+		}
+
+		c.Header("ETag", etag(profile.Version, units))
+		if v := c.GetHeader("If-None-Match"); v != "" && etagMatches(v, profile.Version, units) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"profile": profile,
+			"profile": profileInUnits(profile, units),
+			"units":   units,
 		})
 	}
 }
 
 func (s *Server) updateProfile() gin.HandlerFunc {
-	var req struct {
-		Profile struct {
-			FirstName   string    `json:"first_name"`
-			LastName    string    `json:"last_name"`
-			DateOfBirth time.Time `json:"dob"`
-			Gender      string    `json:"gender"`
-			Height      float64   `json:"height"`
-			Weight      float64   `json:"weight"`
-		} `json:"profile"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			Profile struct {
+				FirstName   string    `json:"first_name"`
+				LastName    string    `json:"last_name"`
+				DateOfBirth time.Time `json:"dob"`
+				Gender      string    `json:"gender"`
+				Height      float64   `json:"height"`
+				Weight      float64   `json:"weight"`
+				Units       fwt.Units `json:"units"`
+				Timezone    string    `json:"timezone"`
+			} `json:"profile"`
+			Unit string `json:"unit"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -147,11 +172,11 @@ func (s *Server) updateProfile() gin.HandlerFunc {
 		if req.Profile.Gender != "" {
 			upd.Gender = &req.Profile.Gender
 		}
-		if req.Profile.Height != 0 {
-			upd.Height = &req.Profile.Height
+		if req.Profile.Units != (fwt.Units{}) {
+			upd.Units = &req.Profile.Units
 		}
-		if req.Profile.Weight != 0 {
-			upd.Weight = &req.Profile.Weight
+		if req.Profile.Timezone != "" {
+			upd.Timezone = &req.Profile.Timezone
 		}
 
 		profile, err := s.ProfileService.FindProfileByUserID(c.Request.Context(), user.ID)
@@ -162,31 +187,70 @@ func (s *Server) updateProfile() gin.HandlerFunc {
 				})
 				return
 			}
-			log.Printf("error in update profile handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in update profile handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
+		units, ok := resolveUnits(c, profile.Units.Merge(req.Profile.Units), req.Unit)
+		if !ok {
+			return
+		}
+		if req.Profile.Height != 0 {
+			height := units.Length.ToCentimetres(req.Profile.Height)
+			upd.Height = &height
+		}
+		if req.Profile.Weight != 0 {
+			weight := units.Weight.ToKilograms(req.Profile.Weight)
+			upd.Weight = &weight
+		}
+
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{
+				"error": "Invalid If-Match header",
+			})
+			return
+		}
+
+		upd.Version = version
+
 		updatedProfile, err := s.ProfileService.UpdateProfile(c.Request.Context(), profile.ID, upd)
 		if err != nil {
+			if fwt.ErrorCode(err) == fwt.EINVALID {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
+			if fwt.ErrorCode(err) == fwt.ESTALE {
+				c.JSON(http.StatusPreconditionFailed, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
 			if fwt.ErrorCode(err) == fwt.ENOTAUTHORIZED {
 				c.JSON(http.StatusUnauthorized, gin.H{
 					"error": fwt.ErrorMessage(err),
 				})
 				return
 			}
-			log.Printf("error in update profile handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in update profile handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
+		c.Header("ETag", etag(updatedProfile.Version, units))
 		c.JSON(http.StatusOK, gin.H{
 			"message": "profile updated successfully",
-			"profile": updatedProfile,
+			"profile": profileInUnits(updatedProfile, units),
+			"units":   units,
 		})
 	}
 }
@@ -209,15 +273,30 @@ func (s *Server) deleteProfile() gin.HandlerFunc {
 				})
 				return
 			}
-			log.Printf("error in update profile handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in delete profile handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
-		err = s.ProfileService.DeleteProfile(c.Request.Context(), profile.ID)
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{
+				"error": "Invalid If-Match header",
+			})
+			return
+		}
+
+		err = s.ProfileService.DeleteProfile(c.Request.Context(), profile.ID, version)
 		if err != nil {
+			if fwt.ErrorCode(err) == fwt.ESTALE {
+				c.JSON(http.StatusPreconditionFailed, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
 			if fwt.ErrorCode(err) == fwt.ENOTAUTHORIZED {
 				c.JSON(http.StatusUnauthorized, gin.H{
 					"error": fwt.ErrorMessage(err),
@@ -225,7 +304,7 @@ func (s *Server) deleteProfile() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in update profile handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in delete profile handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -0,0 +1,176 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	_, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Get/ErrNoToken",
+			method: http.MethodGet,
+			path:   "/api/v1/profile",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Get/ErrNotFound",
+			method: http.MethodGet,
+			path:   "/api/v1/profile",
+			token:  janeToken,
+			status: http.StatusNotFound,
+			error:  "Profile not found.",
+		},
+		{
+			name:   "Create/ErrInvalidDate",
+			method: http.MethodPost,
+			path:   "/api/v1/profile/create",
+			token:  janeToken,
+			body:   `{"profile":{"first_name":"Jane","dob":"yesterday"}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Create/ErrInvalidTimezone",
+			method: http.MethodPost,
+			path:   "/api/v1/profile/create",
+			token:  janeToken,
+			body:   `{"profile":{"first_name":"Jane","dob":"1990-01-02T00:00:00Z","timezone":"Mars/Olympus_Mons"}}`,
+			status: http.StatusBadRequest,
+			error:  "Timezone is invalid.",
+		},
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			path:   "/api/v1/profile/create",
+			token:  janeToken,
+			body:   `{"profile":{"first_name":"Jane","dob":"1990-01-02T00:00:00Z","height":170}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				profile := body["profile"].(map[string]any)
+				require.Equal(t, "Jane", profile["first_name"])
+				require.Equal(t, float64(170), profile["height"])
+				require.Equal(t, float64(1), profile["version"])
+				require.Equal(t, "UTC", profile["timezone"])
+			},
+		},
+		{
+			name:   "Create/ErrExists",
+			method: http.MethodPost,
+			path:   "/api/v1/profile/create",
+			token:  janeToken,
+			body:   `{"profile":{"first_name":"Jane"}}`,
+			status: http.StatusConflict,
+			error:  "Profile already exists.",
+		},
+		{
+			name:       "Get",
+			method:     http.MethodGet,
+			path:       "/api/v1/profile",
+			token:      janeToken,
+			status:     http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"1-kg-cm-km"`},
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, "Jane", body["profile"].(map[string]any)["first_name"])
+			},
+		},
+		{
+			name:   "Get/NotModified",
+			method: http.MethodGet,
+			path:   "/api/v1/profile",
+			token:  janeToken,
+			header: map[string]string{"If-None-Match": `"1-kg-cm-km"`},
+			status: http.StatusNotModified,
+		},
+		{
+			name:       "Get/OtherUnits",
+			method:     http.MethodGet,
+			path:       "/api/v1/profile?unit=imperial",
+			token:      janeToken,
+			header:     map[string]string{"If-None-Match": `"1-kg-cm-km"`},
+			status:     http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"1-lb-in-mi"`},
+		},
+		{
+			name:   "Update/ErrInvalidIfMatch",
+			method: http.MethodPatch,
+			path:   "/api/v1/profile/update",
+			token:  janeToken,
+			header: map[string]string{"If-Match": "abc"},
+			body:   `{"profile":{"last_name":"Doe"}}`,
+			status: http.StatusPreconditionFailed,
+			error:  "Invalid If-Match header",
+		},
+		{
+			name:   "Update/ErrStale",
+			method: http.MethodPatch,
+			path:   "/api/v1/profile/update",
+			token:  janeToken,
+			header: map[string]string{"If-Match": `"7"`},
+			body:   `{"profile":{"last_name":"Doe"}}`,
+			status: http.StatusPreconditionFailed,
+			error:  "Profile has been modified since it was last fetched.",
+		},
+		{
+			name:       "Update",
+			method:     http.MethodPatch,
+			path:       "/api/v1/profile/update",
+			token:      janeToken,
+			header:     map[string]string{"If-Match": `"1-lb-in-mi"`},
+			body:       `{"profile":{"last_name":"Doe"}}`,
+			status:     http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"2-kg-cm-km"`},
+			check: func(t *testing.T, body map[string]any) {
+				profile := body["profile"].(map[string]any)
+				require.Equal(t, "Jane", profile["first_name"])
+				require.Equal(t, "Doe", profile["last_name"])
+			},
+		},
+		{
+			name:   "Update/ErrNotFound",
+			method: http.MethodPatch,
+			path:   "/api/v1/profile/update",
+			token:  johnToken,
+			body:   `{"profile":{"last_name":"Doe"}}`,
+			status: http.StatusNotFound,
+			error:  "Profile not found.",
+		},
+		{
+			name:   "Delete/ErrStale",
+			method: http.MethodDelete,
+			path:   "/api/v1/profile/delete",
+			token:  janeToken,
+			header: map[string]string{"If-Match": `"1"`},
+			status: http.StatusPreconditionFailed,
+			error:  "Profile has been modified since it was last fetched.",
+		},
+		{
+			name:   "Delete/ErrNotFound",
+			method: http.MethodDelete,
+			path:   "/api/v1/profile/delete",
+			token:  johnToken,
+			status: http.StatusNotFound,
+			error:  "Profile not found.",
+		},
+		{
+			name:   "Delete",
+			method: http.MethodDelete,
+			path:   "/api/v1/profile/delete",
+			token:  janeToken,
+			header: map[string]string{"If-Match": `"2"`},
+			status: http.StatusOK,
+		},
+		{
+			name:   "Get/ErrDeleted",
+			method: http.MethodGet,
+			path:   "/api/v1/profile",
+			token:  janeToken,
+			status: http.StatusNotFound,
+		},
+	})
+}
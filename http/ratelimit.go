@@ -0,0 +1,186 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+const (
+	RateLimitPolicyAuth = "auth"
+	RateLimitPolicyAPI  = "api"
+)
+
+// RateLimitPolicy allows Limit requests per Period, refilled continuously
+// as a token bucket. A policy with a zero Limit is disabled.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// ParseRateLimitPolicy parses a policy in the form "<limit>/<period>",
+// e.g. "10/1m" or "100/1h".
+func ParseRateLimitPolicy(name, s string) (RateLimitPolicy, error) {
+	limitStr, periodStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit policy %q: expected <limit>/<period>", s)
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit policy %q: bad limit", s)
+	}
+
+	period, err := time.ParseDuration(periodStr)
+	if err != nil || period <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit policy %q: bad period", s)
+	}
+
+	return RateLimitPolicy{Name: name, Limit: limit, Period: period}, nil
+}
+
+func DefaultRateLimitPolicies() map[string]RateLimitPolicy {
+	return map[string]RateLimitPolicy{
+		RateLimitPolicyAuth: {Name: RateLimitPolicyAuth, Limit: 10, Period: time.Minute},
+		RateLimitPolicyAPI:  {Name: RateLimitPolicyAPI, Limit: 300, Period: time.Minute},
+	}
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the bucket state for each key. Implementations backed
+// by a shared store allow limits to apply across several server instances.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+
+	Now func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		Now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	s.sweep(now)
+
+	capacity := float64(policy.Limit)
+	rate := capacity / policy.Period.Seconds()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, updated: now, period: policy.Period}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	result := RateLimitResult{Limit: policy.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / rate)
+
+	return result, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// rateLimit applies the named policy. Requests are keyed by user ID when
+// authenticate() has already run and by client IP otherwise.
+func (s *Server) rateLimit(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, ok := s.RateLimitPolicies[name]
+		if !ok || policy.Limit <= 0 || s.RateLimitStore == nil {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.ClientIP()
+		if userID := fwt.UserIDFromContext(c.Request.Context()); userID != 0 {
+			key = "user:" + strconv.FormatUint(uint64(userID), 10)
+		}
+
+		result, err := s.RateLimitStore.Take(c.Request.Context(), name+":"+key, policy)
+		if err != nil {
+			s.Logger.ErrorContext(c.Request.Context(), "error in rate limit middleware", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Period)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too Many Requests",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
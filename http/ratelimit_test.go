@@ -0,0 +1,137 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/mock"
+	"github.com/maliByatzes/fwt/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := fwthttp.NewMemoryRateLimitStore()
+	s.Now = func() time.Time { return now }
+	policy := fwthttp.RateLimitPolicy{Name: "test", Limit: 2, Period: time.Minute}
+
+	r, err := s.Take(context.Background(), "k", policy)
+	require.NoError(t, err)
+	require.True(t, r.Allowed)
+	require.Equal(t, 1, r.Remaining)
+
+	r, err = s.Take(context.Background(), "k", policy)
+	require.NoError(t, err)
+	require.True(t, r.Allowed)
+	require.Equal(t, 0, r.Remaining)
+
+	r, err = s.Take(context.Background(), "k", policy)
+	require.NoError(t, err)
+	require.False(t, r.Allowed)
+	require.Equal(t, 30*time.Second, r.RetryAfter)
+
+	r, err = s.Take(context.Background(), "other", policy)
+	require.NoError(t, err)
+	require.True(t, r.Allowed)
+
+	now = now.Add(30 * time.Second)
+	r, err = s.Take(context.Background(), "k", policy)
+	require.NoError(t, err)
+	require.True(t, r.Allowed)
+}
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	p, err := fwthttp.ParseRateLimitPolicy("auth", "10/1m")
+	require.NoError(t, err)
+	require.Equal(t, fwthttp.RateLimitPolicy{Name: "auth", Limit: 10, Period: time.Minute}, p)
+
+	_, err = fwthttp.ParseRateLimitPolicy("auth", "10")
+	require.Error(t, err)
+
+	_, err = fwthttp.ParseRateLimitPolicy("auth", "x/1m")
+	require.Error(t, err)
+}
+
+func TestRateLimit_Login(t *testing.T) {
+	s := MustNewServer(t, fwthttp.Options{})
+	s.RateLimitPolicies[fwthttp.RateLimitPolicyAuth] = fwthttp.RateLimitPolicy{Name: fwthttp.RateLimitPolicyAuth, Limit: 2, Period: time.Minute}
+	s.UserService = &mock.UserService{
+		AuthenticateFn: func(ctx context.Context, username, password string) (*fwt.User, error) {
+			return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "Incorrect credentials")
+		},
+	}
+
+	login := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/login", strings.NewReader(`{"user":{"username":"janedoe","password":"password123"}}`))
+		s.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := login()
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	w = login()
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = login()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "30", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_ForwardedFor(t *testing.T) {
+	// login sends a failed login from a proxy at 192.0.2.1 on behalf of
+	// the client forwardedFor.
+	login := func(s *fwthttp.Server, forwardedFor string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", strings.NewReader(`{"user":{"username":"janedoe","password":"password123"}}`))
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		s.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	newServer := func(t *testing.T, config fwthttp.Config) *fwthttp.Server {
+		s := MustNewServer(t, fwthttp.Options{Config: config})
+		s.RateLimitPolicies[fwthttp.RateLimitPolicyAuth] = fwthttp.RateLimitPolicy{Name: fwthttp.RateLimitPolicyAuth, Limit: 1, Period: time.Minute}
+		s.UserService = &mock.UserService{
+			AuthenticateFn: func(ctx context.Context, username, password string) (*fwt.User, error) {
+				return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "Incorrect credentials")
+			},
+		}
+		return s
+	}
+
+	t.Run("ErrSpoofed", func(t *testing.T) {
+		s := newServer(t, fwthttp.Config{})
+
+		require.Equal(t, http.StatusUnauthorized, login(s, "203.0.113.1").Code)
+		require.Equal(t, http.StatusTooManyRequests, login(s, "203.0.113.2").Code)
+	})
+
+	t.Run("TrustedProxy", func(t *testing.T) {
+		s := newServer(t, fwthttp.Config{TrustedProxies: []string{"192.0.2.0/24"}})
+
+		require.Equal(t, http.StatusUnauthorized, login(s, "203.0.113.1").Code)
+		require.Equal(t, http.StatusUnauthorized, login(s, "203.0.113.2").Code)
+		require.Equal(t, http.StatusTooManyRequests, login(s, "203.0.113.2").Code)
+	})
+
+	t.Run("ErrInvalidProxy", func(t *testing.T) {
+		tokenMaker, err := token.NewJWTMaker(TestSecretKey)
+		require.NoError(t, err)
+
+		_, err = fwthttp.NewServer(fwthttp.Options{
+			TokenMaker: tokenMaker,
+			Config:     fwthttp.Config{TrustedProxies: []string{"not-an-ip"}},
+		})
+		require.Error(t, err)
+	})
+}
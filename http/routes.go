@@ -1,33 +1,64 @@
 package http
 
+import "github.com/maliByatzes/fwt"
+
 func (s *Server) routes() {
-	s.Router.Use(CORSMiddleware())
+	s.Router.Use(s.requestID(), s.trace(), s.instrument(), s.accessLog(), s.recovery(), CORSMiddleware())
+
+	s.Router.GET("/livez", s.livez())
+	s.Router.GET("/readyz", s.readyz())
+	s.Router.GET("/version", s.version())
 
 	apiRouter := s.Router.Group("/api/v1")
 	{
-		apiRouter.GET("/healthchecker", healthCheck())
-		apiRouter.POST("/users/register", s.createUser())
-		apiRouter.POST("/users/login", s.loginUser())
+		// healthchecker is kept for the probes configured before /livez.
+		apiRouter.GET("/healthchecker", s.livez())
+		apiRouter.POST("/users/register", s.rateLimit(RateLimitPolicyAuth), s.createUser())
+		apiRouter.POST("/users/login", s.rateLimit(RateLimitPolicyAuth), s.loginUser())
 		apiRouter.POST("/users/logout", s.logoutUser())
+		apiRouter.POST("/users/restore", s.rateLimit(RateLimitPolicyAuth), s.restoreUser())
+		apiRouter.GET("/calendar/feed/:token", s.rateLimit(RateLimitPolicyAPI), s.getCalendarFeed())
+		apiRouter.GET("/users/export/:id/download", s.rateLimit(RateLimitPolicyAPI), s.downloadExport())
 
-		apiRouter.Use(s.authenticate())
+		apiRouter.Use(s.authenticate(), s.rateLimit(RateLimitPolicyAPI), s.idempotency())
 		{
 			apiRouter.GET("/users/me", s.getCurrentUser())
 			apiRouter.PATCH("/users/update", s.updateUser())
 			apiRouter.DELETE("/users/delete", s.deleteUser())
+			apiRouter.POST("/users/export", s.createExport())
+			apiRouter.GET("/users/export/:id", s.getExport())
 
 			apiRouter.POST("/profile/create", s.createProfile())
 			apiRouter.GET("/profile", s.getUserProfile())
 			apiRouter.PATCH("/profile/update", s.updateProfile())
 			apiRouter.DELETE("/profile/delete", s.deleteProfile())
 
+			apiRouter.POST("/measurements", s.createBodyMeasurement())
+			apiRouter.GET("/measurements", s.getBodyMeasurements())
+			apiRouter.GET("/measurements/:id", s.getOneBodyMeasurement())
+			apiRouter.PATCH("/measurements/:id", s.updateBodyMeasurement())
+			apiRouter.DELETE("/measurements/:id", s.deleteBodyMeasurement())
+
+			apiRouter.POST("/goals", s.createGoal())
+			apiRouter.GET("/goals", s.getGoals())
+			apiRouter.GET("/goals/:id", s.getOneGoal())
+			apiRouter.POST("/goals/:id/archive", s.archiveGoal())
+
+			apiRouter.GET("/calendar", s.getCalendar())
+			apiRouter.POST("/calendar/feed", s.rotateCalendarFeed())
+
 			apiRouter.POST("/workout/create", s.createWorkout())
+			apiRouter.POST("/workout/log", s.logWorkout())
+			apiRouter.POST("/workout/quick-start", s.quickStartWorkout())
 			apiRouter.GET("/workout/all", s.getAllWorkouts())
 			apiRouter.GET("/workout/:id", s.getOneWorkout())
 			apiRouter.PATCH("/workout/:id", s.updateWorkout())
 			apiRouter.PATCH("/workout/exercises/remove/:id", s.removeExercisesFromWorkout())
 			apiRouter.PATCH("/workout/exercises/add/:id", s.addExercisesToWorkout())
 			apiRouter.PATCH("/workout/status/:wid/:weid", s.updateWorkoutExerciseStatus())
+			apiRouter.POST("/workout/:id/start", s.transitionWorkout("started", fwt.WorkoutService.StartWorkout))
+			apiRouter.POST("/workout/:id/finish", s.transitionWorkout("finished", fwt.WorkoutService.FinishWorkout))
+			apiRouter.POST("/workout/:id/skip", s.transitionWorkout("skipped", fwt.WorkoutService.SkipWorkout))
 			apiRouter.DELETE("/workout/:id", s.deleteWorkout())
 		}
 	}
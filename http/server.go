@@ -2,22 +2,40 @@ package http
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/maliByatzes/fwt"
-	"github.com/maliByatzes/fwt/postgres"
 	"github.com/maliByatzes/fwt/token"
 )
 
 const TimeOut = 5 * time.Second
 
+const (
+	// DefaultShutdownDelay is how long Close keeps serving once /readyz
+	// reports shutting down, so that load balancers stop sending traffic
+	// before the listener closes.
+	DefaultShutdownDelay = 5 * time.Second
+
+	// DefaultShutdownTimeout bounds how long Close waits for in-flight
+	// requests. It is longer than TimeOut so that none are cut off.
+	DefaultShutdownTimeout = 2 * TimeOut
+)
+
 type Server struct {
 	Server                 *http.Server
+	MetricsServer          *http.Server
 	Router                 *gin.Engine
+	Logger                 *slog.Logger
 	TokenMaker             token.Maker
 	UserService            fwt.UserService
 	ProfileService         fwt.ProfileService
@@ -25,34 +43,196 @@ type Server struct {
 	ExerciseService        fwt.ExerciseService
 	WorkoutExerciseService fwt.WorkoutExerciseService
 	WEStatusService        fwt.WEStatusService
+	BodyMeasurementService fwt.BodyMeasurementService
+	GoalService            fwt.GoalService
+	CalendarFeedService    fwt.CalendarFeedService
+	IdempotencyService     fwt.IdempotencyService
+	TxRunner               fwt.TxRunner
+	Health                 HealthChecker
+
+	// Now returns the current time. It is replaced in tests.
+	Now func() time.Time
+
+	IdempotencyKeyTTL  time.Duration
+	IdempotencyLockTTL time.Duration
+	RateLimitStore     RateLimitStore
+	RateLimitPolicies  map[string]RateLimitPolicy
+	ReadinessTimeout   time.Duration
+	ShutdownDelay      time.Duration
+	ShutdownTimeout    time.Duration
+
+	// ExportDir holds the archives of data exports until they expire.
+	ExportDir     string
+	ExportLinkTTL time.Duration
+	ExportKey     []byte
+
+	shuttingDown atomic.Bool
+
+	exportsMu sync.Mutex
+	exports   map[string]*fwt.Export
+	exportWG  sync.WaitGroup
 }
 
-func NewServer(db *postgres.DB, secretKey string) (*Server, error) {
-	s := Server{
+// Options holds the dependencies of a Server. Services are plain fwt
+// interfaces so that any backend, or a decorator wrapping one, can be used.
+type Options struct {
+	UserService            fwt.UserService
+	ProfileService         fwt.ProfileService
+	WorkoutService         fwt.WorkoutService
+	ExerciseService        fwt.ExerciseService
+	WorkoutExerciseService fwt.WorkoutExerciseService
+	WEStatusService        fwt.WEStatusService
+	BodyMeasurementService fwt.BodyMeasurementService
+	GoalService            fwt.GoalService
+	CalendarFeedService    fwt.CalendarFeedService
+	IdempotencyService     fwt.IdempotencyService
+
+	// TxRunner makes compound operations atomic. When nil, each service
+	// call runs in its own transaction.
+	TxRunner fwt.TxRunner
+
+	// Health is used by /readyz. When nil, only shutdown is reported.
+	Health HealthChecker
+
+	// TokenMaker creates and verifies access tokens. It is required.
+	TokenMaker token.Maker
+
+	// ExportKey signs the download links of data exports. A random key is
+	// used when it is empty, so links stop working on restart.
+	ExportKey []byte
+
+	// Logger defaults to the slog default logger. It is used as given, so
+	// records carry request and user IDs only if its handler is wrapped in
+	// fwt.NewLogHandler, as cmd/fwt does.
+	Logger *slog.Logger
+
+	// Now defaults to time.Now.
+	Now func() time.Time
+
+	Config Config
+}
+
+// Config tunes the behaviour of a Server. Zero values select the defaults.
+type Config struct {
+	IdempotencyKeyTTL  time.Duration
+	IdempotencyLockTTL time.Duration
+	RateLimitStore     RateLimitStore
+	RateLimitPolicies  map[string]RateLimitPolicy
+	ReadinessTimeout   time.Duration
+	ShutdownDelay      time.Duration
+	ShutdownTimeout    time.Duration
+	ExportDir          string
+	ExportLinkTTL      time.Duration
+
+	// TrustedProxies lists the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header gives the client IP used for rate
+	// limiting. When empty, no proxy is trusted and the address of the
+	// connection is used, as the header can be set by anyone.
+	TrustedProxies []string
+}
+
+func NewServer(opts Options) (*Server, error) {
+	if opts.TokenMaker == nil {
+		return nil, fmt.Errorf("http: token maker is required")
+	}
+
+	s := &Server{
 		Server: &http.Server{
 			WriteTimeout: TimeOut,
 			ReadTimeout:  TimeOut,
 			IdleTimeout:  TimeOut,
 		},
-		Router: gin.Default(),
+		Router:                 gin.New(),
+		Logger:                 opts.Logger,
+		TokenMaker:             opts.TokenMaker,
+		UserService:            opts.UserService,
+		ProfileService:         opts.ProfileService,
+		WorkoutService:         opts.WorkoutService,
+		ExerciseService:        opts.ExerciseService,
+		WorkoutExerciseService: opts.WorkoutExerciseService,
+		WEStatusService:        opts.WEStatusService,
+		BodyMeasurementService: opts.BodyMeasurementService,
+		GoalService:            opts.GoalService,
+		CalendarFeedService:    opts.CalendarFeedService,
+		IdempotencyService:     opts.IdempotencyService,
+		TxRunner:               opts.TxRunner,
+		Health:                 opts.Health,
+		Now:                    opts.Now,
+		IdempotencyKeyTTL:      opts.Config.IdempotencyKeyTTL,
+		IdempotencyLockTTL:     opts.Config.IdempotencyLockTTL,
+		RateLimitStore:         opts.Config.RateLimitStore,
+		RateLimitPolicies:      opts.Config.RateLimitPolicies,
+		ReadinessTimeout:       opts.Config.ReadinessTimeout,
+		ShutdownDelay:          opts.Config.ShutdownDelay,
+		ShutdownTimeout:        opts.Config.ShutdownTimeout,
+		ExportDir:              opts.Config.ExportDir,
+		ExportLinkTTL:          opts.Config.ExportLinkTTL,
+		ExportKey:              opts.ExportKey,
+		exports:                make(map[string]*fwt.Export),
+	}
+
+	if s.Logger == nil {
+		s.Logger = slog.Default()
+	}
+	if s.TxRunner == nil {
+		s.TxRunner = noTx{}
+	}
+	if s.Now == nil {
+		s.Now = time.Now
+	}
+	if s.IdempotencyKeyTTL == 0 {
+		s.IdempotencyKeyTTL = DefaultIdempotencyKeyTTL
+	}
+	if s.IdempotencyLockTTL == 0 {
+		s.IdempotencyLockTTL = DefaultIdempotencyLockTTL
+	}
+	if s.RateLimitStore == nil {
+		s.RateLimitStore = NewMemoryRateLimitStore()
+	}
+	if s.RateLimitPolicies == nil {
+		s.RateLimitPolicies = DefaultRateLimitPolicies()
+	}
+	if s.ReadinessTimeout == 0 {
+		s.ReadinessTimeout = DefaultReadinessTimeout
+	}
+	if s.ShutdownDelay == 0 {
+		s.ShutdownDelay = DefaultShutdownDelay
+	}
+	if s.ShutdownTimeout == 0 {
+		s.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if s.ExportDir == "" {
+		s.ExportDir = filepath.Join(os.TempDir(), "fwt-exports")
+	}
+	if s.ExportLinkTTL == 0 {
+		s.ExportLinkTTL = DefaultExportLinkTTL
+	}
+	if len(s.ExportKey) == 0 {
+		s.ExportKey = make([]byte, 32)
+		if _, err := rand.Read(s.ExportKey); err != nil {
+			return nil, fmt.Errorf("http: cannot generate export key: %w", err)
+		}
 	}
 
-	tkMaker, err := token.NewJWTMaker(secretKey)
-	if err != nil {
-		return nil, err
+	if err := s.Router.SetTrustedProxies(opts.Config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("http: invalid trusted proxies: %w", err)
 	}
-	s.TokenMaker = tkMaker
 
 	s.routes()
-	s.UserService = postgres.NewUserService(db)
-	s.ProfileService = postgres.NewProfileService(db)
-	s.WorkoutService = postgres.NewWorkoutService(db)
-	s.ExerciseService = postgres.NewExerciseService(db)
-	s.WorkoutExerciseService = postgres.NewWorkoutExerciseService(db)
-	s.WEStatusService = postgres.NewWEStatusService(db)
 	s.Server.Handler = s.Router
 
-	return &s, nil
+	return s, nil
+}
+
+// noTx runs units of work without a shared transaction.
+type noTx struct{}
+
+func (noTx) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (noTx) RunInTxOptions(ctx context.Context, opts fwt.TxOptions, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
 
 func (s *Server) Run(port string) error {
@@ -60,20 +240,23 @@ func (s *Server) Run(port string) error {
 		port = ":" + port
 	}
 	s.Server.Addr = port
-	log.Printf("🚀 Server starting on port %s", port)
+	s.Logger.Info("🚀 Server starting", "addr", port)
 	return s.Server.ListenAndServe()
 }
 
+// Close marks the server as not ready and keeps serving for ShutdownDelay,
+// so that probes see it before the listener closes. It then drains in-flight
+// requests and waits for exports being built.
 func (s *Server) Close() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
-	return s.Server.Shutdown(ctx)
-}
+	s.shuttingDown.Store(true)
+	defer s.exportWG.Wait()
 
-func healthCheck() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		ctx.JSON(http.StatusOK, gin.H{
-			"status": "available",
-		})
+	time.Sleep(s.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	if err := s.closeMetrics(ctx); err != nil {
+		return err
 	}
+	return s.Server.Shutdown(ctx)
 }
@@ -1,11 +1,19 @@
 package http_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/maliByatzes/fwt"
 	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/inmem"
 	"github.com/maliByatzes/fwt/mock"
-	"github.com/maliByatzes/fwt/postgres"
+	"github.com/maliByatzes/fwt/token"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,16 +27,157 @@ type Server struct {
 	UserService mock.UserService
 }
 
-func MustOpenServer(tb testing.TB) *Server {
+// MustNewServer returns a server built from opts. A JWT token maker using
+// TestSecretKey is supplied when opts does not set one.
+func MustNewServer(tb testing.TB, opts fwthttp.Options) *fwthttp.Server {
+	tb.Helper()
+
+	if opts.TokenMaker == nil {
+		tokenMaker, err := token.NewJWTMaker(TestSecretKey)
+		require.NoError(tb, err)
+		opts.TokenMaker = tokenMaker
+	}
+	if opts.Config.ExportDir == "" {
+		opts.Config.ExportDir = tb.TempDir()
+	}
+	if opts.Config.ShutdownDelay == 0 {
+		opts.Config.ShutdownDelay = time.Millisecond
+	}
+
+	s, err := fwthttp.NewServer(opts)
+	require.NoError(tb, err)
+	return s
+}
+
+// MemoryServer is a Server backed by the in-memory services, so handlers
+// can be exercised end-to-end without a database.
+type MemoryServer struct {
+	*fwthttp.Server
+	DB *inmem.DB
+}
+
+// MustNewMemoryServer returns a server whose services share a fresh
+// in-memory database seeded with the default exercise catalog.
+func MustNewMemoryServer(tb testing.TB) *MemoryServer {
+	tb.Helper()
+
+	db := inmem.NewDB()
+	require.NoError(tb, db.SeedExercises())
+
+	s := MustNewServer(tb, fwthttp.Options{
+		UserService:            inmem.NewUserService(db),
+		ProfileService:         inmem.NewProfileService(db),
+		WorkoutService:         inmem.NewWorkoutService(db),
+		ExerciseService:        inmem.NewExerciseService(db),
+		WorkoutExerciseService: inmem.NewWorkoutExerciseService(db),
+		WEStatusService:        inmem.NewWEStatusService(db),
+		BodyMeasurementService: inmem.NewBodyMeasurementService(db),
+		GoalService:            inmem.NewGoalService(db),
+		CalendarFeedService:    inmem.NewCalendarFeedService(db),
+		IdempotencyService:     inmem.NewIdempotencyService(db),
+		TxRunner:               db,
+		Health:                 db,
+	})
+	return &MemoryServer{Server: s, DB: db}
+}
+
+// MustCreateUser creates a user with the password "password123" and returns
+// it with an access token for it.
+func (s *MemoryServer) MustCreateUser(tb testing.TB, username string) (*fwt.User, string) {
 	tb.Helper()
 
-	srv, err := fwthttp.NewServer(&postgres.DB{}, TestSecretKey)
+	user := &fwt.User{Username: username, Email: username + "@email.com"}
+	require.NoError(tb, user.SetPassword("password123"))
+	require.NoError(tb, s.UserService.CreateUser(context.Background(), user))
+
+	token, _, err := s.TokenMaker.CreateToken(user.ID, user.Username, time.Minute)
 	require.NoError(tb, err)
-	s := &Server{Server: srv}
 
-	s.Server.UserService = &s.UserService
+	return user, token
+}
+
+// MustCreateWorkout creates a workout for user scheduled for the day after
+// tomorrow with the given exercises from the default catalog.
+func (s *MemoryServer) MustCreateWorkout(tb testing.TB, user *fwt.User, exercises ...string) *fwt.Workout {
+	tb.Helper()
+
+	workout := &fwt.Workout{Name: "Leg day", ScheduledDate: time.Now().Add(48 * time.Hour)}
+	for _, name := range exercises {
+		workout.Exercises = append(workout.Exercises, &fwt.Exercise{Name: name})
+	}
+
+	ctx := fwt.NewContextWithUser(context.Background(), user)
+	require.NoError(tb, s.WorkoutService.CreateWorkout(ctx, workout))
+	return workout
+}
+
+// handlerTest describes a single request and the response it should get.
+type handlerTest struct {
+	name   string
+	method string
+	path   string
+	token  string
+	header map[string]string
+	body   string
+
+	status int
+	// wantHeader lists response headers that must have the given values.
+	wantHeader map[string]string
+	// error, when set, must equal the "error" field of the response.
+	error string
+	// check, when set, inspects the decoded response body.
+	check func(t *testing.T, body map[string]any)
+}
+
+// runHandlerTests issues each request in order against h, so later cases
+// observe the changes made by earlier ones.
+func runHandlerTests(t *testing.T, h http.Handler, tests []handlerTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(h, tt.method, tt.path, tt.token, tt.body, tt.header)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			for k, v := range tt.wantHeader {
+				require.Equal(t, v, w.Header().Get(k), k)
+			}
+
+			if tt.error == "" && tt.check == nil {
+				return
+			}
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			if tt.error != "" {
+				require.Equal(t, tt.error, body["error"])
+			}
+			if tt.check != nil {
+				tt.check(t, body)
+			}
+		})
+	}
+}
+
+func doRequest(h http.Handler, method, path, token, body string, header map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func MustOpenServer(tb testing.TB) *Server {
+	tb.Helper()
+
+	s := &Server{}
+	s.Server = MustNewServer(tb, fwthttp.Options{UserService: &s.UserService})
 
-	err = s.Run(TestPort)
+	err := s.Run(TestPort)
 	require.NoError(tb, err)
 
 	return s
@@ -39,3 +188,19 @@ func MustCloseServer(tb testing.TB, s *Server) {
 	err := s.Close()
 	require.NoError(tb, err)
 }
+
+func TestNewServer(t *testing.T) {
+	t.Run("ErrTokenMakerRequired", func(t *testing.T) {
+		_, err := fwthttp.NewServer(fwthttp.Options{})
+		require.Error(t, err)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		s := MustNewServer(t, fwthttp.Options{})
+		require.NotNil(t, s.Logger)
+		require.NotNil(t, s.Now)
+		require.Equal(t, fwthttp.DefaultIdempotencyKeyTTL, s.IdempotencyKeyTTL)
+		require.Equal(t, fwthttp.DefaultReadinessTimeout, s.ReadinessTimeout)
+		require.Equal(t, fwthttp.DefaultRateLimitPolicies(), s.RateLimitPolicies)
+	})
+}
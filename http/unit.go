@@ -0,0 +1,155 @@
+package http
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maliByatzes/fwt"
+)
+
+// Quantities are stored in metric units and converted only here, when they
+// are read from a request or written to a response. The units of a request
+// are the user's preferred units, overridden by the unit query parameter
+// and then by the unit field of the request body, if any. Responses that
+// carry converted values report the units they use.
+
+// requestUnits returns the units of the current request, starting from the
+// preferences on the user's profile. On failure it writes the error
+// response and returns false.
+func (s *Server) requestUnits(c *gin.Context, override string) (fwt.Units, bool) {
+	units := fwt.MetricUnits
+	if user := fwt.UserFromContext(c.Request.Context()); user != nil {
+		profile, err := s.ProfileService.FindProfileByUserID(c.Request.Context(), user.ID)
+		if err == nil {
+			units = units.Merge(profile.Units)
+		} else if fwt.ErrorCode(err) != fwt.ENOTFOUND {
+			s.Logger.ErrorContext(c.Request.Context(), "error in request units", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return units, false
+		}
+	}
+
+	return resolveUnits(c, units, override)
+}
+
+// resolveUnits applies the overrides of the current request to units. On
+// failure it writes the error response and returns false.
+func resolveUnits(c *gin.Context, units fwt.Units, override string) (fwt.Units, bool) {
+	for _, v := range []string{c.Query("unit"), override} {
+		u, err := fwt.ParseUnits(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fwt.ErrorMessage(err),
+			})
+			return units, false
+		}
+		units = units.Merge(u)
+	}
+
+	if err := units.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fwt.ErrorMessage(err),
+		})
+		return units, false
+	}
+
+	return units, true
+}
+
+// fromKilograms converts a stored weight for a response. Converted values
+// are rounded to two decimals so that round trips read back as entered.
+func fromKilograms(u fwt.WeightUnit, v float64) float64 {
+	if u == fwt.Kilogram {
+		return v
+	}
+	return math.Round(u.FromKilograms(v)*100) / 100
+}
+
+// fromCentimetres converts a stored length for a response.
+func fromCentimetres(u fwt.LengthUnit, v float64) float64 {
+	if u == fwt.Centimetre {
+		return v
+	}
+	return math.Round(u.FromCentimetres(v)*100) / 100
+}
+
+func profileInUnits(p *fwt.Profile, u fwt.Units) *fwt.Profile {
+	other := *p
+	other.Height = fromCentimetres(u.Length, p.Height)
+	other.Weight = fromKilograms(u.Weight, p.Weight)
+	return &other
+}
+
+func bodyMeasurementInUnits(m *fwt.BodyMeasurement, u fwt.Units) *fwt.BodyMeasurement {
+	other := *m
+	other.Weight = fromKilograms(u.Weight, m.Weight)
+	other.Waist = fromCentimetres(u.Length, m.Waist)
+	other.Chest = fromCentimetres(u.Length, m.Chest)
+	other.Arms = fromCentimetres(u.Length, m.Arms)
+	other.Thighs = fromCentimetres(u.Length, m.Thighs)
+	return &other
+}
+
+func bodyMeasurementsInUnits(a []*fwt.BodyMeasurement, u fwt.Units) []*fwt.BodyMeasurement {
+	other := make([]*fwt.BodyMeasurement, len(a))
+	for i, m := range a {
+		other[i] = bodyMeasurementInUnits(m, u)
+	}
+	return other
+}
+
+// workoutInUnits converts the set loads of w, copying the exercises and
+// sets so that w itself is left as the service returned it.
+func workoutInUnits(w *fwt.Workout, u fwt.Units) *fwt.Workout {
+	other := *w
+	if w.Exercises != nil {
+		other.Exercises = make([]*fwt.Exercise, len(w.Exercises))
+	}
+	for i, ex := range w.Exercises {
+		exercise := *ex
+		if ex.Sets != nil {
+			exercise.Sets = make([]*fwt.Set, len(ex.Sets))
+		}
+		for j, set := range ex.Sets {
+			s := *set
+			s.Load = fromKilograms(u.Weight, set.Load)
+			exercise.Sets[j] = &s
+		}
+		other.Exercises[i] = &exercise
+	}
+	return &other
+}
+
+func workoutsInUnits(a []*fwt.Workout, u fwt.Units) []*fwt.Workout {
+	other := make([]*fwt.Workout, len(a))
+	for i, w := range a {
+		other[i] = workoutInUnits(w, u)
+	}
+	return other
+}
+
+// goalInKilograms reports whether the target of g is a weight. Only
+// frequency goals count something else.
+func goalInKilograms(g *fwt.Goal) bool {
+	return g.Type != fwt.GoalTypeFrequency
+}
+
+func goalInUnits(g *fwt.Goal, u fwt.Units) *fwt.Goal {
+	other := *g
+	if goalInKilograms(g) {
+		other.Target = fromKilograms(u.Weight, g.Target)
+		other.Start = fromKilograms(u.Weight, g.Start)
+	}
+	return &other
+}
+
+func goalProgressInUnits(p *fwt.GoalProgress, g *fwt.Goal, u fwt.Units) *fwt.GoalProgress {
+	other := *p
+	if goalInKilograms(g) {
+		other.Current = fromKilograms(u.Weight, p.Current)
+	}
+	return &other
+}
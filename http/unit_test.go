@@ -0,0 +1,122 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitConversion(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	ctx := fwt.NewContextWithUser(context.Background(), jane)
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.RFC3339)
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Profile/Create",
+			method: http.MethodPost,
+			path:   "/api/v1/profile/create",
+			token:  janeToken,
+			body:   `{"profile":{"first_name":"Jane","height":70,"weight":176.37,"units":{"weight":"lb","length":"in"}}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				profile := body["profile"].(map[string]any)
+				require.Equal(t, float64(70), profile["height"])
+				require.Equal(t, 176.37, profile["weight"])
+				require.Equal(t, map[string]any{"weight": "lb", "length": "in", "distance": "km"}, body["units"])
+
+				stored, err := s.ProfileService.FindProfileByUserID(ctx, jane.ID)
+				require.NoError(t, err)
+				require.InDelta(t, 177.8, stored.Height, 1e-9)
+				require.InDelta(t, 80, stored.Weight, 0.01)
+			},
+		},
+		{
+			name:   "Profile/QueryOverride",
+			method: http.MethodGet,
+			path:   "/api/v1/profile?unit=metric",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				profile := body["profile"].(map[string]any)
+				require.InDelta(t, 177.8, profile["height"], 1e-9)
+				require.InDelta(t, 80, profile["weight"], 0.01)
+			},
+		},
+		{
+			name:   "Profile/ErrInvalidUnit",
+			method: http.MethodGet,
+			path:   "/api/v1/profile?unit=stone",
+			token:  janeToken,
+			status: http.StatusBadRequest,
+			error:  "Unit is invalid.",
+		},
+		{
+			name:   "Measurement/Preferred",
+			method: http.MethodPost,
+			path:   "/api/v1/measurements",
+			token:  janeToken,
+			body:   `{"measurement":{"weight":180,"waist":34}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				m := body["measurement"].(map[string]any)
+				require.Equal(t, float64(180), m["weight"])
+				require.Equal(t, float64(34), m["waist"])
+
+				stored, err := s.BodyMeasurementService.FindBodyMeasurementByID(ctx, uint(m["id"].(float64)))
+				require.NoError(t, err)
+				require.InDelta(t, 81.65, stored.Weight, 0.01)
+				require.InDelta(t, 86.36, stored.Waist, 1e-9)
+			},
+		},
+		{
+			name:   "Measurement/BodyOverride",
+			method: http.MethodPost,
+			path:   "/api/v1/measurements",
+			token:  janeToken,
+			body:   `{"measurement":{"weight":82,"waist":35},"unit":"kg"}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				m := body["measurement"].(map[string]any)
+				require.Equal(t, float64(82), m["weight"])
+				require.Equal(t, float64(35), m["waist"])
+				require.Equal(t, map[string]any{"weight": "kg", "length": "in", "distance": "km"}, body["units"])
+			},
+		},
+		{
+			name:   "Workout/LogSets",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/log",
+			token:  janeToken,
+			body:   `{"workout": {"name": "Heavy", "scheduled_date": "` + yesterday + `", "exercises": [{"name": "Squat", "sets": [{"reps": 5, "load": 225}]}]}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				workout := body["workout"].(map[string]any)
+				sets := workout["exercises"].([]any)[0].(map[string]any)["sets"].([]any)
+				require.Equal(t, float64(225), sets[0].(map[string]any)["load"])
+
+				stored, err := s.WorkoutService.FindWorkoutByID(ctx, uint(workout["id"].(float64)))
+				require.NoError(t, err)
+				require.InDelta(t, 102.06, stored.Exercises[0].Sets[0].Load, 0.01)
+			},
+		},
+		{
+			name:   "Workout/ListMetric",
+			method: http.MethodGet,
+			path:   "/api/v1/workout/all?unit=kg",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				workout := body["workouts"].([]any)[0].(map[string]any)
+				sets := workout["exercises"].([]any)[0].(map[string]any)["sets"].([]any)
+				require.InDelta(t, 102.06, sets[0].(map[string]any)["load"], 0.01)
+			},
+		},
+	})
+}
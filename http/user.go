@@ -1,7 +1,6 @@
 package http
 
 import (
-	"log"
 	"net/http"
 	"time"
 
@@ -10,15 +9,15 @@ import (
 )
 
 func (s *Server) createUser() gin.HandlerFunc {
-	var req struct {
-		User struct {
-			Username string `json:"username" binding:"required,min=3"`
-			Email    string `json:"email" binding:"required,email"`
-			Password string `json:"password" binding:"required,min=8,max=72"`
-		} `json:"user" binding:"required"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			User struct {
+				Username string `json:"username" binding:"required,min=3"`
+				Email    string `json:"email" binding:"required,email"`
+				Password string `json:"password" binding:"required,min=8,max=72"`
+			} `json:"user" binding:"required"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -39,7 +38,7 @@ func (s *Server) createUser() gin.HandlerFunc {
 				})
 				return
 			}
-			log.Printf("error in create user handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in create user handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -53,14 +52,14 @@ func (s *Server) createUser() gin.HandlerFunc {
 }
 
 func (s *Server) loginUser() gin.HandlerFunc {
-	var req struct {
-		User struct {
-			Username string `json:"username" binding:"required,min=3"`
-			Password string `json:"password" binding:"required,min=8,max=72"`
-		} `json:"user" binding:"required"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			User struct {
+				Username string `json:"username" binding:"required,min=3"`
+				Password string `json:"password" binding:"required,min=8,max=72"`
+			} `json:"user" binding:"required"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -76,20 +75,28 @@ func (s *Server) loginUser() gin.HandlerFunc {
 			return
 		}
 
+		if user.Deleted() {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":    "Account is scheduled for deletion. Restore it to sign in again.",
+				"purge_at": user.PurgeAt(),
+			})
+			return
+		}
+
 		accessToken, accessPayload, err := s.TokenMaker.CreateToken(
 			user.ID,
 			user.Username,
 			time.Hour*24,
 		)
 		if err != nil {
-			log.Printf("error in create token in login user: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in create token in login user", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
-		duration := accessPayload.ExpiredAt.Sub(time.Now())
+		duration := accessPayload.ExpiredAt.Sub(s.Now())
 
 		c.SetCookie(
 			"access_token",
@@ -133,14 +140,14 @@ func (s *Server) getCurrentUser() gin.HandlerFunc {
 }
 
 func (s *Server) updateUser() gin.HandlerFunc {
-	var req struct {
-		User struct {
-			Username string `json:"username"`
-			Email    string `json:"email"`
-		} `json:"user"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			User struct {
+				Username string `json:"username"`
+				Email    string `json:"email"`
+			} `json:"user"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -180,7 +187,7 @@ func (s *Server) updateUser() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in update user handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in update user handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -194,8 +201,22 @@ func (s *Server) updateUser() gin.HandlerFunc {
 	}
 }
 
+// deleteUser schedules the deletion of the current user once they confirm
+// it with their password. The account can be restored with restoreUser
+// until the grace period ends, when all of its data is removed.
 func (s *Server) deleteUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var req struct {
+			Password string `json:"password" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		user := fwt.UserFromContext(c.Request.Context())
 		if user == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -204,18 +225,89 @@ func (s *Server) deleteUser() gin.HandlerFunc {
 			return
 		}
 
-		err := s.UserService.DeleteUser(c.Request.Context(), user.ID)
+		if _, err := s.UserService.Authenticate(c.Request.Context(), user.Username, req.Password); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid password",
+			})
+			return
+		}
+
+		deletedUser, err := s.UserService.ScheduleUserDeletion(c.Request.Context(), user.ID)
 		if err != nil {
-			log.Printf("error in delete user handler: %v", err)
+			if fwt.ErrorCode(err) == fwt.ECONFLICT {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
+			s.Logger.ErrorContext(c.Request.Context(), "error in delete user handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
+			return
+		}
+
+		if err := s.RemoveUserExports(user.ID); err != nil {
+			s.Logger.WarnContext(c.Request.Context(), "cannot remove exports of deleted user", "user_id", user.ID, "error", err)
 		}
 
 		c.SetCookie("access_token", "", -1, "/", "localhost", false, true)
 
 		c.JSON(http.StatusOK, gin.H{
-			"message": "user deleted successfully",
+			"message":  "user scheduled for deletion",
+			"user":     deletedUser,
+			"purge_at": deletedUser.PurgeAt(),
+		})
+	}
+}
+
+// restoreUser cancels the scheduled deletion of an account. The user cannot
+// sign in meanwhile, so they authenticate with their credentials instead.
+func (s *Server) restoreUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			User struct {
+				Username string `json:"username" binding:"required,min=3"`
+				Password string `json:"password" binding:"required,min=8,max=72"`
+			} `json:"user" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		user, err := s.UserService.Authenticate(c.Request.Context(), req.User.Username, req.User.Password)
+		if err != nil || user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid credentials",
+			})
+			return
+		}
+
+		ctx := fwt.NewContextWithUser(c.Request.Context(), user)
+		restoredUser, err := s.UserService.RestoreUser(ctx, user.ID)
+		if err != nil {
+			if fwt.ErrorCode(err) == fwt.ECONFLICT {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
+			s.Logger.ErrorContext(c.Request.Context(), "error in restore user handler", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Internal Server Error",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "user restored successfully",
+			"user":    restoredUser,
 		})
 	}
 }
@@ -0,0 +1,299 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+
+	ctx := fwt.NewContextWithUser(context.Background(), jane)
+	require.NoError(t, s.ProfileService.CreateProfile(ctx, &fwt.Profile{FirstName: "Jane"}))
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Register",
+			method: http.MethodPost,
+			path:   "/api/v1/users/register",
+			body:   `{"user":{"username":"susan","email":"susan@email.com","password":"password123"}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				user := body["user"].(map[string]any)
+				require.Equal(t, "susan", user["username"])
+				require.NotContains(t, user, "hashed_password")
+			},
+		},
+		{
+			name:   "Register/ErrInvalidEmail",
+			method: http.MethodPost,
+			path:   "/api/v1/users/register",
+			body:   `{"user":{"username":"susan","email":"susan","password":"password123"}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Register/ErrShortPassword",
+			method: http.MethodPost,
+			path:   "/api/v1/users/register",
+			body:   `{"user":{"username":"mark","email":"mark@email.com","password":"short"}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Register/ErrUsernameTaken",
+			method: http.MethodPost,
+			path:   "/api/v1/users/register",
+			body:   `{"user":{"username":"janedoe","email":"other@email.com","password":"password123"}}`,
+			status: http.StatusConflict,
+			error:  "This username already exists.",
+		},
+		{
+			name:   "Login",
+			method: http.MethodPost,
+			path:   "/api/v1/users/login",
+			body:   `{"user":{"username":"janedoe","password":"password123"}}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.NotEmpty(t, body["access_token"])
+				require.Equal(t, "janedoe", body["user"].(map[string]any)["username"])
+			},
+		},
+		{
+			name:   "Login/ErrWrongPassword",
+			method: http.MethodPost,
+			path:   "/api/v1/users/login",
+			body:   `{"user":{"username":"janedoe","password":"password456"}}`,
+			status: http.StatusUnauthorized,
+			error:  "Invalid credentials",
+		},
+		{
+			name:   "Login/ErrUnknownUser",
+			method: http.MethodPost,
+			path:   "/api/v1/users/login",
+			body:   `{"user":{"username":"nobody","password":"password123"}}`,
+			status: http.StatusUnauthorized,
+			error:  "Invalid credentials",
+		},
+		{
+			name:   "Login/ErrMissingUser",
+			method: http.MethodPost,
+			path:   "/api/v1/users/login",
+			body:   `{}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Me",
+			method: http.MethodGet,
+			path:   "/api/v1/users/me",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(jane.ID), body["user"].(map[string]any)["id"])
+			},
+		},
+		{
+			name:   "Me/ErrNoToken",
+			method: http.MethodGet,
+			path:   "/api/v1/users/me",
+			status: http.StatusUnauthorized,
+			error:  "Unauthorized - No access token",
+		},
+		{
+			name:   "Me/ErrInvalidToken",
+			method: http.MethodGet,
+			path:   "/api/v1/users/me",
+			token:  "not-a-token",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Update",
+			method: http.MethodPatch,
+			path:   "/api/v1/users/update",
+			token:  janeToken,
+			body:   `{"user":{"username":"jane"}}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, "jane", body["user"].(map[string]any)["username"])
+			},
+		},
+		{
+			name:   "Update/ErrUsernameTaken",
+			method: http.MethodPatch,
+			path:   "/api/v1/users/update",
+			token:  janeToken,
+			body:   `{"user":{"username":"johndoe"}}`,
+			status: http.StatusConflict,
+			error:  "This username already exists.",
+		},
+		{
+			// Fields from earlier requests must not leak into this one.
+			name:   "Update/EmailOnly",
+			method: http.MethodPatch,
+			path:   "/api/v1/users/update",
+			token:  janeToken,
+			body:   `{"user":{"email":"jane@example.com"}}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				user := body["user"].(map[string]any)
+				require.Equal(t, "jane", user["username"])
+				require.Equal(t, "jane@example.com", user["email"])
+			},
+		},
+		{
+			name:   "Delete/ErrNoPassword",
+			method: http.MethodDelete,
+			path:   "/api/v1/users/delete",
+			token:  janeToken,
+			body:   `{}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Delete/ErrWrongPassword",
+			method: http.MethodDelete,
+			path:   "/api/v1/users/delete",
+			token:  janeToken,
+			body:   `{"password":"wrongpassword"}`,
+			status: http.StatusUnauthorized,
+			error:  "Invalid password",
+		},
+		{
+			// Jane still has a profile, which is kept until the account
+			// is purged.
+			name:   "Delete",
+			method: http.MethodDelete,
+			path:   "/api/v1/users/delete",
+			token:  janeToken,
+			body:   `{"password":"password123"}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				user := body["user"].(map[string]any)
+				require.NotEqual(t, "0001-01-01T00:00:00Z", user["deleted_at"])
+				require.NotEmpty(t, body["purge_at"])
+
+				_, err := s.ProfileService.FindProfileByUserID(ctx, jane.ID)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "Me/ErrDeleted",
+			method: http.MethodGet,
+			path:   "/api/v1/users/me",
+			token:  janeToken,
+			status: http.StatusUnauthorized,
+			error:  "Unauthorized - account is scheduled for deletion",
+		},
+		{
+			name:   "Login/ErrDeleted",
+			method: http.MethodPost,
+			path:   "/api/v1/users/login",
+			body:   `{"user":{"username":"jane","password":"password123"}}`,
+			status: http.StatusForbidden,
+			error:  "Account is scheduled for deletion. Restore it to sign in again.",
+		},
+	})
+
+	// Restoring is limited like logging in, and this test has used up the
+	// budget of the client already.
+	s.RateLimitStore = fwthttp.NewMemoryRateLimitStore()
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Restore/ErrWrongPassword",
+			method: http.MethodPost,
+			path:   "/api/v1/users/restore",
+			body:   `{"user":{"username":"jane","password":"wrongpassword"}}`,
+			status: http.StatusUnauthorized,
+			error:  "Invalid credentials",
+		},
+		{
+			name:   "Restore",
+			method: http.MethodPost,
+			path:   "/api/v1/users/restore",
+			body:   `{"user":{"username":"jane","password":"password123"}}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, "0001-01-01T00:00:00Z", body["user"].(map[string]any)["deleted_at"])
+			},
+		},
+		{
+			name:   "Restore/ErrNotDeleted",
+			method: http.MethodPost,
+			path:   "/api/v1/users/restore",
+			body:   `{"user":{"username":"jane","password":"password123"}}`,
+			status: http.StatusConflict,
+			error:  "User is not scheduled for deletion.",
+		},
+		{
+			name:   "Me/Restored",
+			method: http.MethodGet,
+			path:   "/api/v1/users/me",
+			token:  janeToken,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Delete/Other",
+			method: http.MethodDelete,
+			path:   "/api/v1/users/delete",
+			token:  johnToken,
+			body:   `{"password":"password123"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Logout",
+			method: http.MethodPost,
+			path:   "/api/v1/users/logout",
+			status: http.StatusOK,
+		},
+	})
+}
+
+func TestUserHandlers_RestoreAfterGracePeriod(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, _ := s.MustCreateUser(t, "janedoe")
+
+	ctx := fwt.NewContextWithUser(context.Background(), jane)
+	jane, err := s.UserService.ScheduleUserDeletion(ctx, jane.ID)
+	require.NoError(t, err)
+	s.DB.Now = func() time.Time { return jane.PurgeAt().Add(time.Minute) }
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Restore/ErrGracePeriodEnded",
+			method: http.MethodPost,
+			path:   "/api/v1/users/restore",
+			body:   `{"user":{"username":"janedoe","password":"password123"}}`,
+			status: http.StatusConflict,
+			error:  "User can no longer be restored as the deletion grace period has ended.",
+		},
+	})
+}
+
+func TestUserHandlers_ErrInternal(t *testing.T) {
+	s := MustNewServer(t, fwthttp.Options{
+		UserService: &mock.UserService{
+			CreateUserFn: func(ctx context.Context, user *fwt.User) error {
+				return errors.New("connection reset")
+			},
+		},
+	})
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Register",
+			method: http.MethodPost,
+			path:   "/api/v1/users/register",
+			body:   `{"user":{"username":"susan","email":"susan@email.com","password":"password123"}}`,
+			status: http.StatusInternalServerError,
+			error:  "Internal Server Error",
+		},
+	})
+}
@@ -1,25 +1,26 @@
 package http
 
 import (
-	"log"
+	"context"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/maliByatzes/fwt"
+	"github.com/maliByatzes/fwt/metrics"
 )
 
 func (s *Server) createWorkout() gin.HandlerFunc {
-	var req struct {
-		Workout struct {
-			Name          string    `json:"name"`
-			ScheduledDate time.Time `json:"scheduled_date"`
-			Exercises     []string  `json:"exercises"`
-		} `json:"workout"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			Workout struct {
+				Name          string    `json:"name"`
+				ScheduledDate time.Time `json:"scheduled_date"`
+				Exercises     []string  `json:"exercises"`
+			} `json:"workout"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -35,34 +36,26 @@ func (s *Server) createWorkout() gin.HandlerFunc {
 			return
 		}
 
-		exercises := make([]*fwt.Exercise, 0)
-		for _, exName := range req.Workout.Exercises {
-			exercise, err := s.ExerciseService.FindExerciseByName(c.Request.Context(), exName)
-			if err != nil {
-				if fwt.ErrorCode(err) == fwt.ENOTFOUND {
-					c.JSON(http.StatusNotFound, gin.H{
-						"error": fwt.ErrorMessage(err),
-					})
-					return
-				}
-				log.Printf("error in create workout handler: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal Server Error",
-				})
-				return
-			}
-
-			exercises = append(exercises, exercise)
-		}
-
 		newWorkout := fwt.Workout{
 			UserID:        user.ID,
 			Name:          req.Workout.Name,
 			ScheduledDate: req.Workout.ScheduledDate,
-			Exercises:     exercises,
+			Exercises:     make([]*fwt.Exercise, 0),
 		}
 
-		if err := s.WorkoutService.CreateWorkout(c.Request.Context(), &newWorkout); err != nil {
+		err := s.TxRunner.RunInTx(c.Request.Context(), func(ctx context.Context) error {
+			for _, exName := range req.Workout.Exercises {
+				exercise, err := s.ExerciseService.FindExerciseByName(ctx, exName)
+				if err != nil {
+					return err
+				}
+
+				newWorkout.Exercises = append(newWorkout.Exercises, exercise)
+			}
+
+			return s.WorkoutService.CreateWorkout(ctx, &newWorkout)
+		})
+		if err != nil {
 			if fwt.ErrorCode(err) == fwt.EINVALID {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"error": fwt.ErrorMessage(err),
@@ -84,13 +77,183 @@ func (s *Server) createWorkout() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in create workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in create workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
+		metrics.WorkoutsCreatedTotal.Inc()
+
+		c.JSON(http.StatusCreated, gin.H{
+			"workout": newWorkout,
+		})
+	}
+}
+
+// logWorkout records a workout that already happened, together with the
+// status and sets of each of its exercises.
+func (s *Server) logWorkout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Workout struct {
+				Name          string    `json:"name"`
+				ScheduledDate time.Time `json:"scheduled_date"`
+				StartedAt     time.Time `json:"started_at"`
+				FinishedAt    time.Time `json:"finished_at"`
+				Exercises     []struct {
+					Name        string     `json:"name"`
+					Status      string     `json:"status"`
+					Comments    string     `json:"comments"`
+					CompletedAt time.Time  `json:"completed_at"`
+					Sets        []*fwt.Set `json:"sets"`
+				} `json:"exercises"`
+			} `json:"workout"`
+			Unit string `json:"unit"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		units, ok := s.requestUnits(c, req.Unit)
+		if !ok {
+			return
+		}
+
+		newWorkout := fwt.Workout{
+			UserID:        user.ID,
+			Name:          req.Workout.Name,
+			ScheduledDate: req.Workout.ScheduledDate,
+			StartedAt:     req.Workout.StartedAt,
+			FinishedAt:    req.Workout.FinishedAt,
+			Exercises:     make([]*fwt.Exercise, 0, len(req.Workout.Exercises)),
+		}
+		for _, ex := range req.Workout.Exercises {
+			for _, set := range ex.Sets {
+				set.Load = units.Weight.ToKilograms(set.Load)
+			}
+			exercise := &fwt.Exercise{Name: ex.Name, Sets: ex.Sets}
+			if ex.Status != "" {
+				exercise.Status = &fwt.WEStatus{Status: ex.Status, Comments: ex.Comments, CompletedAt: ex.CompletedAt}
+			}
+			newWorkout.Exercises = append(newWorkout.Exercises, exercise)
+		}
+
+		if err := s.WorkoutService.LogWorkout(c.Request.Context(), &newWorkout); err != nil {
+			switch fwt.ErrorCode(err) {
+			case fwt.EINVALID:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTFOUND:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTAUTHORIZED:
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			default:
+				s.Logger.ErrorContext(c.Request.Context(), "error in log workout handler", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
+			}
+			return
+		}
+
+		metrics.WorkoutsCreatedTotal.Inc()
+		for _, ex := range newWorkout.Exercises {
+			if ex.Status != nil && ex.Status.Status == fwt.WEStatusCompleted {
+				metrics.ExercisesCompletedTotal.Inc()
+			}
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"workout": workoutInUnits(&newWorkout, units),
+			"units":   units,
+		})
+	}
+}
+
+// quickStartWorkout creates a workout for today and starts it right away.
+// The body is optional; without a name the workout is called "Quick
+// workout" and exercises can be added once it is under way.
+func (s *Server) quickStartWorkout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Workout struct {
+				Name      string   `json:"name"`
+				Exercises []string `json:"exercises"`
+			} `json:"workout"`
+		}
+
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		newWorkout := fwt.Workout{
+			UserID:    user.ID,
+			Name:      req.Workout.Name,
+			Exercises: make([]*fwt.Exercise, 0, len(req.Workout.Exercises)),
+		}
+		for _, name := range req.Workout.Exercises {
+			newWorkout.Exercises = append(newWorkout.Exercises, &fwt.Exercise{Name: name})
+		}
+
+		if err := s.WorkoutService.QuickStartWorkout(c.Request.Context(), &newWorkout); err != nil {
+			switch fwt.ErrorCode(err) {
+			case fwt.EINVALID:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTFOUND:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTAUTHORIZED:
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			default:
+				s.Logger.ErrorContext(c.Request.Context(), "error in quick start workout handler", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
+			}
+			return
+		}
+
+		metrics.WorkoutsCreatedTotal.Inc()
+
+		// Quick started workouts are returned as stored, in metric units.
+		c.Header("ETag", etag(newWorkout.Version, fwt.MetricUnits))
 		c.JSON(http.StatusCreated, gin.H{
 			"workout": newWorkout,
 		})
@@ -107,9 +270,14 @@ func (s *Server) getAllWorkouts() gin.HandlerFunc {
 			return
 		}
 
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
 		workouts, n, err := s.WorkoutService.FindWorkouts(c.Request.Context(), fwt.WorkoutFilter{UserID: &user.ID})
 		if err != nil {
-			log.Printf("error in get all workouts workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in get all workouts workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -118,7 +286,8 @@ func (s *Server) getAllWorkouts() gin.HandlerFunc {
 
 		c.JSON(http.StatusOK, gin.H{
 			"count":    n,
-			"workouts": workouts,
+			"workouts": workoutsInUnits(workouts, units),
+			"units":    units,
 		})
 	}
 }
@@ -142,6 +311,11 @@ func (s *Server) getOneWorkout() gin.HandlerFunc {
 			return
 		}
 
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
 		workoutID2 := uint(workoutID)
 		workout, err := s.WorkoutService.FindWorkoutByIDUserID(c.Request.Context(), workoutID2, user.ID)
 		if err != nil {
@@ -151,28 +325,35 @@ func (s *Server) getOneWorkout() gin.HandlerFunc {
 				})
 				return
 			}
-			log.Printf("error in create workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in create workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
+		c.Header("ETag", etag(workout.Version, units))
+		if v := c.GetHeader("If-None-Match"); v != "" && etagMatches(v, workout.Version, units) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"workout": workout,
+			"workout": workoutInUnits(workout, units),
+			"units":   units,
 		})
 	}
 }
 
 func (s *Server) updateWorkout() gin.HandlerFunc {
-	var req struct {
-		Workout struct {
-			Name          string    `json:"name"`
-			ScheduledDate time.Time `json:"scheduled_date"`
-		} `json:"workout"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			Workout struct {
+				Name          string    `json:"name"`
+				ScheduledDate time.Time `json:"scheduled_date"`
+			} `json:"workout"`
+		}
+
 		workoutIDstr := c.Param("id")
 		workoutID, err := strconv.ParseUint(workoutIDstr, 10, 64)
 		if err != nil {
@@ -197,6 +378,11 @@ func (s *Server) updateWorkout() gin.HandlerFunc {
 			return
 		}
 
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
 		upd := fwt.WorkoutUpdate{}
 		if req.Workout.Name != "" {
 			upd.Name = &req.Workout.Name
@@ -205,8 +391,25 @@ func (s *Server) updateWorkout() gin.HandlerFunc {
 			upd.ScheduledDate = &req.Workout.ScheduledDate
 		}
 
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{
+				"error": "Invalid If-Match header",
+			})
+			return
+		}
+
+		upd.Version = version
+
 		workout, err := s.WorkoutService.UpdateWorkout(c.Request.Context(), uint(workoutID), upd)
 		if err != nil {
+			if fwt.ErrorCode(err) == fwt.ESTALE {
+				c.JSON(http.StatusPreconditionFailed, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
 			if fwt.ErrorCode(err) == fwt.ENOTAUTHORIZED {
 				c.JSON(http.StatusUnauthorized, gin.H{
 					"error": fwt.ErrorMessage(err),
@@ -214,26 +417,28 @@ func (s *Server) updateWorkout() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in update workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in update workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
+		c.Header("ETag", etag(workout.Version, units))
 		c.JSON(http.StatusOK, gin.H{
 			"message": "workout updated successfully",
-			"workout": workout,
+			"workout": workoutInUnits(workout, units),
+			"units":   units,
 		})
 	}
 }
 
 func (s *Server) removeExercisesFromWorkout() gin.HandlerFunc {
-	var req struct {
-		Exercises []string `json:"exercises"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			Exercises []string `json:"exercises"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -258,7 +463,7 @@ func (s *Server) removeExercisesFromWorkout() gin.HandlerFunc {
 				})
 				return
 			}
-			log.Printf("error in remove exercises from workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in remove exercises from workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -271,6 +476,11 @@ func (s *Server) removeExercisesFromWorkout() gin.HandlerFunc {
 			return
 		}
 
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
 		workout, err := s.WorkoutService.RemoveExercisesFromWorkout(c.Request.Context(), w.ID, req.Exercises)
 		if err != nil {
 			if fwt.ErrorCode(err) == fwt.ENOTFOUND {
@@ -287,7 +497,7 @@ func (s *Server) removeExercisesFromWorkout() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in remove exercises from workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in remove exercises from workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -295,17 +505,18 @@ func (s *Server) removeExercisesFromWorkout() gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"workout": workout,
+			"workout": workoutInUnits(workout, units),
+			"units":   units,
 		})
 	}
 }
 
 func (s *Server) addExercisesToWorkout() gin.HandlerFunc {
-	var req struct {
-		Exercises []string `json:"exercises"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			Exercises []string `json:"exercises"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -322,6 +533,11 @@ func (s *Server) addExercisesToWorkout() gin.HandlerFunc {
 			return
 		}
 
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
 		workout, err := s.WorkoutService.AddExercisesToWorkout(c.Request.Context(), uint(workoutID), req.Exercises)
 		if err != nil {
 			if fwt.ErrorCode(err) == fwt.ENOTFOUND {
@@ -338,7 +554,7 @@ func (s *Server) addExercisesToWorkout() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in add exercises to workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in add exercises to workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -346,21 +562,22 @@ func (s *Server) addExercisesToWorkout() gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"workout": workout,
+			"workout": workoutInUnits(workout, units),
+			"units":   units,
 		})
 	}
 }
 
 func (s *Server) updateWorkoutExerciseStatus() gin.HandlerFunc {
-	var req struct {
-		WEStatus struct {
-			Status      string    `json:"status"`
-			Comments    string    `json:"comments"`
-			CompletedAt time.Time `json:"completed_at"`
-		} `json:"westatus"`
-	}
-
 	return func(c *gin.Context) {
+		var req struct {
+			WEStatus struct {
+				Status      string    `json:"status"`
+				Comments    string    `json:"comments"`
+				CompletedAt time.Time `json:"completed_at"`
+			} `json:"westatus"`
+		}
+
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
@@ -377,6 +594,15 @@ func (s *Server) updateWorkoutExerciseStatus() gin.HandlerFunc {
 			return
 		}
 
+		weIDstr := c.Param("weid")
+		weID, err := strconv.ParseUint(weIDstr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid workout exercise id param",
+			})
+			return
+		}
+
 		user := fwt.UserFromContext(c.Request.Context())
 		if user == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -385,10 +611,43 @@ func (s *Server) updateWorkoutExerciseStatus() gin.HandlerFunc {
 			return
 		}
 
-		_, err = s.WorkoutService.FindWorkoutByIDUserID(c.Request.Context(), uint(workoutID), user.ID)
+		upd := fwt.WEStatusUpdate{}
+		if req.WEStatus.Status != "" {
+			upd.Status = &req.WEStatus.Status
+		}
+		if req.WEStatus.Comments != "" {
+			upd.Comments = &req.WEStatus.Comments
+		}
+		if !req.WEStatus.CompletedAt.IsZero() {
+			upd.CompletedAt = &req.WEStatus.CompletedAt
+		}
+
+		// The ownership checks and the update run as one unit of work so the
+		// workout exercise cannot be removed in between. It has no other
+		// effects, so it is safe to retry.
+		var wes, updwes *fwt.WEStatus
+		err = s.TxRunner.RunInTxOptions(c.Request.Context(), fwt.TxOptions{Retry: true}, func(ctx context.Context) error {
+			if _, err := s.WorkoutService.FindWorkoutByIDUserID(ctx, uint(workoutID), user.ID); err != nil {
+				return err
+			}
+
+			we, err := s.WorkoutExerciseService.FindWorkoutExerciseByID(ctx, uint(weID))
+			if err != nil {
+				return err
+			} else if we.WorkoutID != uint(workoutID) {
+				return fwt.Errorf(fwt.ENOTFOUND, "Workout Exercise not found.")
+			}
+
+			if wes, err = s.WEStatusService.FindWEStatusByWEID(ctx, we.ID); err != nil {
+				return err
+			}
+
+			updwes, err = s.WEStatusService.UpdateWEStatus(ctx, wes.ID, upd)
+			return err
+		})
 		if err != nil {
 			if fwt.ErrorCode(err) == fwt.EINVALID {
-				c.JSON(http.StatusUnauthorized, gin.H{
+				c.JSON(http.StatusBadRequest, gin.H{
 					"error": fwt.ErrorMessage(err),
 				})
 				return
@@ -401,91 +660,79 @@ func (s *Server) updateWorkoutExerciseStatus() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in update workout exercise status: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in update workout exercise status", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
 			return
 		}
 
-		weIDstr := c.Param("weid")
-		weID, err := strconv.ParseUint(weIDstr, 10, 64)
+		if wes.Status != fwt.WEStatusCompleted && updwes.Status == fwt.WEStatusCompleted {
+			metrics.ExercisesCompletedTotal.Inc()
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "workout exercise status updated successfully",
+			"wes":     updwes,
+		})
+	}
+}
+
+// transitionWorkout returns a handler that moves a workout through its
+// lifecycle with transition, e.g. fwt.WorkoutService.StartWorkout.
+func (s *Server) transitionWorkout(action string, transition func(fwt.WorkoutService, context.Context, uint) (*fwt.Workout, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workoutIDstr := c.Param("id")
+		workoutID, err := strconv.ParseUint(workoutIDstr, 10, 64)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid workout exercise id param",
+				"error": "Invalid workout id param",
 			})
 			return
 		}
 
-		we, err := s.WorkoutExerciseService.FindWorkoutExerciseByID(c.Request.Context(), uint(weID))
-		if err != nil {
-			if fwt.ErrorCode(err) == fwt.EINVALID {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"error": fwt.ErrorMessage(err),
-				})
-				return
-			}
-
-			if fwt.ErrorCode(err) == fwt.ENOTFOUND {
-				c.JSON(http.StatusNotFound, gin.H{
-					"error": fwt.ErrorMessage(err),
-				})
-				return
-			}
-
-			log.Printf("error in update workout exercise status: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal Server Error",
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
 			})
 			return
 		}
 
-		wes, err := s.WEStatusService.FindWEStatusByWEID(c.Request.Context(), we.ID)
+		units, ok := s.requestUnits(c, "")
+		if !ok {
+			return
+		}
+
+		workout, err := transition(s.WorkoutService, c.Request.Context(), uint(workoutID))
 		if err != nil {
-			if fwt.ErrorCode(err) == fwt.EINVALID {
+			switch fwt.ErrorCode(err) {
+			case fwt.ENOTFOUND:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+			case fwt.ENOTAUTHORIZED:
 				c.JSON(http.StatusUnauthorized, gin.H{
 					"error": fwt.ErrorMessage(err),
 				})
-				return
-			}
-
-			if fwt.ErrorCode(err) == fwt.ENOTFOUND {
-				c.JSON(http.StatusNotFound, gin.H{
+			case fwt.ECONFLICT:
+				c.JSON(http.StatusConflict, gin.H{
 					"error": fwt.ErrorMessage(err),
 				})
-				return
+			default:
+				s.Logger.ErrorContext(c.Request.Context(), "error in transition workout handler", "action", action, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal Server Error",
+				})
 			}
-
-			log.Printf("error in update workout exercise status: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal Server Error",
-			})
-			return
-		}
-
-		upd := fwt.WEStatusUpdate{}
-		if req.WEStatus.Status != "" {
-			upd.Status = &req.WEStatus.Status
-		}
-		if req.WEStatus.Comments != "" {
-			upd.Comments = &req.WEStatus.Comments
-		}
-		if !req.WEStatus.CompletedAt.IsZero() {
-			upd.CompletedAt = &req.WEStatus.CompletedAt
-		}
-
-		updwes, err := s.WEStatusService.UpdateWEStatus(c.Request.Context(), wes.ID, upd)
-		if err != nil {
-			log.Printf("error in update workout exercise status: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal Server Error",
-			})
 			return
 		}
 
+		c.Header("ETag", etag(workout.Version, units))
 		c.JSON(http.StatusOK, gin.H{
-			"message": "workout exercise status updated successfully",
-			"wes":     updwes,
+			"message": "workout " + action + " successfully",
+			"workout": workoutInUnits(workout, units),
+			"units":   units,
 		})
 	}
 }
@@ -501,8 +748,31 @@ func (s *Server) deleteWorkout() gin.HandlerFunc {
 			return
 		}
 
-		err = s.WorkoutService.DeleteWorkout(c.Request.Context(), uint(workoutID))
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{
+				"error": "Invalid If-Match header",
+			})
+			return
+		}
+
+		user := fwt.UserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		err = s.WorkoutService.DeleteWorkout(c.Request.Context(), uint(workoutID), version)
 		if err != nil {
+			if fwt.ErrorCode(err) == fwt.ESTALE {
+				c.JSON(http.StatusPreconditionFailed, gin.H{
+					"error": fwt.ErrorMessage(err),
+				})
+				return
+			}
+
 			if fwt.ErrorCode(err) == fwt.ENOTFOUND {
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": fwt.ErrorMessage(err),
@@ -517,7 +787,7 @@ func (s *Server) deleteWorkout() gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("error in delete workout handler: %v", err)
+			s.Logger.ErrorContext(c.Request.Context(), "error in delete workout handler", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal Server Error",
 			})
@@ -0,0 +1,627 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+	fwthttp "github.com/maliByatzes/fwt/http"
+	"github.com/maliByatzes/fwt/metrics"
+	"github.com/maliByatzes/fwt/mock"
+	"github.com/maliByatzes/fwt/token"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkoutHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+
+	workout := s.MustCreateWorkout(t, jane, "Push-up", "Squat")
+	path := fmt.Sprintf("/api/v1/workout/%d", workout.ID)
+
+	wes, _, err := s.WorkoutExerciseService.FindWorkoutExercises(context.Background(), fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID})
+	require.NoError(t, err)
+	statusPath := fmt.Sprintf("/api/v1/workout/status/%d/%d", workout.ID, wes[0].ID)
+	other := s.MustCreateWorkout(t, jane, "Lunges")
+
+	scheduled := time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+
+	exercises := func(body map[string]any) []any {
+		return body["workout"].(map[string]any)["exercises"].([]any)
+	}
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/create",
+			token:  janeToken,
+			body:   `{"workout":{"name":"Upper body","scheduled_date":"` + scheduled + `","exercises":["Push-up","Bench Press"]}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, "Upper body", body["workout"].(map[string]any)["name"])
+				require.Len(t, exercises(body), 2)
+			},
+		},
+		{
+			name:   "Create/ErrUnknownExercise",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/create",
+			token:  janeToken,
+			body:   `{"workout":{"name":"Upper body","scheduled_date":"` + scheduled + `","exercises":["Flying"]}}`,
+			status: http.StatusNotFound,
+			error:  "Exercise not found.",
+		},
+		{
+			name:   "Create/ErrPastDate",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/create",
+			token:  janeToken,
+			body:   `{"workout":{"name":"Upper body","scheduled_date":"` + past + `","exercises":["Push-up"]}}`,
+			status: http.StatusBadRequest,
+			error:  "Scheduled Date is invalid.",
+		},
+		{
+			name:   "Create/ErrNoExercises",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/create",
+			token:  janeToken,
+			body:   `{"workout":{"name":"Upper body","scheduled_date":"` + scheduled + `"}}`,
+			status: http.StatusBadRequest,
+			error:  "Exercises must contain at least 1 exercise.",
+		},
+		{
+			name:   "Create/ErrNoToken",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/create",
+			body:   `{"workout":{"name":"Upper body","scheduled_date":"` + scheduled + `","exercises":["Push-up"]}}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "All",
+			method: http.MethodGet,
+			path:   "/api/v1/workout/all",
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(3), body["count"])
+			},
+		},
+		{
+			name:   "All/OtherUser",
+			method: http.MethodGet,
+			path:   "/api/v1/workout/all",
+			token:  johnToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, float64(0), body["count"])
+				require.Empty(t, body["workouts"])
+			},
+		},
+		{
+			name:       "Get",
+			method:     http.MethodGet,
+			path:       path,
+			token:      janeToken,
+			status:     http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"1-kg-cm-km"`},
+			check: func(t *testing.T, body map[string]any) {
+				require.Len(t, exercises(body), 2)
+			},
+		},
+		{
+			name:   "Get/NotModified",
+			method: http.MethodGet,
+			path:   path,
+			token:  janeToken,
+			header: map[string]string{"If-None-Match": `"1-kg-cm-km"`},
+			status: http.StatusNotModified,
+		},
+		{
+			name:       "Get/OtherUnits",
+			method:     http.MethodGet,
+			path:       path + "?unit=lb",
+			token:      janeToken,
+			header:     map[string]string{"If-None-Match": `"1-kg-cm-km"`},
+			status:     http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"1-lb-cm-km"`},
+		},
+		{
+			name:   "Get/ErrInvalidID",
+			method: http.MethodGet,
+			path:   "/api/v1/workout/abc",
+			token:  janeToken,
+			status: http.StatusBadRequest,
+			error:  "Invalid workout id param",
+		},
+		{
+			name:   "Get/ErrOtherUser",
+			method: http.MethodGet,
+			path:   path,
+			token:  johnToken,
+			status: http.StatusNotFound,
+			error:  "Workout not found.",
+		},
+		{
+			name:   "Get/ErrNotFound",
+			method: http.MethodGet,
+			path:   "/api/v1/workout/999",
+			token:  janeToken,
+			status: http.StatusNotFound,
+			error:  "Workout not found.",
+		},
+		{
+			name:   "Update/ErrOtherUser",
+			method: http.MethodPatch,
+			path:   path,
+			token:  johnToken,
+			body:   `{"workout":{"name":"Mine now"}}`,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to update this workout.",
+		},
+		{
+			name:   "Update/ErrStale",
+			method: http.MethodPatch,
+			path:   path,
+			token:  janeToken,
+			header: map[string]string{"If-Match": `"5"`},
+			body:   `{"workout":{"name":"Legs"}}`,
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "Update",
+			method:     http.MethodPatch,
+			path:       path,
+			token:      janeToken,
+			header:     map[string]string{"If-Match": `"1-kg-cm-km"`},
+			body:       `{"workout":{"name":"Legs"}}`,
+			status:     http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"2-kg-cm-km"`},
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, "Legs", body["workout"].(map[string]any)["name"])
+			},
+		},
+		{
+			name:   "AddExercises",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/exercises/add/%d", workout.ID),
+			token:  janeToken,
+			body:   `{"exercises":["Deadlift","Squat"]}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Len(t, exercises(body), 3)
+			},
+		},
+		{
+			name:   "AddExercises/ErrUnknownExercise",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/exercises/add/%d", workout.ID),
+			token:  janeToken,
+			body:   `{"exercises":["Flying"]}`,
+			status: http.StatusNotFound,
+			error:  "Exercise not found.",
+		},
+		{
+			name:   "AddExercises/ErrOtherUser",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/exercises/add/%d", workout.ID),
+			token:  johnToken,
+			body:   `{"exercises":["Lunges"]}`,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to modify this workout.",
+		},
+		{
+			name:   "RemoveExercises/ErrNoneLeft",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/exercises/remove/%d", workout.ID),
+			token:  janeToken,
+			body:   `{"exercises":["Push-up","Squat","Deadlift"]}`,
+			status: http.StatusBadRequest,
+			error:  "There must be at least one exercise remaining in the workout.",
+		},
+		{
+			name:   "RemoveExercises/ErrOtherUser",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/exercises/remove/%d", workout.ID),
+			token:  johnToken,
+			body:   `{"exercises":["Squat"]}`,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to modify this workout.",
+		},
+		{
+			name:   "RemoveExercises",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/exercises/remove/%d", workout.ID),
+			token:  janeToken,
+			body:   `{"exercises":["Squat"]}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Len(t, exercises(body), 2)
+			},
+		},
+		{
+			name:   "UpdateStatus",
+			method: http.MethodPatch,
+			path:   statusPath,
+			token:  janeToken,
+			body:   `{"westatus":{"status":"completed","comments":"Easy"}}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				require.Equal(t, "completed", body["wes"].(map[string]any)["status"])
+				require.Equal(t, "Easy", body["wes"].(map[string]any)["comments"])
+			},
+		},
+		{
+			name:   "UpdateStatus/ErrOtherUser",
+			method: http.MethodPatch,
+			path:   statusPath,
+			token:  johnToken,
+			body:   `{"westatus":{"status":"pending"}}`,
+			status: http.StatusNotFound,
+			error:  "Workout not found.",
+		},
+		{
+			name:   "UpdateStatus/ErrOtherWorkout",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/status/%d/%d", other.ID, wes[0].ID),
+			token:  janeToken,
+			body:   `{"westatus":{"status":"pending"}}`,
+			status: http.StatusNotFound,
+			error:  "Workout Exercise not found.",
+		},
+		{
+			name:   "UpdateStatus/ErrNotFound",
+			method: http.MethodPatch,
+			path:   fmt.Sprintf("/api/v1/workout/status/%d/999", workout.ID),
+			token:  janeToken,
+			body:   `{"westatus":{"status":"pending"}}`,
+			status: http.StatusNotFound,
+			error:  "Workout Exercise not found.",
+		},
+		{
+			name:   "Delete/ErrOtherUser",
+			method: http.MethodDelete,
+			path:   path,
+			token:  johnToken,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to delete this workout.",
+		},
+		{
+			name:   "Delete/ErrStale",
+			method: http.MethodDelete,
+			path:   path,
+			token:  janeToken,
+			header: map[string]string{"If-Match": `"1"`},
+			status: http.StatusPreconditionFailed,
+			error:  "Workout has been modified since it was last fetched.",
+		},
+		{
+			name:   "Delete",
+			method: http.MethodDelete,
+			path:   path,
+			token:  janeToken,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Get/ErrDeleted",
+			method: http.MethodGet,
+			path:   path,
+			token:  janeToken,
+			status: http.StatusNotFound,
+		},
+	})
+}
+
+func TestWorkoutLifecycleHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	jane, janeToken := s.MustCreateUser(t, "janedoe")
+	_, johnToken := s.MustCreateUser(t, "johndoe")
+
+	workout := s.MustCreateWorkout(t, jane, "Push-up", "Squat")
+	skipped := s.MustCreateWorkout(t, jane, "Lunges")
+	path := func(id uint, action string) string {
+		return fmt.Sprintf("/api/v1/workout/%d/%s", id, action)
+	}
+
+	status := func(want string) func(t *testing.T, body map[string]any) {
+		return func(t *testing.T, body map[string]any) {
+			require.Equal(t, want, body["workout"].(map[string]any)["status"])
+		}
+	}
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Start/ErrOtherUser",
+			method: http.MethodPost,
+			path:   path(workout.ID, "start"),
+			token:  johnToken,
+			status: http.StatusUnauthorized,
+			error:  "You are not allowed to update this workout.",
+		},
+		{
+			name:   "Start/ErrInvalidID",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/abc/start",
+			token:  janeToken,
+			status: http.StatusBadRequest,
+			error:  "Invalid workout id param",
+		},
+		{
+			name:   "Start/ErrNotFound",
+			method: http.MethodPost,
+			path:   path(1<<30, "start"),
+			token:  janeToken,
+			status: http.StatusNotFound,
+			error:  "Workout not found.",
+		},
+		{
+			name:   "Start",
+			method: http.MethodPost,
+			path:   path(workout.ID, "start"),
+			token:  janeToken,
+			status: http.StatusOK,
+			check:  status(fwt.WorkoutStatusInProgress),
+		},
+		{
+			name:   "Start/ErrAlreadyStarted",
+			method: http.MethodPost,
+			path:   path(workout.ID, "start"),
+			token:  janeToken,
+			status: http.StatusConflict,
+			error:  "Workout cannot be moved from in_progress to in_progress.",
+		},
+		{
+			name:   "Finish",
+			method: http.MethodPost,
+			path:   path(workout.ID, "finish"),
+			token:  janeToken,
+			status: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				status(fwt.WorkoutStatusPartiallyCompleted)(t, body)
+				require.Contains(t, body["workout"], "duration_seconds")
+			},
+		},
+		{
+			name:   "Skip",
+			method: http.MethodPost,
+			path:   path(skipped.ID, "skip"),
+			token:  janeToken,
+			status: http.StatusOK,
+			check:  status(fwt.WorkoutStatusSkipped),
+		},
+		{
+			name:   "Finish/ErrSkipped",
+			method: http.MethodPost,
+			path:   path(skipped.ID, "finish"),
+			token:  janeToken,
+			status: http.StatusConflict,
+			error:  "Workout cannot be moved from skipped to partially_completed.",
+		},
+	})
+}
+
+func TestLoggedWorkoutHandlers(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	_, janeToken := s.MustCreateUser(t, "janedoe")
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.RFC3339)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 2).Format(time.RFC3339)
+	completed := testutil.ToFloat64(metrics.ExercisesCompletedTotal)
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "Log",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/log",
+			token:  janeToken,
+			body: `{"workout": {"name": "Morning run", "scheduled_date": "` + yesterday + `", "exercises": [
+				{"name": "Squat", "sets": [{"reps": 5, "load": 100}, {"reps": 5, "load": 100}]},
+				{"name": "Lunges", "status": "skipped"}
+			]}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				workout := body["workout"].(map[string]any)
+				require.Equal(t, fwt.WorkoutStatusPartiallyCompleted, workout["status"])
+
+				exercises := workout["exercises"].([]any)
+				require.Len(t, exercises, 2)
+				squat := exercises[0].(map[string]any)
+				require.Equal(t, fwt.WEStatusCompleted, squat["status"].(map[string]any)["status"])
+				require.Len(t, squat["sets"], 2)
+
+				// Only the squat counts, as the lunges were skipped.
+				require.Equal(t, completed+1, testutil.ToFloat64(metrics.ExercisesCompletedTotal))
+			},
+		},
+		{
+			name:   "Log/ErrFuture",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/log",
+			token:  janeToken,
+			body:   `{"workout": {"name": "Later", "scheduled_date": "` + tomorrow + `", "exercises": [{"name": "Squat"}]}}`,
+			status: http.StatusBadRequest,
+			error:  "Scheduled Date of a logged workout cannot be in the future.",
+		},
+		{
+			name:   "Log/ErrExerciseNotFound",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/log",
+			token:  janeToken,
+			body:   `{"workout": {"name": "Odd", "scheduled_date": "` + yesterday + `", "exercises": [{"name": "Juggling"}]}}`,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "Log/ErrUnauthenticated",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/log",
+			body:   `{"workout": {}}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "QuickStart",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/quick-start",
+			token:  janeToken,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				workout := body["workout"].(map[string]any)
+				require.Equal(t, fwt.WorkoutStatusInProgress, workout["status"])
+				require.Equal(t, "Quick workout", workout["name"])
+				require.Empty(t, workout["exercises"])
+			},
+		},
+		{
+			name:   "QuickStart/WithExercises",
+			method: http.MethodPost,
+			path:   "/api/v1/workout/quick-start",
+			token:  janeToken,
+			body:   `{"workout": {"name": "Lunch break", "exercises": ["Push-up"]}}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, body map[string]any) {
+				workout := body["workout"].(map[string]any)
+				require.Equal(t, "Lunch break", workout["name"])
+				require.Len(t, workout["exercises"], 1)
+			},
+		},
+	})
+}
+
+// failingWorkoutService wraps a WorkoutService and fails every listing.
+type failingWorkoutService struct {
+	fwt.WorkoutService
+}
+
+func (s *failingWorkoutService) FindWorkouts(ctx context.Context, filter fwt.WorkoutFilter) ([]*fwt.Workout, int, error) {
+	return nil, 0, errors.New("connection reset")
+}
+
+func TestWorkoutHandlers_ErrInternal(t *testing.T) {
+	s := MustNewMemoryServer(t)
+	_, token := s.MustCreateUser(t, "janedoe")
+	s.WorkoutService = &failingWorkoutService{WorkoutService: s.WorkoutService}
+
+	runHandlerTests(t, s.Router, []handlerTest{
+		{
+			name:   "All",
+			method: http.MethodGet,
+			path:   "/api/v1/workout/all",
+			token:  token,
+			status: http.StatusInternalServerError,
+			error:  "Internal Server Error",
+		},
+	})
+}
+
+func TestWorkoutHandlers_Calls(t *testing.T) {
+	user := &fwt.User{ID: 1, Username: "janedoe"}
+	users := &mock.UserService{
+		FindUserbyIDFn: func(ctx context.Context, id uint) (*fwt.User, error) { return user, nil },
+	}
+	tokens := &mock.TokenMaker{
+		VerifyTokenFn: func(tok string) (*token.Payload, error) {
+			return &token.Payload{ID: user.ID, Username: user.Username}, nil
+		},
+	}
+	exercises := &mock.ExerciseService{
+		FindExerciseByNameFn: func(ctx context.Context, name string) (*fwt.Exercise, error) {
+			return &fwt.Exercise{Name: name}, nil
+		},
+	}
+	workouts := &mock.WorkoutService{
+		CreateWorkoutFn: func(ctx context.Context, workout *fwt.Workout) error {
+			workout.ID = 1
+			return nil
+		},
+	}
+
+	s := MustNewServer(t, fwthttp.Options{
+		UserService:     users,
+		ExerciseService: exercises,
+		WorkoutService:  workouts,
+		TokenMaker:      tokens,
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		scheduled := time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339)
+		w := doRequest(s.Router, http.MethodPost, "/api/v1/workout/create", "abc", `{"workout":{"name":"Legs","scheduled_date":"`+scheduled+`","exercises":["Squat","Lunges"]}}`, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		tokens.AssertCalled(t, "VerifyToken", "abc")
+		users.AssertCalled(t, "FindUserByID", user.ID)
+		exercises.AssertCallCount(t, "FindExerciseByName", 2)
+		exercises.AssertCalled(t, "FindExerciseByName", "Lunges")
+		workouts.AssertCallCount(t, "CreateWorkout", 1)
+	})
+
+	t.Run("ErrInvalidID", func(t *testing.T) {
+		workouts.Reset()
+
+		w := doRequest(s.Router, http.MethodDelete, "/api/v1/workout/abc", "abc", "", nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Empty(t, workouts.Calls())
+	})
+}
+
+func TestWorkoutHandlers_UnitOfWork(t *testing.T) {
+	type txKey struct{}
+	inTx := func(ctx context.Context) error {
+		if ctx.Value(txKey{}) == nil {
+			return errors.New("called outside of the unit of work")
+		}
+		return nil
+	}
+
+	user := &fwt.User{ID: 1, Username: "janedoe"}
+	tx := &mock.TxRunner{
+		RunInTxOptionsFn: func(ctx context.Context, opts fwt.TxOptions, fn func(ctx context.Context) error) error {
+			return fn(context.WithValue(ctx, txKey{}, true))
+		},
+	}
+	workouts := &mock.WorkoutService{
+		FindWorkoutByIDUserIDFn: func(ctx context.Context, id, userID uint) (*fwt.Workout, error) {
+			return &fwt.Workout{ID: id, UserID: userID}, inTx(ctx)
+		},
+	}
+	workoutExercises := &mock.WorkoutExerciseService{
+		FindWorkoutExerciseByIDFn: func(ctx context.Context, id uint) (*fwt.WorkoutExercise, error) {
+			return &fwt.WorkoutExercise{ID: id, WorkoutID: 1}, inTx(ctx)
+		},
+	}
+	statuses := &mock.WEStatusService{
+		FindWEStatusByWEIDFn: func(ctx context.Context, id uint) (*fwt.WEStatus, error) {
+			return &fwt.WEStatus{ID: 3, WorkoutExerciseID: id, Status: "pending"}, inTx(ctx)
+		},
+		UpdateWEStatusFn: func(ctx context.Context, id uint, upd fwt.WEStatusUpdate) (*fwt.WEStatus, error) {
+			return &fwt.WEStatus{ID: id, WorkoutExerciseID: 2, Status: *upd.Status}, inTx(ctx)
+		},
+	}
+
+	s := MustNewServer(t, fwthttp.Options{
+		UserService: &mock.UserService{
+			FindUserbyIDFn: func(ctx context.Context, id uint) (*fwt.User, error) { return user, nil },
+		},
+		WorkoutService:         workouts,
+		WorkoutExerciseService: workoutExercises,
+		WEStatusService:        statuses,
+		TxRunner:               tx,
+	})
+	token, _, err := s.TokenMaker.CreateToken(user.ID, user.Username, time.Minute)
+	require.NoError(t, err)
+
+	w := doRequest(s.Router, http.MethodPatch, "/api/v1/workout/status/1/2", token, `{"westatus":{"status":"completed"}}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	tx.AssertCalled(t, "RunInTxOptions", fwt.TxOptions{Retry: true})
+	statuses.AssertCalled(t, "UpdateWEStatus", uint(3), fwt.WEStatusUpdate{Status: ptr("completed")})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,65 @@
+package fwt
+
+import (
+	"context"
+	"time"
+)
+
+type IdempotencyKey struct {
+	ID             uint      `json:"id"`
+	UserID         uint      `json:"user_id"`
+	Key            string    `json:"key"`
+	RequestHash    string    `json:"request_hash"`
+	ResponseStatus int       `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+
+	// ResponseHeaders holds the headers set by the handler, such as ETag,
+	// which are sent again when the response is replayed.
+	ResponseHeaders map[string][]string `json:"response_headers"`
+}
+
+func (k *IdempotencyKey) Validate() error {
+	if k.UserID == uint(0) {
+		return Errorf(EINVALID, "UserID is required.")
+	}
+
+	if k.Key == "" {
+		return Errorf(EINVALID, "Key is required.")
+	}
+
+	if len(k.Key) > 255 {
+		return Errorf(EINVALID, "Key must be at most 255 characters.")
+	}
+
+	if k.RequestHash == "" {
+		return Errorf(EINVALID, "Request Hash is required.")
+	}
+
+	if k.ExpiresAt.IsZero() {
+		return Errorf(EINVALID, "Expires At is required.")
+	}
+
+	return nil
+}
+
+// Completed reports whether a response has been stored for the key. A key
+// without a response belongs to a request that is still being processed.
+func (k *IdempotencyKey) Completed() bool {
+	return k.ResponseStatus != 0
+}
+
+type IdempotencyService interface {
+	FindIdempotencyKey(ctx context.Context, userID uint, key string) (*IdempotencyKey, error)
+	CreateIdempotencyKey(ctx context.Context, key *IdempotencyKey) error
+	UpdateIdempotencyKey(ctx context.Context, id uint, upd IdempotencyKeyUpdate) (*IdempotencyKey, error)
+	DeleteIdempotencyKey(ctx context.Context, id uint) error
+}
+
+type IdempotencyKeyUpdate struct {
+	ResponseStatus  *int                `json:"response_status"`
+	ResponseBody    []byte              `json:"response_body"`
+	ResponseHeaders map[string][]string `json:"response_headers"`
+	ExpiresAt       *time.Time          `json:"expires_at"`
+}
@@ -0,0 +1,201 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.BodyMeasurementService = (*BodyMeasurementService)(nil)
+
+type BodyMeasurementService struct {
+	db *DB
+}
+
+func NewBodyMeasurementService(db *DB) *BodyMeasurementService {
+	return &BodyMeasurementService{db: db}
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurementByID(ctx context.Context, id uint) (*fwt.BodyMeasurement, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findBodyMeasurement(fwt.BodyMeasurementFilter{ID: &id})
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurements(ctx context.Context, filter fwt.BodyMeasurementFilter) ([]*fwt.BodyMeasurement, int, error) {
+	defer s.db.rlock(ctx)()
+
+	a, n := s.db.findBodyMeasurements(filter)
+	return a, n, nil
+}
+
+func (s *BodyMeasurementService) CreateBodyMeasurement(ctx context.Context, m *fwt.BodyMeasurement) error {
+	defer s.db.lock(ctx)()
+
+	if err := s.db.createBodyMeasurement(ctx, m); err != nil {
+		return err
+	}
+	s.db.syncProfileWeight(m.UserID)
+
+	return nil
+}
+
+func (s *BodyMeasurementService) UpdateBodyMeasurement(ctx context.Context, id uint, upd fwt.BodyMeasurementUpdate) (*fwt.BodyMeasurement, error) {
+	defer s.db.lock(ctx)()
+
+	m, err := s.db.findBodyMeasurement(fwt.BodyMeasurementFilter{ID: &id})
+	if err != nil {
+		return m, err
+	} else if m.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this body measurement.")
+	}
+
+	if v := upd.MeasuredAt; v != nil {
+		m.MeasuredAt = *v
+	}
+	if v := upd.Weight; v != nil {
+		m.Weight = *v
+	}
+	if v := upd.BodyFat; v != nil {
+		m.BodyFat = *v
+	}
+	if v := upd.Waist; v != nil {
+		m.Waist = *v
+	}
+	if v := upd.Chest; v != nil {
+		m.Chest = *v
+	}
+	if v := upd.Arms; v != nil {
+		m.Arms = *v
+	}
+	if v := upd.Thighs; v != nil {
+		m.Thighs = *v
+	}
+	if v := upd.RestingHeartRate; v != nil {
+		m.RestingHeartRate = *v
+	}
+	if v := upd.Notes; v != nil {
+		m.Notes = *v
+	}
+	m.UpdatedAt = s.db.now()
+
+	if err := m.Validate(); err != nil {
+		return m, err
+	}
+
+	other := *m
+	s.db.bodyMeasurements[other.ID] = &other
+	s.db.syncProfileWeight(m.UserID)
+
+	return m, nil
+}
+
+func (s *BodyMeasurementService) DeleteBodyMeasurement(ctx context.Context, id uint) error {
+	defer s.db.lock(ctx)()
+
+	m, err := s.db.findBodyMeasurement(fwt.BodyMeasurementFilter{ID: &id})
+	if err != nil {
+		return err
+	} else if m.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this body measurement.")
+	}
+
+	delete(s.db.bodyMeasurements, id)
+	s.db.syncProfileWeight(m.UserID)
+
+	return nil
+}
+
+func (db *DB) createBodyMeasurement(ctx context.Context, m *fwt.BodyMeasurement) error {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to record a body measurement.")
+	}
+	m.UserID = userID
+
+	if m.MeasuredAt.IsZero() {
+		m.MeasuredAt = db.now()
+	}
+	m.CreatedAt = db.now()
+	m.UpdatedAt = m.CreatedAt
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	m.ID = db.nextID("body_measurement")
+	other := *m
+	db.bodyMeasurements[other.ID] = &other
+
+	return nil
+}
+
+func (db *DB) findBodyMeasurement(filter fwt.BodyMeasurementFilter) (*fwt.BodyMeasurement, error) {
+	a, _ := db.findBodyMeasurements(filter)
+	if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Body Measurement not found."}
+	}
+	return a[0], nil
+}
+
+// findBodyMeasurements returns copies of the matching measurements, oldest
+// first.
+func (db *DB) findBodyMeasurements(filter fwt.BodyMeasurementFilter) ([]*fwt.BodyMeasurement, int) {
+	a := make([]*fwt.BodyMeasurement, 0)
+	for _, m := range sortedByID(db.bodyMeasurements) {
+		if v := filter.ID; v != nil && m.ID != *v {
+			continue
+		}
+		if v := filter.UserID; v != nil && m.UserID != *v {
+			continue
+		}
+		if v := filter.From; v != nil && m.MeasuredAt.Before(*v) {
+			continue
+		}
+		if v := filter.To; v != nil && m.MeasuredAt.After(*v) {
+			continue
+		}
+
+		other := *m
+		a = append(a, &other)
+	}
+	sort.SliceStable(a, func(i, j int) bool { return a[i].MeasuredAt.Before(a[j].MeasuredAt) })
+
+	return paginate(a, filter.Limit, filter.Offset)
+}
+
+// syncProfileWeight sets the weight of the user's profile, if any, to the
+// most recent measured weight, or zero when no weight has been measured.
+func (db *DB) syncProfileWeight(userID uint) {
+	weight := db.findLatestWeight(userID)
+	profile, err := db.findProfile(fwt.ProfileFilter{UserID: &userID})
+	if err != nil || profile.Weight == weight {
+		return
+	}
+	profile.Weight = weight
+	profile.UpdatedAt = db.now()
+	profile.Version++
+	db.profiles[profile.ID] = profile
+}
+
+// findLatestWeight returns the most recent measured weight of the user, or
+// zero when no weight has been measured.
+func (db *DB) findLatestWeight(userID uint) float64 {
+	a, _ := db.findBodyMeasurements(fwt.BodyMeasurementFilter{UserID: &userID})
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i].Weight > 0 {
+			return a[i].Weight
+		}
+	}
+	return 0
+}
+
+// recordProfileWeight adds a weight set through the profile to the body
+// measurement history, so that it is not lost when it changes again.
+func (db *DB) recordProfileWeight(ctx context.Context, weight float64) error {
+	if weight <= 0 {
+		return nil
+	}
+	return db.createBodyMeasurement(ctx, &fwt.BodyMeasurement{Weight: weight})
+}
@@ -0,0 +1,62 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.CalendarFeedService = (*CalendarFeedService)(nil)
+
+type CalendarFeedService struct {
+	db *DB
+}
+
+func NewCalendarFeedService(db *DB) *CalendarFeedService {
+	return &CalendarFeedService{db: db}
+}
+
+func (s *CalendarFeedService) FindCalendarFeedByToken(ctx context.Context, token string) (*fwt.CalendarFeed, error) {
+	defer s.db.rlock(ctx)()
+
+	hash := fwt.HashCalendarFeedToken(token)
+	for _, f := range s.db.calendarFeeds {
+		if f.TokenHash == hash {
+			other := *f
+			return &other, nil
+		}
+	}
+	return nil, fwt.Errorf(fwt.ENOTFOUND, "Calendar feed not found.")
+}
+
+func (s *CalendarFeedService) RotateCalendarFeed(ctx context.Context) (*fwt.CalendarFeed, error) {
+	defer s.db.lock(ctx)()
+
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to rotate a calendar feed.")
+	}
+
+	token, hash, err := fwt.NewCalendarFeedToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for id, f := range s.db.calendarFeeds {
+		if f.UserID == userID {
+			delete(s.db.calendarFeeds, id)
+		}
+	}
+
+	feed := &fwt.CalendarFeed{
+		ID:        s.db.nextID("calendar_feed"),
+		UserID:    userID,
+		TokenHash: hash,
+		CreatedAt: s.db.now(),
+	}
+	other := *feed
+	s.db.calendarFeeds[feed.ID] = &other
+
+	feed.Token = token
+	return feed, nil
+}
@@ -0,0 +1,83 @@
+package inmem
+
+import "github.com/maliByatzes/fwt"
+
+// DefaultExercises returns the exercise catalog that the SQL backends seed
+// through their migrations.
+func DefaultExercises() []*fwt.Exercise {
+	return []*fwt.Exercise{
+		{Name: "Push-up", Description: "A basic upper body exercise focusing on chest, shoulders, and triceps"},
+		{Name: "Squat", Description: "A compound lower-body exercise for quads, hamstrings, and glutes"},
+		{Name: "Lunges", Description: "A lower body exercise targeting the quads, hamstrings, and glutes"},
+		{Name: "Deadlift", Description: "A full-body exercise targeting the back, glutes, hamstrings, and core"},
+		{Name: "Bench Press", Description: "A chest exercise focusing on chest, shoulders, and triceps"},
+		{Name: "Overhead Press", Description: "A shoulder exercise focusing on deltoids and triceps"},
+		{Name: "Pull-up", Description: "An upper-body exercise focusing on the back, shoulders, and arms"},
+		{Name: "Chin-up", Description: "A variation of the pull-up focusing on biceps and back muscles"},
+		{Name: "Barbell Row", Description: "A back exercise targeting the lats and rhomboids"},
+		{Name: "Bicep Curl", Description: "An arm exercise focusing on the biceps"},
+		{Name: "Tricep Dips", Description: "An arm exercise focusing on the triceps"},
+		{Name: "Leg Press", Description: "A lower-body exercise targeting the quads, hamstrings, and glutes"},
+		{Name: "Lat Pulldown", Description: "A back exercise targeting the lats and shoulders"},
+		{Name: "Face Pull", Description: "A shoulder exercise focusing on rear deltoids and traps"},
+		{Name: "Dumbbell Fly", Description: "A chest exercise focusing on chest muscles"},
+		{Name: "Cable Fly", Description: "A variation of the fly focusing on chest muscles with cables"},
+		{Name: "Seated Row", Description: "A back exercise focusing on lats and rhomboids"},
+		{Name: "Incline Bench Press", Description: "A chest exercise targeting the upper chest and shoulders"},
+		{Name: "Decline Bench Press", Description: "A chest exercise targeting the lower chest and triceps"},
+		{Name: "Arnold Press", Description: "A shoulder exercise focusing on all three heads of the deltoid"},
+		{Name: "Hammer Curl", Description: "An arm exercise focusing on the biceps and brachialis"},
+		{Name: "Concentration Curl", Description: "An isolated bicep exercise for focused contraction"},
+		{Name: "Skull Crusher", Description: "A tricep exercise using a barbell or dumbbells"},
+		{Name: "Close-Grip Bench Press", Description: "A chest and triceps exercise focusing on triceps activation"},
+		{Name: "Sumo Deadlift", Description: "A deadlift variation focusing more on glutes and hamstrings"},
+		{Name: "Romanian Deadlift", Description: "A deadlift variation focusing on hamstrings and glutes"},
+		{Name: "Leg Curl", Description: "A machine exercise targeting the hamstrings"},
+		{Name: "Leg Extension", Description: "A machine exercise targeting the quads"},
+		{Name: "Calf Raise", Description: "A lower body exercise focusing on the calves"},
+		{Name: "Bulgarian Split Squat", Description: "A unilateral leg exercise focusing on quads and glutes"},
+		{Name: "Step-ups", Description: "A leg exercise focusing on quads, hamstrings, and glutes"},
+		{Name: "Hip Thrust", Description: "A glute-focused exercise using body weight or resistance"},
+		{Name: "Glute Bridge", Description: "A lower body exercise focusing on the glutes and hamstrings"},
+		{Name: "Pistol Squat", Description: "A unilateral bodyweight squat focusing on quads and glutes"},
+		{Name: "Box Jump", Description: "A plyometric leg exercise focusing on explosive strength"},
+		{Name: "Mountain Climbers", Description: "A cardio and core exercise using body weight"},
+		{Name: "Plank", Description: "A core stabilization exercise focusing on abs, back, and shoulders"},
+		{Name: "Side Plank", Description: "A variation of the plank focusing on obliques and core stability"},
+		{Name: "Russian Twist", Description: "A core exercise focusing on obliques"},
+		{Name: "Bicycle Crunch", Description: "A core exercise targeting abs and obliques"},
+		{Name: "Hanging Leg Raise", Description: "A core exercise focusing on lower abs"},
+		{Name: "V-Up", Description: "A full-body core exercise focusing on abs and hip flexors"},
+		{Name: "Reverse Crunch", Description: "A core exercise focusing on lower abs and hip flexors"},
+		{Name: "Flutter Kicks", Description: "A core exercise focusing on lower abs"},
+		{Name: "Superman", Description: "A lower-back exercise focusing on spinal erectors and glutes"},
+		{Name: "Bird Dog", Description: "A core and lower-back stabilization exercise"},
+		{Name: "Ab Wheel Rollout", Description: "A core exercise using an ab wheel to target abs and lower back"},
+		{Name: "Cable Crunch", Description: "A core exercise using cables to target the abs"},
+		{Name: "Tuck Jump", Description: "A plyometric exercise focusing on leg and core strength"},
+		{Name: "Burpees", Description: "A full-body cardio exercise combining squat, plank, and jump"},
+		{Name: "Jumping Jacks", Description: "A full-body cardio exercise using body weight"},
+		{Name: "High Knees", Description: "A cardio exercise focusing on hip flexors and quads"},
+		{Name: "Kettlebell Swing", Description: "A full-body exercise using kettlebell to target posterior chain"},
+		{Name: "Turkish Get-Up", Description: "A complex full-body exercise using a kettlebell for strength and stability"},
+		{Name: "Farmer’s Walk", Description: "A full-body exercise focusing on grip strength and core stability"},
+		{Name: "Renegade Row", Description: "A compound exercise combining plank and dumbbell rows"},
+		{Name: "Cable Row", Description: "A back exercise using cables to target lats and rhomboids"},
+		{Name: "Landmine Press", Description: "A shoulder and chest exercise using a barbell landmine attachment"},
+		{Name: "Landmine Squat", Description: "A lower-body exercise using a barbell landmine attachment"},
+		{Name: "Cable Lateral Raise", Description: "A shoulder isolation exercise focusing on deltoids"},
+		{Name: "Dumbbell Lateral Raise", Description: "A shoulder exercise focusing on the lateral head of the deltoid"},
+		{Name: "Dumbbell Front Raise", Description: "A shoulder exercise focusing on the front deltoid"},
+		{Name: "Rear Delt Fly", Description: "A shoulder exercise focusing on the posterior deltoid"},
+		{Name: "Shrug", Description: "An upper-body exercise focusing on the traps and shoulders"},
+		{Name: "Upright Row", Description: "A shoulder exercise focusing on deltoids and traps"},
+		{Name: "Pendlay Row", Description: "A back exercise focusing on lats and upper back"},
+		{Name: "Good Morning", Description: "A posterior chain exercise focusing on hamstrings and lower back"},
+		{Name: "Jefferson Curl", Description: "A flexibility and strength exercise for the spine and hamstrings"},
+		{Name: "Copenhagen Plank", Description: "A core exercise focusing on adductors and obliques"},
+		{Name: "Single-Leg Deadlift", Description: "A unilateral lower-body exercise focusing on hamstrings and balance"},
+		{Name: "Zercher Squat", Description: "A squat variation focusing on core, quads, and glutes"},
+		{Name: "Front Squat", Description: "A squat variation targeting quads and core strength"},
+		{Name: "Suitcase Carry", Description: "A unilateral full-body exercise focusing on grip and core stability"},
+	}
+}
@@ -0,0 +1,30 @@
+package inmem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt/fwttest"
+	"github.com/maliByatzes/fwt/inmem"
+)
+
+func TestConformance(t *testing.T) {
+	fwttest.TestServices(t, func(tb testing.TB) *fwttest.Services {
+		db := MustOpenDB(tb)
+
+		return &fwttest.Services{
+			UserService:            inmem.NewUserService(db),
+			ProfileService:         inmem.NewProfileService(db),
+			WorkoutService:         inmem.NewWorkoutService(db),
+			ExerciseService:        inmem.NewExerciseService(db),
+			WorkoutExerciseService: inmem.NewWorkoutExerciseService(db),
+			WEStatusService:        inmem.NewWEStatusService(db),
+			BodyMeasurementService: inmem.NewBodyMeasurementService(db),
+			GoalService:            inmem.NewGoalService(db),
+			CalendarFeedService:    inmem.NewCalendarFeedService(db),
+			IdempotencyService:     inmem.NewIdempotencyService(db),
+			TxRunner:               db,
+			SetNow:                 func(now func() time.Time) { db.Now = now },
+		}
+	})
+}
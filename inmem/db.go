@@ -0,0 +1,197 @@
+// Package inmem implements the fwt services on top of plain maps. It is
+// meant for tests and demos: nothing is persisted and all data is lost when
+// the process exits.
+package inmem
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.TxRunner = (*DB)(nil)
+
+// DB holds the state shared by all services. A single lock guards every
+// table so that each service call is atomic, like a database transaction.
+type DB struct {
+	mu  sync.RWMutex
+	Now func() time.Time
+
+	tables
+}
+
+// tables holds the stored rows. Rows are replaced rather than modified in
+// place, so a shallow copy of the maps is a consistent snapshot.
+type tables struct {
+	users            map[uint]*fwt.User
+	profiles         map[uint]*fwt.Profile
+	workouts         map[uint]*fwt.Workout
+	exercises        map[uint]*fwt.Exercise
+	workoutExercises map[uint]*fwt.WorkoutExercise
+	weStatuses       map[uint]*fwt.WEStatus
+	sets             map[uint]*fwt.Set
+	bodyMeasurements map[uint]*fwt.BodyMeasurement
+	goals            map[uint]*fwt.Goal
+	calendarFeeds    map[uint]*fwt.CalendarFeed
+	idempotencyKeys  map[uint]*fwt.IdempotencyKey
+
+	// seq holds the last ID handed out for each table.
+	seq map[string]uint
+}
+
+func NewDB() *DB {
+	return &DB{
+		Now: time.Now,
+		tables: tables{
+			users:            make(map[uint]*fwt.User),
+			profiles:         make(map[uint]*fwt.Profile),
+			workouts:         make(map[uint]*fwt.Workout),
+			exercises:        make(map[uint]*fwt.Exercise),
+			workoutExercises: make(map[uint]*fwt.WorkoutExercise),
+			weStatuses:       make(map[uint]*fwt.WEStatus),
+			sets:             make(map[uint]*fwt.Set),
+			bodyMeasurements: make(map[uint]*fwt.BodyMeasurement),
+			goals:            make(map[uint]*fwt.Goal),
+			calendarFeeds:    make(map[uint]*fwt.CalendarFeed),
+			idempotencyKeys:  make(map[uint]*fwt.IdempotencyKey),
+			seq:              make(map[string]uint),
+		},
+	}
+}
+
+func (t *tables) clone() tables {
+	return tables{
+		users:            maps.Clone(t.users),
+		profiles:         maps.Clone(t.profiles),
+		workouts:         maps.Clone(t.workouts),
+		exercises:        maps.Clone(t.exercises),
+		workoutExercises: maps.Clone(t.workoutExercises),
+		weStatuses:       maps.Clone(t.weStatuses),
+		sets:             maps.Clone(t.sets),
+		bodyMeasurements: maps.Clone(t.bodyMeasurements),
+		goals:            maps.Clone(t.goals),
+		calendarFeeds:    maps.Clone(t.calendarFeeds),
+		idempotencyKeys:  maps.Clone(t.idempotencyKeys),
+		seq:              maps.Clone(t.seq),
+	}
+}
+
+type txContextKey struct{}
+
+// RunInTx implements fwt.TxRunner. It holds the write lock for the whole of
+// fn, so units of work are serialized, and restores a snapshot of the
+// tables when fn fails.
+func (db *DB) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if db.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	snapshot := db.tables.clone()
+	if err := fn(context.WithValue(ctx, txContextKey{}, db)); err != nil {
+		db.tables = snapshot
+		return err
+	}
+	return nil
+}
+
+// RunInTxOptions implements fwt.TxRunner. Units of work are serialized, so
+// the options make no difference.
+func (db *DB) RunInTxOptions(ctx context.Context, opts fwt.TxOptions, fn func(ctx context.Context) error) error {
+	return db.RunInTx(ctx, fn)
+}
+
+func (db *DB) inTx(ctx context.Context) bool {
+	v, _ := ctx.Value(txContextKey{}).(*DB)
+	return v == db
+}
+
+// lock takes the write lock unless ctx belongs to a RunInTx call, which
+// already holds it. The returned function releases the lock.
+func (db *DB) lock(ctx context.Context) func() {
+	if db.inTx(ctx) {
+		return func() {}
+	}
+	db.mu.Lock()
+	return db.mu.Unlock
+}
+
+// rlock is like lock but takes the read lock.
+func (db *DB) rlock(ctx context.Context) func() {
+	if db.inTx(ctx) {
+		return func() {}
+	}
+	db.mu.RLock()
+	return db.mu.RUnlock
+}
+
+// SeedExercises loads the default exercise catalog.
+func (db *DB) SeedExercises() error {
+	ctx := context.Background()
+	s := NewExerciseService(db)
+	for _, e := range DefaultExercises() {
+		if err := s.CreateExercise(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping always succeeds; there is nothing to connect to.
+func (db *DB) Ping(ctx context.Context) error {
+	return nil
+}
+
+// SchemaVersion reports version 0 as the in-memory store has no migrations.
+func (db *DB) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return 0, false, nil
+}
+
+// now returns the current time with the precision stored by the SQL
+// backends.
+func (db *DB) now() time.Time {
+	return db.Now().UTC().Truncate(time.Second)
+}
+
+// nextID returns the next identifier for table, like a SERIAL column.
+func (db *DB) nextID(table string) uint {
+	db.seq[table]++
+	return db.seq[table]
+}
+
+// sortedByID returns the values of m ordered by ID.
+func sortedByID[T any](m map[uint]*T) []*T {
+	ids := make([]uint, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	a := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		a = append(a, m[id])
+	}
+	return a
+}
+
+// paginate applies limit and offset to a filtered result and returns the
+// page together with the total number of matches.
+func paginate[T any](a []T, limit, offset int) ([]T, int) {
+	n := len(a)
+	if offset > 0 {
+		if offset >= len(a) {
+			return a[:0], n
+		}
+		a = a[offset:]
+	}
+	if limit > 0 && limit < len(a) {
+		a = a[:limit]
+	}
+	return a, n
+}
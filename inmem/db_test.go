@@ -0,0 +1,42 @@
+package inmem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maliByatzes/fwt"
+	"github.com/maliByatzes/fwt/inmem"
+	"github.com/stretchr/testify/require"
+)
+
+// MustOpenDB returns a new database seeded with the default exercise
+// catalog, matching the state of a freshly migrated SQL database.
+func MustOpenDB(tb testing.TB) *inmem.DB {
+	tb.Helper()
+
+	db := inmem.NewDB()
+	require.NoError(tb, db.SeedExercises())
+	return db
+}
+
+func TestDB_SeedExercises(t *testing.T) {
+	db := MustOpenDB(t)
+
+	_, n, err := inmem.NewExerciseService(db).FindExercises(context.Background(), fwt.ExerciseFilter{})
+	require.NoError(t, err)
+	require.Equal(t, len(inmem.DefaultExercises()), n)
+}
+
+func TestDB_Copies(t *testing.T) {
+	db := MustOpenDB(t)
+	s := inmem.NewExerciseService(db)
+	ctx := context.Background()
+
+	e, err := s.FindExerciseByName(ctx, inmem.DefaultExercises()[0].Name)
+	require.NoError(t, err)
+	e.Name = "changed"
+
+	other, err := s.FindExerciseByID(ctx, e.ID)
+	require.NoError(t, err)
+	require.Equal(t, inmem.DefaultExercises()[0].Name, other.Name)
+}
@@ -0,0 +1,78 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.ExerciseService = (*ExerciseService)(nil)
+
+type ExerciseService struct {
+	db *DB
+}
+
+func NewExerciseService(db *DB) *ExerciseService {
+	return &ExerciseService{db: db}
+}
+
+func (s *ExerciseService) FindExerciseByID(ctx context.Context, id uint) (*fwt.Exercise, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findExercise(fwt.ExerciseFilter{ID: &id})
+}
+
+func (s *ExerciseService) FindExerciseByName(ctx context.Context, name string) (*fwt.Exercise, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findExercise(fwt.ExerciseFilter{Name: &name})
+}
+
+func (s *ExerciseService) FindExercises(ctx context.Context, filter fwt.ExerciseFilter) ([]*fwt.Exercise, int, error) {
+	defer s.db.rlock(ctx)()
+
+	exercises, n := s.db.findExercises(filter)
+	return exercises, n, nil
+}
+
+func (s *ExerciseService) CreateExercise(ctx context.Context, exercise *fwt.Exercise) error {
+	defer s.db.lock(ctx)()
+
+	exercise.CreatedAt = s.db.now()
+	exercise.UpdatedAt = exercise.CreatedAt
+
+	if err := exercise.Validate(); err != nil {
+		return err
+	}
+
+	exercise.ID = s.db.nextID("exercise")
+	e := *exercise
+	s.db.exercises[e.ID] = &e
+
+	return nil
+}
+
+func (db *DB) findExercise(filter fwt.ExerciseFilter) (*fwt.Exercise, error) {
+	a, _ := db.findExercises(filter)
+	if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Exercise not found."}
+	}
+	return a[0], nil
+}
+
+func (db *DB) findExercises(filter fwt.ExerciseFilter) ([]*fwt.Exercise, int) {
+	exercises := make([]*fwt.Exercise, 0)
+	for _, e := range sortedByID(db.exercises) {
+		if v := filter.ID; v != nil && e.ID != *v {
+			continue
+		}
+		if v := filter.Name; v != nil && e.Name != *v {
+			continue
+		}
+
+		other := *e
+		exercises = append(exercises, &other)
+	}
+
+	return paginate(exercises, filter.Limit, filter.Offset)
+}
@@ -0,0 +1,231 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.GoalService = (*GoalService)(nil)
+
+type GoalService struct {
+	db *DB
+}
+
+func NewGoalService(db *DB) *GoalService {
+	return &GoalService{db: db}
+}
+
+func (s *GoalService) FindGoalByID(ctx context.Context, id uint) (*fwt.Goal, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findGoal(fwt.GoalFilter{ID: &id})
+}
+
+func (s *GoalService) FindGoals(ctx context.Context, filter fwt.GoalFilter) ([]*fwt.Goal, int, error) {
+	defer s.db.rlock(ctx)()
+
+	a, n := s.db.findGoals(filter)
+	return a, n, nil
+}
+
+func (s *GoalService) CreateGoal(ctx context.Context, goal *fwt.Goal) error {
+	defer s.db.lock(ctx)()
+
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to create a goal.")
+	}
+	goal.UserID = userID
+
+	goal.ArchivedAt = time.Time{}
+	goal.CreatedAt = s.db.now()
+	goal.UpdatedAt = goal.CreatedAt
+
+	if err := goal.Validate(); err != nil {
+		return err
+	}
+
+	if goal.Type == fwt.GoalTypeLift {
+		if _, err := s.db.findExercise(fwt.ExerciseFilter{ID: &goal.ExerciseID}); err != nil {
+			return err
+		}
+	} else {
+		goal.ExerciseID = 0
+	}
+
+	// Record where the user starts from. Periodic goals start afresh every
+	// period.
+	goal.Start = 0
+	switch goal.Type {
+	case fwt.GoalTypeBodyWeight:
+		goal.Start = s.db.findStartingWeight(userID)
+	case fwt.GoalTypeLift:
+		for _, sample := range s.db.findGoalSamples(goal, time.Time{}, time.Time{}) {
+			goal.Start = max(goal.Start, sample.Value)
+		}
+	}
+	if err := goal.ValidateStart(); err != nil {
+		return err
+	}
+
+	goal.ID = s.db.nextID("goal")
+	other := *goal
+	s.db.goals[other.ID] = &other
+
+	return nil
+}
+
+// findStartingWeight returns the latest measured weight of the user, falling
+// back to the weight on their profile, or zero when neither is known.
+func (db *DB) findStartingWeight(userID uint) float64 {
+	if weight := db.findLatestWeight(userID); weight > 0 {
+		return weight
+	}
+	if profile, err := db.findProfile(fwt.ProfileFilter{UserID: &userID}); err == nil {
+		return profile.Weight
+	}
+	return 0
+}
+
+func (s *GoalService) ArchiveGoal(ctx context.Context, id uint) (*fwt.Goal, error) {
+	defer s.db.lock(ctx)()
+
+	goal, err := s.db.findGoal(fwt.GoalFilter{ID: &id})
+	if err != nil {
+		return goal, err
+	} else if goal.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to archive this goal.")
+	} else if !goal.ArchivedAt.IsZero() {
+		return goal, fwt.Errorf(fwt.ECONFLICT, "Goal is already archived.")
+	}
+
+	goal.ArchivedAt = s.db.now()
+	goal.UpdatedAt = goal.ArchivedAt
+
+	other := *goal
+	s.db.goals[other.ID] = &other
+
+	return goal, nil
+}
+
+func (s *GoalService) FindGoalProgress(ctx context.Context, id uint) (*fwt.GoalProgress, error) {
+	defer s.db.rlock(ctx)()
+
+	goal, err := s.db.findGoal(fwt.GoalFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.db.now().In(s.db.findLocation(goal.UserID))
+	start, end := goal.SampleRange(now)
+	return goal.Evaluate(s.db.findGoalSamples(goal, start, end), now), nil
+}
+
+func (db *DB) findGoal(filter fwt.GoalFilter) (*fwt.Goal, error) {
+	a, _ := db.findGoals(filter)
+	if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Goal not found."}
+	}
+	return a[0], nil
+}
+
+func (db *DB) findGoals(filter fwt.GoalFilter) ([]*fwt.Goal, int) {
+	a := make([]*fwt.Goal, 0)
+	for _, g := range sortedByID(db.goals) {
+		if v := filter.ID; v != nil && g.ID != *v {
+			continue
+		}
+		if v := filter.UserID; v != nil && g.UserID != *v {
+			continue
+		}
+		if v := filter.Type; v != nil && g.Type != *v {
+			continue
+		}
+		if v := filter.Archived; v != nil && g.ArchivedAt.IsZero() == *v {
+			continue
+		}
+
+		other := *g
+		a = append(a, &other)
+	}
+
+	return paginate(a, filter.Limit, filter.Offset)
+}
+
+// findGoalSamples returns the observations towards goal taken in the
+// half-open interval from start to end, ordered by time. A zero start or
+// end leaves that side of the interval open. Workouts count from when they
+// were finished. Workouts logged without times count on their scheduled
+// date, which is compared with the dates of start and end in their own
+// location.
+func (db *DB) findGoalSamples(goal *fwt.Goal, start, end time.Time) []fwt.GoalSample {
+	inRange := func(t time.Time) bool {
+		return (start.IsZero() || !t.Before(start)) && (end.IsZero() || t.Before(end))
+	}
+	inDates := func(date time.Time) bool {
+		return (start.IsZero() || !date.Before(fwt.Date(start))) && (end.IsZero() || date.Before(fwt.Date(end)))
+	}
+
+	samples := make([]fwt.GoalSample, 0)
+	if goal.Type == fwt.GoalTypeBodyWeight {
+		ms, _ := db.findBodyMeasurements(fwt.BodyMeasurementFilter{UserID: &goal.UserID})
+		for _, m := range ms {
+			if m.Weight > 0 && inRange(m.MeasuredAt) {
+				samples = append(samples, fwt.GoalSample{At: m.MeasuredAt, Value: m.Weight})
+			}
+		}
+		return samples
+	}
+
+	finishedAt := make(map[uint]time.Time)
+	for _, w := range sortedByID(db.workouts) {
+		if w.UserID != goal.UserID {
+			continue
+		} else if w.Status != fwt.WorkoutStatusCompleted && w.Status != fwt.WorkoutStatusPartiallyCompleted {
+			continue
+		}
+
+		at := w.FinishedAt
+		if at.IsZero() {
+			at = fwt.Date(w.ScheduledDate)
+			if !inDates(at) {
+				continue
+			}
+		} else if !inRange(at) {
+			continue
+		}
+		finishedAt[w.ID] = at
+
+		if goal.Type == fwt.GoalTypeFrequency {
+			samples = append(samples, fwt.GoalSample{At: at, Value: 1})
+		}
+	}
+
+	if goal.Type != fwt.GoalTypeFrequency {
+		for _, set := range sortedByID(db.sets) {
+			we, ok := db.workoutExercises[set.WorkoutExerciseID]
+			if !ok {
+				continue
+			}
+			at, ok := finishedAt[we.WorkoutID]
+			if !ok {
+				continue
+			}
+
+			switch goal.Type {
+			case fwt.GoalTypeLift:
+				if we.ExerciseID == goal.ExerciseID {
+					samples = append(samples, fwt.GoalSample{At: at, Value: fwt.EstimateOneRepMax(set.Reps, set.Load)})
+				}
+			case fwt.GoalTypeVolume:
+				samples = append(samples, fwt.GoalSample{At: at, Value: float64(set.Reps) * set.Load})
+			}
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].At.Before(samples[j].At) })
+
+	return samples
+}
@@ -0,0 +1,124 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.IdempotencyService = (*IdempotencyService)(nil)
+
+type IdempotencyService struct {
+	db *DB
+}
+
+func NewIdempotencyService(db *DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+func (s *IdempotencyService) FindIdempotencyKey(ctx context.Context, userID uint, key string) (*fwt.IdempotencyKey, error) {
+	defer s.db.rlock(ctx)()
+
+	now := s.db.now()
+	for _, k := range s.db.idempotencyKeys {
+		if k.UserID == userID && k.Key == key && k.ExpiresAt.After(now) {
+			return copyIdempotencyKey(k), nil
+		}
+	}
+	return nil, fwt.Errorf(fwt.ENOTFOUND, "Idempotency key not found.")
+}
+
+func (s *IdempotencyService) CreateIdempotencyKey(ctx context.Context, key *fwt.IdempotencyKey) error {
+	defer s.db.lock(ctx)()
+
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to create an idempotency key.")
+	}
+	key.UserID = userID
+	key.CreatedAt = s.db.now()
+
+	if err := key.Validate(); err != nil {
+		return err
+	}
+
+	// An expired key may be reused, so clear it out before inserting.
+	for id, k := range s.db.idempotencyKeys {
+		if k.UserID != key.UserID || k.Key != key.Key {
+			continue
+		}
+		if k.ExpiresAt.After(key.CreatedAt) {
+			return fwt.Errorf(fwt.ECONFLICT, "This idempotency key is already in use.")
+		}
+		delete(s.db.idempotencyKeys, id)
+	}
+
+	key.ID = s.db.nextID("idempotency_key")
+	s.db.idempotencyKeys[key.ID] = copyIdempotencyKey(key)
+
+	return nil
+}
+
+func (s *IdempotencyService) UpdateIdempotencyKey(ctx context.Context, id uint, upd fwt.IdempotencyKeyUpdate) (*fwt.IdempotencyKey, error) {
+	defer s.db.lock(ctx)()
+
+	key, err := s.db.findIdempotencyKeyByID(id)
+	if err != nil {
+		return key, err
+	} else if key.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this idempotency key.")
+	}
+
+	if v := upd.ResponseStatus; v != nil {
+		key.ResponseStatus = *v
+	}
+	if v := upd.ResponseBody; v != nil {
+		key.ResponseBody = v
+	}
+	if v := upd.ResponseHeaders; v != nil {
+		key.ResponseHeaders = v
+	}
+	if v := upd.ExpiresAt; v != nil {
+		key.ExpiresAt = *v
+	}
+
+	s.db.idempotencyKeys[key.ID] = copyIdempotencyKey(key)
+
+	return key, nil
+}
+
+func (s *IdempotencyService) DeleteIdempotencyKey(ctx context.Context, id uint) error {
+	defer s.db.lock(ctx)()
+
+	key, err := s.db.findIdempotencyKeyByID(id)
+	if err != nil {
+		return err
+	} else if key.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this idempotency key.")
+	}
+	delete(s.db.idempotencyKeys, id)
+
+	return nil
+}
+
+func (db *DB) findIdempotencyKeyByID(id uint) (*fwt.IdempotencyKey, error) {
+	k, ok := db.idempotencyKeys[id]
+	if !ok {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "Idempotency key not found.")
+	}
+	return copyIdempotencyKey(k), nil
+}
+
+// copyIdempotencyKey also copies the response body and headers so that
+// callers cannot modify the stored values.
+func copyIdempotencyKey(k *fwt.IdempotencyKey) *fwt.IdempotencyKey {
+	other := *k
+	other.ResponseBody = append([]byte{}, k.ResponseBody...)
+	if k.ResponseHeaders != nil {
+		other.ResponseHeaders = make(map[string][]string, len(k.ResponseHeaders))
+		for name, values := range k.ResponseHeaders {
+			other.ResponseHeaders[name] = append([]string{}, values...)
+		}
+	}
+	return &other
+}
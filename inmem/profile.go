@@ -0,0 +1,203 @@
+package inmem
+
+import (
+	"context"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.ProfileService = (*ProfileService)(nil)
+
+type ProfileService struct {
+	db *DB
+}
+
+func NewProfileService(db *DB) *ProfileService {
+	return &ProfileService{db: db}
+}
+
+func (s *ProfileService) FindProfileByID(ctx context.Context, id uint) (*fwt.Profile, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findProfile(fwt.ProfileFilter{ID: &id})
+}
+
+func (s *ProfileService) FindProfileByUserID(ctx context.Context, userID uint) (*fwt.Profile, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findProfile(fwt.ProfileFilter{UserID: &userID})
+}
+
+func (s *ProfileService) FindProfiles(ctx context.Context, filter fwt.ProfileFilter) ([]*fwt.Profile, int, error) {
+	defer s.db.rlock(ctx)()
+
+	profiles, n := s.db.findProfiles(filter)
+	return profiles, n, nil
+}
+
+func (s *ProfileService) CreateProfile(ctx context.Context, profile *fwt.Profile) error {
+	defer s.db.lock(ctx)()
+
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to create a profile.")
+	}
+	profile.UserID = userID
+
+	profile.Units = fwt.MetricUnits.Merge(profile.Units)
+	if profile.Timezone == "" {
+		profile.Timezone = fwt.DefaultTimezone
+	}
+	profile.Version = 1
+	profile.CreatedAt = s.db.now()
+	profile.UpdatedAt = profile.CreatedAt
+
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.db.findProfile(fwt.ProfileFilter{UserID: &userID}); err == nil {
+		return &fwt.Error{Code: fwt.ECONFLICT, Message: "Profile already exists."}
+	}
+
+	// A profile created without a weight starts from the latest measured
+	// one; a weight given here is recorded as a new measurement instead.
+	if profile.Weight == 0 {
+		profile.Weight = s.db.findLatestWeight(userID)
+	} else if err := s.db.recordProfileWeight(ctx, profile.Weight); err != nil {
+		return err
+	}
+
+	profile.ID = s.db.nextID("profile")
+	p := *profile
+	s.db.profiles[p.ID] = &p
+
+	return nil
+}
+
+func (s *ProfileService) UpdateProfile(ctx context.Context, id uint, upd fwt.ProfileUpdate) (*fwt.Profile, error) {
+	defer s.db.lock(ctx)()
+
+	profile, err := s.db.findProfile(fwt.ProfileFilter{ID: &id})
+	if err != nil {
+		return profile, err
+	} else if profile.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this profile.")
+	} else if v := upd.Version; v != nil && *v != profile.Version {
+		return profile, fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
+	}
+	old := *profile
+
+	if v := upd.FirstName; v != nil {
+		profile.FirstName = *v
+	}
+	if v := upd.LastName; v != nil {
+		profile.LastName = *v
+	}
+	if v := upd.DateOfBirth; v != nil {
+		profile.DateOfBirth = *v
+	}
+	if v := upd.Gender; v != nil {
+		profile.Gender = *v
+	}
+	if v := upd.Height; v != nil {
+		profile.Height = *v
+	}
+	if v := upd.Weight; v != nil {
+		profile.Weight = *v
+	}
+	if v := upd.Units; v != nil {
+		profile.Units = profile.Units.Merge(*v)
+	}
+	if v := upd.Timezone; v != nil {
+		profile.Timezone = *v
+	}
+	profile.UpdatedAt = s.db.now()
+
+	if err := profile.Validate(); err != nil {
+		return profile, err
+	}
+
+	if v := upd.Weight; v != nil && *v != old.Weight {
+		if err := s.db.recordProfileWeight(ctx, *v); err != nil {
+			return profile, err
+		}
+	}
+
+	profile.Version++
+	p := *profile
+	s.db.profiles[p.ID] = &p
+
+	return profile, nil
+}
+
+func (s *ProfileService) DeleteProfile(ctx context.Context, id uint, version *uint) error {
+	defer s.db.lock(ctx)()
+
+	profile, err := s.db.findProfile(fwt.ProfileFilter{ID: &id})
+	if err != nil {
+		return err
+	} else if profile.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this profile.")
+	} else if version != nil && *version != profile.Version {
+		return fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
+	}
+
+	delete(s.db.profiles, id)
+
+	return nil
+}
+
+func (db *DB) findProfile(filter fwt.ProfileFilter) (*fwt.Profile, error) {
+	a, _ := db.findProfiles(filter)
+	if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Profile not found."}
+	}
+	return a[0], nil
+}
+
+// findLocation returns the location of the user's timezone, which is UTC
+// for users without a profile.
+func (db *DB) findLocation(userID uint) *time.Location {
+	profile, err := db.findProfile(fwt.ProfileFilter{UserID: &userID})
+	if err != nil {
+		return time.UTC
+	}
+	return profile.Location()
+}
+
+func (db *DB) findProfiles(filter fwt.ProfileFilter) ([]*fwt.Profile, int) {
+	profiles := make([]*fwt.Profile, 0)
+	for _, p := range sortedByID(db.profiles) {
+		if v := filter.ID; v != nil && p.ID != *v {
+			continue
+		}
+		if v := filter.UserID; v != nil && p.UserID != *v {
+			continue
+		}
+		if v := filter.FirstName; v != nil && p.FirstName != *v {
+			continue
+		}
+		if v := filter.LastName; v != nil && p.LastName != *v {
+			continue
+		}
+		if v := filter.DateOfBirth; v != nil && !p.DateOfBirth.Equal(*v) {
+			continue
+		}
+		if v := filter.Gender; v != nil && p.Gender != *v {
+			continue
+		}
+		if v := filter.Height; v != nil && p.Height != *v {
+			continue
+		}
+		if v := filter.Weight; v != nil && p.Weight != *v {
+			continue
+		}
+
+		other := *p
+		profiles = append(profiles, &other)
+	}
+
+	return paginate(profiles, filter.Limit, filter.Offset)
+}
@@ -0,0 +1,30 @@
+package inmem
+
+import "github.com/maliByatzes/fwt"
+
+func (db *DB) createSet(set *fwt.Set) error {
+	set.CreatedAt = db.now()
+
+	if err := set.Validate(); err != nil {
+		return err
+	}
+
+	set.ID = db.nextID("exercise_set")
+	other := *set
+	db.sets[other.ID] = &other
+
+	return nil
+}
+
+// findSets returns copies of the sets of a workout exercise, or nil if it
+// has none.
+func (db *DB) findSets(workoutExerciseID uint) []*fwt.Set {
+	var a []*fwt.Set
+	for _, set := range sortedByID(db.sets) {
+		if set.WorkoutExerciseID == workoutExerciseID {
+			other := *set
+			a = append(a, &other)
+		}
+	}
+	return a
+}
@@ -0,0 +1,260 @@
+package inmem
+
+import (
+	"context"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.UserService = (*UserService)(nil)
+
+type UserService struct {
+	db *DB
+}
+
+func NewUserService(db *DB) *UserService {
+	return &UserService{db: db}
+}
+
+func (s *UserService) FindUserByID(ctx context.Context, id uint) (*fwt.User, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findUserByID(id)
+}
+
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (*fwt.User, error) {
+	defer s.db.rlock(ctx)()
+
+	a, _ := s.db.findUsers(fwt.UserFilter{Username: &username})
+	if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "User not found."}
+	}
+
+	user := a[0]
+	if err := user.VerifyPassword(password, user.HashedPassword); err != nil {
+		return nil, &fwt.Error{Code: fwt.ENOTAUTHORIZED, Message: "Incorrect credentials"}
+	}
+
+	return user, nil
+}
+
+func (s *UserService) FindUsers(ctx context.Context, filter fwt.UserFilter) ([]*fwt.User, int, error) {
+	defer s.db.rlock(ctx)()
+
+	users, n := s.db.findUsers(filter)
+	return users, n, nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, user *fwt.User) error {
+	defer s.db.lock(ctx)()
+
+	user.CreatedAt = s.db.now()
+	user.UpdatedAt = user.CreatedAt
+
+	if err := user.Validate(); err != nil {
+		return err
+	} else if err := s.db.checkUserUnique(user); err != nil {
+		return err
+	}
+
+	user.ID = s.db.nextID("user")
+	u := *user
+	s.db.users[u.ID] = &u
+
+	return nil
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+	defer s.db.lock(ctx)()
+
+	user, err := s.db.findUserByID(id)
+	if err != nil {
+		return user, err
+	} else if user.ID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this user.")
+	}
+
+	if v := upd.Username; v != nil {
+		user.Username = *v
+	}
+	if v := upd.Email; v != nil {
+		user.Email = *v
+	}
+	user.UpdatedAt = s.db.now()
+
+	if err := user.Validate(); err != nil {
+		return user, err
+	} else if err := s.db.checkUserUnique(user); err != nil {
+		return user, err
+	}
+
+	u := *user
+	s.db.users[u.ID] = &u
+
+	return user, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	defer s.db.lock(ctx)()
+
+	if user, err := s.db.findUserByID(id); err != nil {
+		return err
+	} else if user.ID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this user")
+	}
+
+	s.db.purgeUser(id)
+	return nil
+}
+
+func (s *UserService) ScheduleUserDeletion(ctx context.Context, id uint) (*fwt.User, error) {
+	defer s.db.lock(ctx)()
+
+	return s.db.setUserDeletedAt(ctx, id, s.db.now())
+}
+
+func (s *UserService) RestoreUser(ctx context.Context, id uint) (*fwt.User, error) {
+	defer s.db.lock(ctx)()
+
+	return s.db.setUserDeletedAt(ctx, id, time.Time{})
+}
+
+func (s *UserService) PurgeDeletedUser(ctx context.Context, id uint, before time.Time) error {
+	defer s.db.lock(ctx)()
+
+	user, err := s.db.findUserByID(id)
+	if err != nil {
+		return err
+	} else if !user.Deleted() || user.DeletedAt.After(before) {
+		return fwt.Errorf(fwt.ECONFLICT, "User is not due to be purged.")
+	}
+
+	s.db.purgeUser(id)
+	return nil
+}
+
+// setUserDeletedAt schedules the deletion of a user when deletedAt is set
+// and cancels it otherwise.
+func (db *DB) setUserDeletedAt(ctx context.Context, id uint, deletedAt time.Time) (*fwt.User, error) {
+	user, err := db.findUserByID(id)
+	if err != nil {
+		return user, err
+	} else if user.ID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this user.")
+	}
+
+	if !deletedAt.IsZero() && user.Deleted() {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User is already scheduled for deletion.")
+	} else if deletedAt.IsZero() && !user.Deleted() {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User is not scheduled for deletion.")
+	} else if deletedAt.IsZero() && db.now().After(user.PurgeAt()) {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User can no longer be restored as the deletion grace period has ended.")
+	}
+
+	user.DeletedAt = deletedAt
+	user.UpdatedAt = db.now()
+
+	u := *user
+	db.users[u.ID] = &u
+
+	return user, nil
+}
+
+// purgeUser removes a user and everything they own, children before their
+// parents, as the SQL backends must.
+func (db *DB) purgeUser(id uint) {
+	for weID, we := range db.workoutExercises {
+		if w, ok := db.workouts[we.WorkoutID]; !ok || w.UserID != id {
+			continue
+		}
+		for setID, set := range db.sets {
+			if set.WorkoutExerciseID == weID {
+				delete(db.sets, setID)
+			}
+		}
+		for statusID, status := range db.weStatuses {
+			if status.WorkoutExerciseID == weID {
+				delete(db.weStatuses, statusID)
+			}
+		}
+		delete(db.workoutExercises, weID)
+	}
+	for workoutID, w := range db.workouts {
+		if w.UserID == id {
+			delete(db.workouts, workoutID)
+		}
+	}
+	for goalID, g := range db.goals {
+		if g.UserID == id {
+			delete(db.goals, goalID)
+		}
+	}
+	for measurementID, m := range db.bodyMeasurements {
+		if m.UserID == id {
+			delete(db.bodyMeasurements, measurementID)
+		}
+	}
+	for profileID, p := range db.profiles {
+		if p.UserID == id {
+			delete(db.profiles, profileID)
+		}
+	}
+	for feedID, f := range db.calendarFeeds {
+		if f.UserID == id {
+			delete(db.calendarFeeds, feedID)
+		}
+	}
+	for keyID, k := range db.idempotencyKeys {
+		if k.UserID == id {
+			delete(db.idempotencyKeys, keyID)
+		}
+	}
+	delete(db.users, id)
+}
+
+func (db *DB) findUserByID(id uint) (*fwt.User, error) {
+	a, _ := db.findUsers(fwt.UserFilter{ID: &id})
+	if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "User not found."}
+	}
+	return a[0], nil
+}
+
+func (db *DB) findUsers(filter fwt.UserFilter) ([]*fwt.User, int) {
+	users := make([]*fwt.User, 0)
+	for _, u := range sortedByID(db.users) {
+		if v := filter.ID; v != nil && u.ID != *v {
+			continue
+		}
+		if v := filter.Username; v != nil && u.Username != *v {
+			continue
+		}
+		if v := filter.Email; v != nil && u.Email != *v {
+			continue
+		}
+		if v := filter.DeletedBefore; v != nil && (!u.Deleted() || u.DeletedAt.After(*v)) {
+			continue
+		}
+
+		other := *u
+		users = append(users, &other)
+	}
+
+	return paginate(users, filter.Limit, filter.Offset)
+}
+
+func (db *DB) checkUserUnique(user *fwt.User) error {
+	for _, u := range db.users {
+		if u.ID == user.ID {
+			continue
+		}
+		if u.Username == user.Username {
+			return fwt.Errorf(fwt.ECONFLICT, "This username already exists.")
+		}
+		if u.Email == user.Email {
+			return fwt.Errorf(fwt.ECONFLICT, "This email already exists.")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,412 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WorkoutService = (*WorkoutService)(nil)
+
+type WorkoutService struct {
+	db *DB
+}
+
+func NewWorkoutService(db *DB) *WorkoutService {
+	return &WorkoutService{db: db}
+}
+
+func (s *WorkoutService) FindWorkoutByID(ctx context.Context, id uint) (*fwt.Workout, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findWorkout(fwt.WorkoutFilter{ID: &id})
+}
+
+func (s *WorkoutService) FindWorkoutByIDUserID(ctx context.Context, id uint, userID uint) (*fwt.Workout, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findWorkout(fwt.WorkoutFilter{ID: &id, UserID: &userID})
+}
+
+func (s *WorkoutService) FindWorkouts(ctx context.Context, filter fwt.WorkoutFilter) ([]*fwt.Workout, int, error) {
+	defer s.db.rlock(ctx)()
+
+	workouts, n := s.db.findWorkouts(filter)
+	return workouts, n, nil
+}
+
+// CreateWorkout resolves every exercise name before storing anything so that
+// an unknown exercise leaves no partial workout behind.
+func (s *WorkoutService) CreateWorkout(ctx context.Context, workout *fwt.Workout) error {
+	defer s.db.lock(ctx)()
+
+	if fwt.UserFromContext(ctx) == nil {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged to create workout")
+	}
+	workout.UserID = fwt.UserIDFromContext(ctx)
+
+	workout.Version = 1
+	if workout.Status == "" {
+		workout.Status = fwt.WorkoutStatusPlanned
+	}
+	workout.ScheduledDate = fwt.Date(workout.ScheduledDate)
+	workout.CreatedAt = s.db.now()
+	workout.UpdatedAt = workout.CreatedAt
+
+	if err := workout.Validate(workout.CreatedAt.In(s.db.findLocation(workout.UserID))); err != nil {
+		return err
+	}
+
+	exercises := make([]*fwt.Exercise, 0, len(workout.Exercises))
+	for _, ex := range workout.Exercises {
+		exercise, err := s.db.findExercise(fwt.ExerciseFilter{Name: &ex.Name})
+		if err != nil {
+			return err
+		}
+		exercises = append(exercises, exercise)
+	}
+
+	workout.ID = s.db.nextID("workout")
+	w := *workout
+	w.Exercises = nil
+	s.db.workouts[w.ID] = &w
+
+	for _, exercise := range exercises {
+		if err := s.db.addExerciseToWorkout(ctx, workout, exercise); err != nil {
+			return err
+		}
+	}
+	workout.Exercises = exercises
+
+	return nil
+}
+
+func (s *WorkoutService) UpdateWorkout(ctx context.Context, id uint, upd fwt.WorkoutUpdate) (*fwt.Workout, error) {
+	defer s.db.lock(ctx)()
+
+	workout, err := s.db.findWorkout(fwt.WorkoutFilter{ID: &id})
+	if err != nil {
+		return workout, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this workout.")
+	} else if v := upd.Version; v != nil && *v != workout.Version {
+		return workout, fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
+	}
+
+	if v := upd.Name; v != nil {
+		workout.Name = *v
+	}
+	if v := upd.ScheduledDate; v != nil {
+		workout.ScheduledDate = fwt.Date(*v)
+	}
+	workout.UpdatedAt = s.db.now()
+
+	if err := workout.Validate(workout.UpdatedAt.In(s.db.findLocation(workout.UserID))); err != nil {
+		return workout, err
+	}
+
+	workout.Version++
+	s.db.storeWorkout(workout)
+
+	return workout, nil
+}
+
+func (s *WorkoutService) RemoveExercisesFromWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	defer s.db.lock(ctx)()
+
+	workout, err := s.db.findWorkout(fwt.WorkoutFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to modify this workout.")
+	}
+
+	for _, exName := range exercises {
+		for index, e := range workout.Exercises {
+			if e.Name == exName {
+				if err := s.db.removeExerciseFromWorkout(workout, e); err != nil {
+					return workout, err
+				}
+
+				workout.Exercises = append(workout.Exercises[:index], workout.Exercises[index+1:]...)
+				break
+			}
+		}
+	}
+
+	s.db.touchWorkout(workout)
+
+	return workout, nil
+}
+
+func (s *WorkoutService) AddExercisesToWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	defer s.db.lock(ctx)()
+
+	workout, err := s.db.findWorkout(fwt.WorkoutFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to modify this workout.")
+	}
+
+	toAdd := make([]*fwt.Exercise, 0, len(exercises))
+	for _, exName := range exercises {
+		exercise, err := s.db.findExercise(fwt.ExerciseFilter{Name: &exName})
+		if err != nil {
+			return workout, err
+		}
+		toAdd = append(toAdd, exercise)
+	}
+
+	for _, exercise := range toAdd {
+		if !hasExercise(workout.Exercises, exercise) {
+			if err := s.db.addExerciseToWorkout(ctx, workout, exercise); err != nil {
+				return workout, err
+			}
+
+			workout.Exercises = append(workout.Exercises, exercise)
+		}
+	}
+
+	s.db.touchWorkout(workout)
+
+	return workout, nil
+}
+
+func (s *WorkoutService) StartWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	defer s.db.lock(ctx)()
+
+	return s.db.transitionWorkout(ctx, id, func(workout *fwt.Workout) string {
+		return fwt.WorkoutStatusInProgress
+	})
+}
+
+func (s *WorkoutService) FinishWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	defer s.db.lock(ctx)()
+
+	return s.db.transitionWorkout(ctx, id, func(workout *fwt.Workout) string {
+		statuses, _ := s.db.findWEStatuses(fwt.WEStatusFilter{WorkoutID: &workout.ID})
+		return fwt.FinishedWorkoutStatus(statuses)
+	})
+}
+
+func (s *WorkoutService) SkipWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	defer s.db.lock(ctx)()
+
+	return s.db.transitionWorkout(ctx, id, func(workout *fwt.Workout) string {
+		return fwt.WorkoutStatusSkipped
+	})
+}
+
+// LogWorkout creates the workout as CreateWorkout does and then records the
+// logged status and sets of each exercise, all in one unit of work.
+func (s *WorkoutService) LogWorkout(ctx context.Context, workout *fwt.Workout) error {
+	return s.db.RunInTx(ctx, func(ctx context.Context) error {
+		now := s.db.now().In(s.db.findLocation(fwt.UserIDFromContext(ctx)))
+		if err := workout.Log(now); err != nil {
+			return err
+		}
+
+		logged := workout.Exercises
+		if err := s.CreateWorkout(ctx, workout); err != nil {
+			return err
+		}
+
+		wes, _ := s.db.findWorkoutExercises(fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID})
+		for i, ex := range workout.Exercises {
+			status, err := s.db.findWEStatus(fwt.WEStatusFilter{WorkoutExerciseID: &wes[i].ID})
+			if err != nil {
+				return err
+			}
+			status.Status = logged[i].Status.Status
+			status.Comments = logged[i].Status.Comments
+			status.CompletedAt = logged[i].Status.CompletedAt
+			status.UpdatedAt = s.db.now()
+			if err := status.Validate(); err != nil {
+				return err
+			}
+			other := *status
+			s.db.weStatuses[other.ID] = &other
+			ex.Status = status
+
+			for _, set := range logged[i].Sets {
+				set.WorkoutExerciseID = wes[i].ID
+				if err := s.db.createSet(set); err != nil {
+					return err
+				}
+			}
+			ex.Sets = logged[i].Sets
+		}
+
+		return nil
+	})
+}
+
+func (s *WorkoutService) QuickStartWorkout(ctx context.Context, workout *fwt.Workout) error {
+	return s.db.RunInTx(ctx, func(ctx context.Context) error {
+		workout.QuickStart(s.db.now().In(s.db.findLocation(fwt.UserIDFromContext(ctx))))
+		return s.CreateWorkout(ctx, workout)
+	})
+}
+
+func (s *WorkoutService) DeleteWorkout(ctx context.Context, id uint, version *uint) error {
+	defer s.db.lock(ctx)()
+
+	workout, err := s.db.findWorkout(fwt.WorkoutFilter{ID: &id})
+	if err != nil {
+		return err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this workout.")
+	} else if version != nil && *version != workout.Version {
+		return fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
+	}
+
+	workoutExercises, _ := s.db.findWorkoutExercises(fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID})
+	for _, we := range workoutExercises {
+		if err := s.db.deleteWorkoutExercise(we.ID); err != nil {
+			return err
+		}
+	}
+	delete(s.db.workouts, workout.ID)
+
+	return nil
+}
+
+func (db *DB) findWorkout(filter fwt.WorkoutFilter) (*fwt.Workout, error) {
+	a, _ := db.findWorkouts(filter)
+	if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Workout not found."}
+	}
+	return a[0], nil
+}
+
+// findWorkouts returns copies of the matching workouts with their exercises
+// attached in the order they were added.
+func (db *DB) findWorkouts(filter fwt.WorkoutFilter) ([]*fwt.Workout, int) {
+	workouts := make([]*fwt.Workout, 0)
+	for _, w := range sortedByID(db.workouts) {
+		if v := filter.ID; v != nil && w.ID != *v {
+			continue
+		}
+		if v := filter.UserID; v != nil && w.UserID != *v {
+			continue
+		}
+		if v := filter.Name; v != nil && w.Name != *v {
+			continue
+		}
+		if v := filter.ScheduledDate; v != nil && !w.ScheduledDate.Equal(*v) {
+			continue
+		}
+		if v := filter.From; v != nil && w.ScheduledDate.Before(*v) {
+			continue
+		}
+		if v := filter.To; v != nil && w.ScheduledDate.After(*v) {
+			continue
+		}
+		if v := filter.Status; v != nil && w.Status != *v {
+			continue
+		}
+
+		other := *w
+		workouts = append(workouts, &other)
+	}
+
+	workouts, n := paginate(workouts, filter.Limit, filter.Offset)
+	for _, w := range workouts {
+		wes, _ := db.findWorkoutExercises(fwt.WorkoutExerciseFilter{WorkoutID: &w.ID})
+		for _, we := range wes {
+			if exercise, err := db.findExercise(fwt.ExerciseFilter{ID: &we.ExerciseID}); err == nil {
+				if status, err := db.findWEStatus(fwt.WEStatusFilter{WorkoutExerciseID: &we.ID}); err == nil {
+					exercise.Status = status
+				}
+				exercise.Sets = db.findSets(we.ID)
+				w.Exercises = append(w.Exercises, exercise)
+			}
+		}
+	}
+
+	return workouts, n
+}
+
+// storeWorkout saves a copy of workout without its exercises, which are
+// tracked through workout exercises instead.
+func (db *DB) storeWorkout(workout *fwt.Workout) {
+	w := *workout
+	w.Exercises = nil
+	db.workouts[w.ID] = &w
+}
+
+// touchWorkout bumps the version of a workout whose exercises have changed so
+// that cached copies are invalidated.
+func (db *DB) touchWorkout(workout *fwt.Workout) {
+	workout.Version++
+	workout.UpdatedAt = db.now()
+	db.storeWorkout(workout)
+}
+
+// transitionWorkout moves a workout owned by the current user to the status
+// returned by next and saves it.
+func (db *DB) transitionWorkout(ctx context.Context, id uint, next func(workout *fwt.Workout) string) (*fwt.Workout, error) {
+	workout, err := db.findWorkout(fwt.WorkoutFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this workout.")
+	}
+
+	if err := workout.Transition(next(workout), db.now()); err != nil {
+		return workout, err
+	}
+	db.touchWorkout(workout)
+
+	return workout, nil
+}
+
+// completeWorkout completes the workout of a workout exercise once all of
+// its exercises are completed. Workouts that are already finished or
+// skipped are left alone.
+func (db *DB) completeWorkout(workoutExerciseID uint) {
+	we, ok := db.workoutExercises[workoutExerciseID]
+	if !ok {
+		return
+	}
+
+	statuses, _ := db.findWEStatuses(fwt.WEStatusFilter{WorkoutID: &we.WorkoutID})
+	if !fwt.AllExercisesCompleted(statuses) {
+		return
+	}
+
+	workout, err := db.findWorkout(fwt.WorkoutFilter{ID: &we.WorkoutID})
+	if err != nil {
+		return
+	} else if err := workout.Transition(fwt.WorkoutStatusCompleted, db.now()); err != nil {
+		return
+	}
+	db.touchWorkout(workout)
+}
+
+func (db *DB) removeExerciseFromWorkout(workout *fwt.Workout, exercise *fwt.Exercise) error {
+	a, _ := db.findWorkoutExercises(fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID, ExerciseID: &exercise.ID})
+	if len(a) == 0 {
+		return fwt.Errorf(fwt.ENOTFOUND, "Workout_Exercise not found.")
+	}
+
+	return db.deleteWorkoutExercise(a[0].ID)
+}
+
+func (db *DB) addExerciseToWorkout(ctx context.Context, workout *fwt.Workout, exercise *fwt.Exercise) error {
+	return db.createWorkoutExercise(ctx, &fwt.WorkoutExercise{
+		WorkoutID:  workout.ID,
+		ExerciseID: exercise.ID,
+		Order:      1, // Hard-code for now ...
+	})
+}
+
+func hasExercise(exs []*fwt.Exercise, ex *fwt.Exercise) bool {
+	for _, value := range exs {
+		if value.Name == ex.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WorkoutExerciseService = (*WorkoutExerciseService)(nil)
+
+type WorkoutExerciseService struct {
+	db *DB
+}
+
+func NewWorkoutExerciseService(db *DB) *WorkoutExerciseService {
+	return &WorkoutExerciseService{db: db}
+}
+
+func (s *WorkoutExerciseService) FindWorkoutExerciseByID(ctx context.Context, id uint) (*fwt.WorkoutExercise, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findWorkoutExerciseByID(id)
+}
+
+func (s *WorkoutExerciseService) FindWorkoutExercises(ctx context.Context, filter fwt.WorkoutExerciseFilter) ([]*fwt.WorkoutExercise, int, error) {
+	defer s.db.rlock(ctx)()
+
+	workoutExercises, n := s.db.findWorkoutExercises(filter)
+	return workoutExercises, n, nil
+}
+
+func (s *WorkoutExerciseService) CreateWorkoutExercise(ctx context.Context, workoutExercise *fwt.WorkoutExercise) error {
+	defer s.db.lock(ctx)()
+
+	return s.db.createWorkoutExercise(ctx, workoutExercise)
+}
+
+func (s *WorkoutExerciseService) UpdateWorkoutExercise(ctx context.Context, id uint, upd fwt.WorkoutExerciseUpdate) (*fwt.WorkoutExercise, error) {
+	defer s.db.lock(ctx)()
+
+	we, err := s.db.findWorkoutExerciseByID(id)
+	if err != nil {
+		return we, err
+	}
+
+	if v := upd.Order; v != nil {
+		we.Order = *v
+	}
+	we.UpdatedAt = s.db.now()
+
+	if err := we.Validate(); err != nil {
+		return we, err
+	}
+
+	other := *we
+	s.db.workoutExercises[other.ID] = &other
+
+	return we, nil
+}
+
+func (s *WorkoutExerciseService) DeleteWorkoutExercise(ctx context.Context, id uint) error {
+	defer s.db.lock(ctx)()
+
+	return s.db.deleteWorkoutExercise(id)
+}
+
+// createWorkoutExercise links an exercise to a workout and gives it an
+// initial "pending" status.
+func (db *DB) createWorkoutExercise(ctx context.Context, workoutExercise *fwt.WorkoutExercise) error {
+	workoutExercise.CreatedAt = db.now()
+	workoutExercise.UpdatedAt = workoutExercise.CreatedAt
+
+	if err := workoutExercise.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := db.findExercise(fwt.ExerciseFilter{ID: &workoutExercise.ExerciseID}); err != nil {
+		return err
+	}
+
+	workoutExercise.ID = db.nextID("workout_exercise")
+	we := *workoutExercise
+	db.workoutExercises[we.ID] = &we
+
+	// Both rows are stored or neither is, as a SQL transaction would.
+	if err := db.createWEStatus(ctx, &fwt.WEStatus{
+		WorkoutExerciseID: workoutExercise.ID,
+		Status:            fwt.WEStatusPending,
+	}); err != nil {
+		delete(db.workoutExercises, we.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (db *DB) findWorkoutExerciseByID(id uint) (*fwt.WorkoutExercise, error) {
+	a, _ := db.findWorkoutExercises(fwt.WorkoutExerciseFilter{ID: &id})
+	if len(a) == 0 {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "Workout Exercise not found.")
+	}
+	return a[0], nil
+}
+
+func (db *DB) findWorkoutExercises(filter fwt.WorkoutExerciseFilter) ([]*fwt.WorkoutExercise, int) {
+	workoutExercises := make([]*fwt.WorkoutExercise, 0)
+	for _, we := range sortedByID(db.workoutExercises) {
+		if v := filter.ID; v != nil && we.ID != *v {
+			continue
+		}
+		if v := filter.WorkoutID; v != nil && we.WorkoutID != *v {
+			continue
+		}
+		if v := filter.ExerciseID; v != nil && we.ExerciseID != *v {
+			continue
+		}
+		if v := filter.Order; v != nil && we.Order != *v {
+			continue
+		}
+
+		other := *we
+		workoutExercises = append(workoutExercises, &other)
+	}
+
+	return paginate(workoutExercises, filter.Limit, filter.Offset)
+}
+
+func (db *DB) deleteWorkoutExercise(id uint) error {
+	if _, err := db.findWorkoutExerciseByID(id); err != nil {
+		return err
+	}
+
+	for setID, set := range db.sets {
+		if set.WorkoutExerciseID == id {
+			delete(db.sets, setID)
+		}
+	}
+	for weID, status := range db.weStatuses {
+		if status.WorkoutExerciseID == id {
+			delete(db.weStatuses, weID)
+		}
+	}
+	delete(db.workoutExercises, id)
+
+	return nil
+}
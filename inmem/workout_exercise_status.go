@@ -0,0 +1,150 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WEStatusService = (*WEStatusService)(nil)
+
+type WEStatusService struct {
+	db *DB
+}
+
+func NewWEStatusService(db *DB) *WEStatusService {
+	return &WEStatusService{db: db}
+}
+
+func (s *WEStatusService) FindWEStatusByID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findWEStatus(fwt.WEStatusFilter{ID: &id})
+}
+
+func (s *WEStatusService) FindWEStatusByWEID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
+	defer s.db.rlock(ctx)()
+
+	return s.db.findWEStatus(fwt.WEStatusFilter{WorkoutExerciseID: &id})
+}
+
+func (s *WEStatusService) FindWEStatuses(ctx context.Context, filter fwt.WEStatusFilter) ([]*fwt.WEStatus, int, error) {
+	defer s.db.rlock(ctx)()
+
+	weStatuses, n := s.db.findWEStatuses(filter)
+	return weStatuses, n, nil
+}
+
+func (s *WEStatusService) CreateWEStatus(ctx context.Context, we *fwt.WEStatus) error {
+	defer s.db.lock(ctx)()
+
+	return s.db.createWEStatus(ctx, we)
+}
+
+func (s *WEStatusService) UpdateWEStatus(ctx context.Context, id uint, upd fwt.WEStatusUpdate) (*fwt.WEStatus, error) {
+	defer s.db.lock(ctx)()
+
+	we, err := s.db.findWEStatus(fwt.WEStatusFilter{ID: &id})
+	if err != nil {
+		return we, err
+	}
+
+	if v := upd.Status; v != nil {
+		we.Status = *v
+	}
+	if v := upd.Comments; v != nil {
+		we.Comments = *v
+	}
+	if v := upd.CompletedAt; v != nil {
+		we.CompletedAt = *v
+	}
+	we.UpdatedAt = s.db.now()
+
+	if err := we.Validate(); err != nil {
+		return we, err
+	}
+
+	other := *we
+	s.db.weStatuses[other.ID] = &other
+
+	// Statuses are returned with their workout, so any change to them is a
+	// new version of it.
+	if workoutExercise, ok := s.db.workoutExercises[we.WorkoutExerciseID]; ok {
+		if workout, err := s.db.findWorkout(fwt.WorkoutFilter{ID: &workoutExercise.WorkoutID}); err == nil {
+			s.db.touchWorkout(workout)
+		}
+	}
+
+	if we.Status == fwt.WEStatusCompleted {
+		s.db.completeWorkout(we.WorkoutExerciseID)
+	}
+
+	return we, nil
+}
+
+func (s *WEStatusService) DeleteWEStatus(ctx context.Context, id uint) error {
+	defer s.db.lock(ctx)()
+
+	if _, err := s.db.findWEStatus(fwt.WEStatusFilter{ID: &id}); err != nil {
+		return err
+	}
+	delete(s.db.weStatuses, id)
+
+	return nil
+}
+
+func (db *DB) createWEStatus(ctx context.Context, we *fwt.WEStatus) error {
+	if fwt.UserFromContext(ctx) == nil {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must logged in to create westatus.")
+	}
+
+	we.CreatedAt = db.now()
+	we.UpdatedAt = we.CreatedAt
+
+	if err := we.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := db.findWEStatus(fwt.WEStatusFilter{WorkoutExerciseID: &we.WorkoutExerciseID}); err == nil {
+		return fwt.Errorf(fwt.ECONFLICT, "WEStatus already exists.")
+	}
+
+	we.ID = db.nextID("workout_exercise_status")
+	other := *we
+	db.weStatuses[other.ID] = &other
+
+	return nil
+}
+
+func (db *DB) findWEStatus(filter fwt.WEStatusFilter) (*fwt.WEStatus, error) {
+	a, _ := db.findWEStatuses(filter)
+	if len(a) == 0 {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "WEStatus not found.")
+	}
+	return a[0], nil
+}
+
+func (db *DB) findWEStatuses(filter fwt.WEStatusFilter) ([]*fwt.WEStatus, int) {
+	weStatuses := make([]*fwt.WEStatus, 0)
+	for _, we := range sortedByID(db.weStatuses) {
+		if v := filter.ID; v != nil && we.ID != *v {
+			continue
+		}
+		if v := filter.WorkoutExerciseID; v != nil && we.WorkoutExerciseID != *v {
+			continue
+		}
+		if v := filter.Status; v != nil && we.Status != *v {
+			continue
+		}
+		if v := filter.WorkoutID; v != nil {
+			if workoutExercise, ok := db.workoutExercises[we.WorkoutExerciseID]; !ok || workoutExercise.WorkoutID != *v {
+				continue
+			}
+		}
+
+		other := *we
+		weStatuses = append(weStatuses, &other)
+	}
+
+	return paginate(weStatuses, filter.Limit, filter.Offset)
+}
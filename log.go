@@ -0,0 +1,36 @@
+package fwt
+
+import (
+	"context"
+	"log/slog"
+)
+
+var _ slog.Handler = (*LogHandler)(nil)
+
+// LogHandler adds the request ID and user ID carried by the context to every
+// record so log lines from the same request can be correlated.
+type LogHandler struct {
+	slog.Handler
+}
+
+func NewLogHandler(h slog.Handler) *LogHandler {
+	return &LogHandler{Handler: h}
+}
+
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	if userID := UserIDFromContext(ctx); userID != 0 {
+		r.AddAttrs(slog.Uint64("user_id", uint64(userID)))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,14 @@
+package fwt
+
+// MigrationStatus describes the schema version of a database relative to
+// the migrations embedded in the binary.
+type MigrationStatus struct {
+	Version uint
+	Latest  uint
+	Dirty   bool
+}
+
+// Pending reports whether there are migrations left to apply.
+func (s MigrationStatus) Pending() bool {
+	return s.Version < s.Latest
+}
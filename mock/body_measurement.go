@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.BodyMeasurementService = (*BodyMeasurementService)(nil)
+
+type BodyMeasurementService struct {
+	Recorder
+
+	FindBodyMeasurementByIDFn func(ctx context.Context, id uint) (*fwt.BodyMeasurement, error)
+	FindBodyMeasurementsFn    func(ctx context.Context, filter fwt.BodyMeasurementFilter) ([]*fwt.BodyMeasurement, int, error)
+	CreateBodyMeasurementFn   func(ctx context.Context, m *fwt.BodyMeasurement) error
+	UpdateBodyMeasurementFn   func(ctx context.Context, id uint, upd fwt.BodyMeasurementUpdate) (*fwt.BodyMeasurement, error)
+	DeleteBodyMeasurementFn   func(ctx context.Context, id uint) error
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurementByID(ctx context.Context, id uint) (*fwt.BodyMeasurement, error) {
+	s.record("FindBodyMeasurementByID", id)
+	return s.FindBodyMeasurementByIDFn(ctx, id)
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurements(ctx context.Context, filter fwt.BodyMeasurementFilter) ([]*fwt.BodyMeasurement, int, error) {
+	s.record("FindBodyMeasurements", filter)
+	return s.FindBodyMeasurementsFn(ctx, filter)
+}
+
+func (s *BodyMeasurementService) CreateBodyMeasurement(ctx context.Context, m *fwt.BodyMeasurement) error {
+	s.record("CreateBodyMeasurement", m)
+	return s.CreateBodyMeasurementFn(ctx, m)
+}
+
+func (s *BodyMeasurementService) UpdateBodyMeasurement(ctx context.Context, id uint, upd fwt.BodyMeasurementUpdate) (*fwt.BodyMeasurement, error) {
+	s.record("UpdateBodyMeasurement", id, upd)
+	return s.UpdateBodyMeasurementFn(ctx, id, upd)
+}
+
+func (s *BodyMeasurementService) DeleteBodyMeasurement(ctx context.Context, id uint) error {
+	s.record("DeleteBodyMeasurement", id)
+	return s.DeleteBodyMeasurementFn(ctx, id)
+}
@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.CalendarFeedService = (*CalendarFeedService)(nil)
+
+type CalendarFeedService struct {
+	Recorder
+
+	FindCalendarFeedByTokenFn func(ctx context.Context, token string) (*fwt.CalendarFeed, error)
+	RotateCalendarFeedFn      func(ctx context.Context) (*fwt.CalendarFeed, error)
+}
+
+func (s *CalendarFeedService) FindCalendarFeedByToken(ctx context.Context, token string) (*fwt.CalendarFeed, error) {
+	s.record("FindCalendarFeedByToken", token)
+	return s.FindCalendarFeedByTokenFn(ctx, token)
+}
+
+func (s *CalendarFeedService) RotateCalendarFeed(ctx context.Context) (*fwt.CalendarFeed, error) {
+	s.record("RotateCalendarFeed")
+	return s.RotateCalendarFeedFn(ctx)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.ExerciseService = (*ExerciseService)(nil)
+
+type ExerciseService struct {
+	Recorder
+
+	FindExerciseByIDFn   func(ctx context.Context, id uint) (*fwt.Exercise, error)
+	FindExerciseByNameFn func(ctx context.Context, name string) (*fwt.Exercise, error)
+	FindExercisesFn      func(ctx context.Context, filter fwt.ExerciseFilter) ([]*fwt.Exercise, int, error)
+	CreateExerciseFn     func(ctx context.Context, exercise *fwt.Exercise) error
+}
+
+func (s *ExerciseService) FindExerciseByID(ctx context.Context, id uint) (*fwt.Exercise, error) {
+	s.record("FindExerciseByID", id)
+	return s.FindExerciseByIDFn(ctx, id)
+}
+
+func (s *ExerciseService) FindExerciseByName(ctx context.Context, name string) (*fwt.Exercise, error) {
+	s.record("FindExerciseByName", name)
+	return s.FindExerciseByNameFn(ctx, name)
+}
+
+func (s *ExerciseService) FindExercises(ctx context.Context, filter fwt.ExerciseFilter) ([]*fwt.Exercise, int, error) {
+	s.record("FindExercises", filter)
+	return s.FindExercisesFn(ctx, filter)
+}
+
+func (s *ExerciseService) CreateExercise(ctx context.Context, exercise *fwt.Exercise) error {
+	s.record("CreateExercise", exercise)
+	return s.CreateExerciseFn(ctx, exercise)
+}
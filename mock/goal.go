@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.GoalService = (*GoalService)(nil)
+
+type GoalService struct {
+	Recorder
+
+	FindGoalByIDFn     func(ctx context.Context, id uint) (*fwt.Goal, error)
+	FindGoalsFn        func(ctx context.Context, filter fwt.GoalFilter) ([]*fwt.Goal, int, error)
+	CreateGoalFn       func(ctx context.Context, goal *fwt.Goal) error
+	ArchiveGoalFn      func(ctx context.Context, id uint) (*fwt.Goal, error)
+	FindGoalProgressFn func(ctx context.Context, id uint) (*fwt.GoalProgress, error)
+}
+
+func (s *GoalService) FindGoalByID(ctx context.Context, id uint) (*fwt.Goal, error) {
+	s.record("FindGoalByID", id)
+	return s.FindGoalByIDFn(ctx, id)
+}
+
+func (s *GoalService) FindGoals(ctx context.Context, filter fwt.GoalFilter) ([]*fwt.Goal, int, error) {
+	s.record("FindGoals", filter)
+	return s.FindGoalsFn(ctx, filter)
+}
+
+func (s *GoalService) CreateGoal(ctx context.Context, goal *fwt.Goal) error {
+	s.record("CreateGoal", goal)
+	return s.CreateGoalFn(ctx, goal)
+}
+
+func (s *GoalService) ArchiveGoal(ctx context.Context, id uint) (*fwt.Goal, error) {
+	s.record("ArchiveGoal", id)
+	return s.ArchiveGoalFn(ctx, id)
+}
+
+func (s *GoalService) FindGoalProgress(ctx context.Context, id uint) (*fwt.GoalProgress, error) {
+	s.record("FindGoalProgress", id)
+	return s.FindGoalProgressFn(ctx, id)
+}
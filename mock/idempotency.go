@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.IdempotencyService = (*IdempotencyService)(nil)
+
+type IdempotencyService struct {
+	Recorder
+
+	FindIdempotencyKeyFn   func(ctx context.Context, userID uint, key string) (*fwt.IdempotencyKey, error)
+	CreateIdempotencyKeyFn func(ctx context.Context, key *fwt.IdempotencyKey) error
+	UpdateIdempotencyKeyFn func(ctx context.Context, id uint, upd fwt.IdempotencyKeyUpdate) (*fwt.IdempotencyKey, error)
+	DeleteIdempotencyKeyFn func(ctx context.Context, id uint) error
+}
+
+func (s *IdempotencyService) FindIdempotencyKey(ctx context.Context, userID uint, key string) (*fwt.IdempotencyKey, error) {
+	s.record("FindIdempotencyKey", userID, key)
+	return s.FindIdempotencyKeyFn(ctx, userID, key)
+}
+
+func (s *IdempotencyService) CreateIdempotencyKey(ctx context.Context, key *fwt.IdempotencyKey) error {
+	s.record("CreateIdempotencyKey", key)
+	return s.CreateIdempotencyKeyFn(ctx, key)
+}
+
+func (s *IdempotencyService) UpdateIdempotencyKey(ctx context.Context, id uint, upd fwt.IdempotencyKeyUpdate) (*fwt.IdempotencyKey, error) {
+	s.record("UpdateIdempotencyKey", id, upd)
+	return s.UpdateIdempotencyKeyFn(ctx, id, upd)
+}
+
+func (s *IdempotencyService) DeleteIdempotencyKey(ctx context.Context, id uint) error {
+	s.record("DeleteIdempotencyKey", id)
+	return s.DeleteIdempotencyKeyFn(ctx, id)
+}
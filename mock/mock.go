@@ -0,0 +1,122 @@
+// Package mock provides function-field implementations of the fwt service
+// interfaces for tests. Every mock records the calls made to it so tests
+// can assert which service methods a handler used.
+package mock
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Call is a single recorded invocation. Args holds every argument except
+// the context.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Recorder keeps the calls made to a mock. It is embedded in every mock and
+// is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *Recorder) record(method string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns all recorded calls in the order they were made.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the recorded calls to method.
+func (r *Recorder) CallsTo(method string) []Call {
+	var a []Call
+	for _, call := range r.Calls() {
+		if call.Method == method {
+			a = append(a, call)
+		}
+	}
+	return a
+}
+
+// CallCount returns the number of calls made to method.
+func (r *Recorder) CallCount(method string) int {
+	return len(r.CallsTo(method))
+}
+
+// Reset forgets all recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+// AssertCalled fails the test unless method was called at least once. When
+// args are given, one of the calls must have exactly those arguments.
+func (r *Recorder) AssertCalled(tb testing.TB, method string, args ...any) {
+	tb.Helper()
+
+	calls := r.CallsTo(method)
+	if len(calls) == 0 {
+		tb.Errorf("expected %s to be called", method)
+		return
+	} else if len(args) == 0 {
+		return
+	}
+
+	for _, call := range calls {
+		if reflect.DeepEqual(call.Args, args) {
+			return
+		}
+	}
+	tb.Errorf("expected %s to be called with %s, got %s", method, formatArgs(args), formatCalls(calls))
+}
+
+// AssertNotCalled fails the test if method was called.
+func (r *Recorder) AssertNotCalled(tb testing.TB, method string) {
+	tb.Helper()
+
+	if calls := r.CallsTo(method); len(calls) > 0 {
+		tb.Errorf("expected %s not to be called, got %s", method, formatCalls(calls))
+	}
+}
+
+// AssertCallCount fails the test unless method was called exactly n times.
+func (r *Recorder) AssertCallCount(tb testing.TB, method string, n int) {
+	tb.Helper()
+
+	if got := r.CallCount(method); got != n {
+		tb.Errorf("expected %s to be called %d times, got %d", method, n, got)
+	}
+}
+
+func formatArgs(args []any) string {
+	s := "("
+	for i, arg := range args {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%#v", arg)
+	}
+	return s + ")"
+}
+
+func formatCalls(calls []Call) string {
+	s := ""
+	for i, call := range calls {
+		if i > 0 {
+			s += ", "
+		}
+		s += formatArgs(call.Args)
+	}
+	return s
+}
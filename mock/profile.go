@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.ProfileService = (*ProfileService)(nil)
+
+type ProfileService struct {
+	Recorder
+
+	FindProfileByIDFn     func(ctx context.Context, id uint) (*fwt.Profile, error)
+	FindProfileByUserIDFn func(ctx context.Context, userID uint) (*fwt.Profile, error)
+	FindProfilesFn        func(ctx context.Context, filter fwt.ProfileFilter) ([]*fwt.Profile, int, error)
+	CreateProfileFn       func(ctx context.Context, profile *fwt.Profile) error
+	UpdateProfileFn       func(ctx context.Context, id uint, upd fwt.ProfileUpdate) (*fwt.Profile, error)
+	DeleteProfileFn       func(ctx context.Context, id uint, version *uint) error
+}
+
+func (s *ProfileService) FindProfileByID(ctx context.Context, id uint) (*fwt.Profile, error) {
+	s.record("FindProfileByID", id)
+	return s.FindProfileByIDFn(ctx, id)
+}
+
+func (s *ProfileService) FindProfileByUserID(ctx context.Context, userID uint) (*fwt.Profile, error) {
+	s.record("FindProfileByUserID", userID)
+	return s.FindProfileByUserIDFn(ctx, userID)
+}
+
+func (s *ProfileService) FindProfiles(ctx context.Context, filter fwt.ProfileFilter) ([]*fwt.Profile, int, error) {
+	s.record("FindProfiles", filter)
+	return s.FindProfilesFn(ctx, filter)
+}
+
+func (s *ProfileService) CreateProfile(ctx context.Context, profile *fwt.Profile) error {
+	s.record("CreateProfile", profile)
+	return s.CreateProfileFn(ctx, profile)
+}
+
+func (s *ProfileService) UpdateProfile(ctx context.Context, id uint, upd fwt.ProfileUpdate) (*fwt.Profile, error) {
+	s.record("UpdateProfile", id, upd)
+	return s.UpdateProfileFn(ctx, id, upd)
+}
+
+func (s *ProfileService) DeleteProfile(ctx context.Context, id uint, version *uint) error {
+	s.record("DeleteProfile", id, version)
+	return s.DeleteProfileFn(ctx, id, version)
+}
@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/maliByatzes/fwt/token"
+)
+
+var _ token.Maker = (*TokenMaker)(nil)
+
+type TokenMaker struct {
+	Recorder
+
+	CreateTokenFn func(id uint, username string, duration time.Duration) (string, *token.Payload, error)
+	VerifyTokenFn func(token string) (*token.Payload, error)
+}
+
+func (m *TokenMaker) CreateToken(id uint, username string, duration time.Duration) (string, *token.Payload, error) {
+	m.record("CreateToken", id, username, duration)
+	return m.CreateTokenFn(id, username, duration)
+}
+
+func (m *TokenMaker) VerifyToken(token string) (*token.Payload, error) {
+	m.record("VerifyToken", token)
+	return m.VerifyTokenFn(token)
+}
@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.TxRunner = (*TxRunner)(nil)
+
+// TxRunner runs fn directly when RunInTxFn or RunInTxOptionsFn is not set.
+type TxRunner struct {
+	Recorder
+
+	RunInTxFn        func(ctx context.Context, fn func(ctx context.Context) error) error
+	RunInTxOptionsFn func(ctx context.Context, opts fwt.TxOptions, fn func(ctx context.Context) error) error
+}
+
+func (r *TxRunner) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.record("RunInTx")
+	if r.RunInTxFn == nil {
+		return fn(ctx)
+	}
+	return r.RunInTxFn(ctx, fn)
+}
+
+func (r *TxRunner) RunInTxOptions(ctx context.Context, opts fwt.TxOptions, fn func(ctx context.Context) error) error {
+	r.record("RunInTxOptions", opts)
+	if r.RunInTxOptionsFn == nil {
+		return fn(ctx)
+	}
+	return r.RunInTxOptionsFn(ctx, opts, fn)
+}
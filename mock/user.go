@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/maliByatzes/fwt"
 )
@@ -9,34 +10,61 @@ import (
 var _ fwt.UserService = (*UserService)(nil)
 
 type UserService struct {
+	Recorder
+
 	FindUserbyIDFn func(ctx context.Context, id uint) (*fwt.User, error)
 	AuthenticateFn func(ctx context.Context, username, password string) (*fwt.User, error)
 	FindUsersFn    func(ctx context.Context, filter fwt.UserFilter) ([]*fwt.User, int, error)
 	CreateUserFn   func(ctx context.Context, user *fwt.User) error
 	UpdateUserFn   func(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error)
 	DeleteUserFn   func(ctx context.Context, id uint) error
+
+	ScheduleUserDeletionFn func(ctx context.Context, id uint) (*fwt.User, error)
+	RestoreUserFn          func(ctx context.Context, id uint) (*fwt.User, error)
+	PurgeDeletedUserFn     func(ctx context.Context, id uint, before time.Time) error
 }
 
 func (s *UserService) FindUserByID(ctx context.Context, id uint) (*fwt.User, error) {
+	s.record("FindUserByID", id)
 	return s.FindUserbyIDFn(ctx, id)
 }
 
 func (s *UserService) Authenticate(ctx context.Context, username, password string) (*fwt.User, error) {
+	s.record("Authenticate", username, password)
 	return s.AuthenticateFn(ctx, username, password)
 }
 
 func (s *UserService) FindUsers(ctx context.Context, filter fwt.UserFilter) ([]*fwt.User, int, error) {
+	s.record("FindUsers", filter)
 	return s.FindUsersFn(ctx, filter)
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user *fwt.User) error {
+	s.record("CreateUser", user)
 	return s.CreateUserFn(ctx, user)
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+	s.record("UpdateUser", id, upd)
 	return s.UpdateUserFn(ctx, id, upd)
 }
 
 func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	s.record("DeleteUser", id)
 	return s.DeleteUserFn(ctx, id)
 }
+
+func (s *UserService) ScheduleUserDeletion(ctx context.Context, id uint) (*fwt.User, error) {
+	s.record("ScheduleUserDeletion", id)
+	return s.ScheduleUserDeletionFn(ctx, id)
+}
+
+func (s *UserService) RestoreUser(ctx context.Context, id uint) (*fwt.User, error) {
+	s.record("RestoreUser", id)
+	return s.RestoreUserFn(ctx, id)
+}
+
+func (s *UserService) PurgeDeletedUser(ctx context.Context, id uint, before time.Time) error {
+	s.record("PurgeDeletedUser", id, before)
+	return s.PurgeDeletedUserFn(ctx, id, before)
+}
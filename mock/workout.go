@@ -0,0 +1,92 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WorkoutService = (*WorkoutService)(nil)
+
+type WorkoutService struct {
+	Recorder
+
+	FindWorkoutByIDFn            func(ctx context.Context, id uint) (*fwt.Workout, error)
+	FindWorkoutByIDUserIDFn      func(ctx context.Context, id uint, userID uint) (*fwt.Workout, error)
+	FindWorkoutsFn               func(ctx context.Context, filter fwt.WorkoutFilter) ([]*fwt.Workout, int, error)
+	CreateWorkoutFn              func(ctx context.Context, workout *fwt.Workout) error
+	UpdateWorkoutFn              func(ctx context.Context, id uint, upd fwt.WorkoutUpdate) (*fwt.Workout, error)
+	RemoveExercisesFromWorkoutFn func(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error)
+	AddExercisesToWorkoutFn      func(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error)
+	DeleteWorkoutFn              func(ctx context.Context, id uint, version *uint) error
+	StartWorkoutFn               func(ctx context.Context, id uint) (*fwt.Workout, error)
+	FinishWorkoutFn              func(ctx context.Context, id uint) (*fwt.Workout, error)
+	SkipWorkoutFn                func(ctx context.Context, id uint) (*fwt.Workout, error)
+	LogWorkoutFn                 func(ctx context.Context, workout *fwt.Workout) error
+	QuickStartWorkoutFn          func(ctx context.Context, workout *fwt.Workout) error
+}
+
+func (s *WorkoutService) FindWorkoutByID(ctx context.Context, id uint) (*fwt.Workout, error) {
+	s.record("FindWorkoutByID", id)
+	return s.FindWorkoutByIDFn(ctx, id)
+}
+
+func (s *WorkoutService) FindWorkoutByIDUserID(ctx context.Context, id uint, userID uint) (*fwt.Workout, error) {
+	s.record("FindWorkoutByIDUserID", id, userID)
+	return s.FindWorkoutByIDUserIDFn(ctx, id, userID)
+}
+
+func (s *WorkoutService) FindWorkouts(ctx context.Context, filter fwt.WorkoutFilter) ([]*fwt.Workout, int, error) {
+	s.record("FindWorkouts", filter)
+	return s.FindWorkoutsFn(ctx, filter)
+}
+
+func (s *WorkoutService) CreateWorkout(ctx context.Context, workout *fwt.Workout) error {
+	s.record("CreateWorkout", workout)
+	return s.CreateWorkoutFn(ctx, workout)
+}
+
+func (s *WorkoutService) UpdateWorkout(ctx context.Context, id uint, upd fwt.WorkoutUpdate) (*fwt.Workout, error) {
+	s.record("UpdateWorkout", id, upd)
+	return s.UpdateWorkoutFn(ctx, id, upd)
+}
+
+func (s *WorkoutService) RemoveExercisesFromWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	s.record("RemoveExercisesFromWorkout", id, exercises)
+	return s.RemoveExercisesFromWorkoutFn(ctx, id, exercises)
+}
+
+func (s *WorkoutService) AddExercisesToWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	s.record("AddExercisesToWorkout", id, exercises)
+	return s.AddExercisesToWorkoutFn(ctx, id, exercises)
+}
+
+func (s *WorkoutService) DeleteWorkout(ctx context.Context, id uint, version *uint) error {
+	s.record("DeleteWorkout", id, version)
+	return s.DeleteWorkoutFn(ctx, id, version)
+}
+
+func (s *WorkoutService) StartWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	s.record("StartWorkout", id)
+	return s.StartWorkoutFn(ctx, id)
+}
+
+func (s *WorkoutService) FinishWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	s.record("FinishWorkout", id)
+	return s.FinishWorkoutFn(ctx, id)
+}
+
+func (s *WorkoutService) SkipWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	s.record("SkipWorkout", id)
+	return s.SkipWorkoutFn(ctx, id)
+}
+
+func (s *WorkoutService) LogWorkout(ctx context.Context, workout *fwt.Workout) error {
+	s.record("LogWorkout", workout)
+	return s.LogWorkoutFn(ctx, workout)
+}
+
+func (s *WorkoutService) QuickStartWorkout(ctx context.Context, workout *fwt.Workout) error {
+	s.record("QuickStartWorkout", workout)
+	return s.QuickStartWorkoutFn(ctx, workout)
+}
@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WorkoutExerciseService = (*WorkoutExerciseService)(nil)
+
+type WorkoutExerciseService struct {
+	Recorder
+
+	FindWorkoutExerciseByIDFn func(ctx context.Context, id uint) (*fwt.WorkoutExercise, error)
+	FindWorkoutExercisesFn    func(ctx context.Context, filter fwt.WorkoutExerciseFilter) ([]*fwt.WorkoutExercise, int, error)
+	CreateWorkoutExerciseFn   func(ctx context.Context, workoutExercise *fwt.WorkoutExercise) error
+	UpdateWorkoutExerciseFn   func(ctx context.Context, id uint, upd fwt.WorkoutExerciseUpdate) (*fwt.WorkoutExercise, error)
+	DeleteWorkoutExerciseFn   func(ctx context.Context, id uint) error
+}
+
+func (s *WorkoutExerciseService) FindWorkoutExerciseByID(ctx context.Context, id uint) (*fwt.WorkoutExercise, error) {
+	s.record("FindWorkoutExerciseByID", id)
+	return s.FindWorkoutExerciseByIDFn(ctx, id)
+}
+
+func (s *WorkoutExerciseService) FindWorkoutExercises(ctx context.Context, filter fwt.WorkoutExerciseFilter) ([]*fwt.WorkoutExercise, int, error) {
+	s.record("FindWorkoutExercises", filter)
+	return s.FindWorkoutExercisesFn(ctx, filter)
+}
+
+func (s *WorkoutExerciseService) CreateWorkoutExercise(ctx context.Context, workoutExercise *fwt.WorkoutExercise) error {
+	s.record("CreateWorkoutExercise", workoutExercise)
+	return s.CreateWorkoutExerciseFn(ctx, workoutExercise)
+}
+
+func (s *WorkoutExerciseService) UpdateWorkoutExercise(ctx context.Context, id uint, upd fwt.WorkoutExerciseUpdate) (*fwt.WorkoutExercise, error) {
+	s.record("UpdateWorkoutExercise", id, upd)
+	return s.UpdateWorkoutExerciseFn(ctx, id, upd)
+}
+
+func (s *WorkoutExerciseService) DeleteWorkoutExercise(ctx context.Context, id uint) error {
+	s.record("DeleteWorkoutExercise", id)
+	return s.DeleteWorkoutExerciseFn(ctx, id)
+}
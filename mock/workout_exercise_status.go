@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WEStatusService = (*WEStatusService)(nil)
+
+type WEStatusService struct {
+	Recorder
+
+	FindWEStatusByIDFn   func(ctx context.Context, id uint) (*fwt.WEStatus, error)
+	FindWEStatusByWEIDFn func(ctx context.Context, id uint) (*fwt.WEStatus, error)
+	FindWEStatusesFn     func(ctx context.Context, filter fwt.WEStatusFilter) ([]*fwt.WEStatus, int, error)
+	CreateWEStatusFn     func(ctx context.Context, we *fwt.WEStatus) error
+	UpdateWEStatusFn     func(ctx context.Context, id uint, upd fwt.WEStatusUpdate) (*fwt.WEStatus, error)
+	DeleteWEStatusFn     func(ctx context.Context, id uint) error
+}
+
+func (s *WEStatusService) FindWEStatusByID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
+	s.record("FindWEStatusByID", id)
+	return s.FindWEStatusByIDFn(ctx, id)
+}
+
+func (s *WEStatusService) FindWEStatusByWEID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
+	s.record("FindWEStatusByWEID", id)
+	return s.FindWEStatusByWEIDFn(ctx, id)
+}
+
+func (s *WEStatusService) FindWEStatuses(ctx context.Context, filter fwt.WEStatusFilter) ([]*fwt.WEStatus, int, error) {
+	s.record("FindWEStatuses", filter)
+	return s.FindWEStatusesFn(ctx, filter)
+}
+
+func (s *WEStatusService) CreateWEStatus(ctx context.Context, we *fwt.WEStatus) error {
+	s.record("CreateWEStatus", we)
+	return s.CreateWEStatusFn(ctx, we)
+}
+
+func (s *WEStatusService) UpdateWEStatus(ctx context.Context, id uint, upd fwt.WEStatusUpdate) (*fwt.WEStatus, error) {
+	s.record("UpdateWEStatus", id, upd)
+	return s.UpdateWEStatusFn(ctx, id, upd)
+}
+
+func (s *WEStatusService) DeleteWEStatus(ctx context.Context, id uint) error {
+	s.record("DeleteWEStatus", id)
+	return s.DeleteWEStatusFn(ctx, id)
+}
@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.BodyMeasurementService = (*BodyMeasurementService)(nil)
+
+type BodyMeasurementService struct {
+	db *DB
+}
+
+func NewBodyMeasurementService(db *DB) *BodyMeasurementService {
+	return &BodyMeasurementService{db: db}
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurementByID(ctx context.Context, id uint) (m *fwt.BodyMeasurement, err error) {
+	err = s.db.run(ctx, "BodyMeasurementService.FindBodyMeasurementByID", readTx, func(tx *Tx) error {
+		m, err = findBodyMeasurementByID(ctx, tx, id)
+		return err
+	})
+	return m, err
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurements(ctx context.Context, filter fwt.BodyMeasurementFilter) (a []*fwt.BodyMeasurement, n int, err error) {
+	err = s.db.run(ctx, "BodyMeasurementService.FindBodyMeasurements", readTx, func(tx *Tx) error {
+		a, n, err = findBodyMeasurements(ctx, tx, filter)
+		return err
+	})
+	return a, n, err
+}
+
+func (s *BodyMeasurementService) CreateBodyMeasurement(ctx context.Context, m *fwt.BodyMeasurement) error {
+	return s.db.run(ctx, "BodyMeasurementService.CreateBodyMeasurement", insertTx, func(tx *Tx) error {
+		if err := createBodyMeasurement(ctx, tx, m); err != nil {
+			return err
+		}
+		return syncProfileWeight(ctx, tx, m.UserID)
+	})
+}
+
+func (s *BodyMeasurementService) UpdateBodyMeasurement(ctx context.Context, id uint, upd fwt.BodyMeasurementUpdate) (m *fwt.BodyMeasurement, err error) {
+	err = s.db.run(ctx, "BodyMeasurementService.UpdateBodyMeasurement", updateTx, func(tx *Tx) error {
+		if m, err = updateBodyMeasurement(ctx, tx, id, upd); err != nil {
+			return err
+		}
+		return syncProfileWeight(ctx, tx, m.UserID)
+	})
+	return m, err
+}
+
+func (s *BodyMeasurementService) DeleteBodyMeasurement(ctx context.Context, id uint) error {
+	return s.db.run(ctx, "BodyMeasurementService.DeleteBodyMeasurement", updateTx, func(tx *Tx) error {
+		if err := deleteBodyMeasurement(ctx, tx, id); err != nil {
+			return err
+		}
+		return syncProfileWeight(ctx, tx, fwt.UserIDFromContext(ctx))
+	})
+}
+
+func createBodyMeasurement(ctx context.Context, tx *Tx, m *fwt.BodyMeasurement) error {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to record a body measurement.")
+	}
+	m.UserID = userID
+
+	if m.MeasuredAt.IsZero() {
+		m.MeasuredAt = tx.now
+	}
+	m.CreatedAt = tx.now
+	m.UpdatedAt = m.CreatedAt
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO body_measurement (user_id, measured_at, weight, body_fat, waist, chest, arms, thighs, resting_heart_rate, notes, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id
+	`
+	args := []interface{}{
+		m.UserID,
+		(*NullTime)(&m.MeasuredAt),
+		m.Weight,
+		m.BodyFat,
+		m.Waist,
+		m.Chest,
+		m.Arms,
+		m.Thighs,
+		m.RestingHeartRate,
+		m.Notes,
+		(*NullTime)(&m.CreatedAt),
+		(*NullTime)(&m.UpdatedAt),
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&m.ID)
+}
+
+func findBodyMeasurementByID(ctx context.Context, tx *Tx, id uint) (*fwt.BodyMeasurement, error) {
+	a, _, err := findBodyMeasurements(ctx, tx, fwt.BodyMeasurementFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Body Measurement not found."}
+	}
+	return a[0], nil
+}
+
+func findBodyMeasurements(ctx context.Context, tx *Tx, filter fwt.BodyMeasurementFilter) (_ []*fwt.BodyMeasurement, n int, err error) {
+	where, args := []string{}, []interface{}{}
+	argPos := 0
+
+	if v := filter.ID; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("id = $%d", argPos)), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("user_id = $%d", argPos)), append(args, *v)
+	}
+	if v := filter.From; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("measured_at >= $%d", argPos)), append(args, (*NullTime)(v))
+	}
+	if v := filter.To; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("measured_at <= $%d", argPos)), append(args, (*NullTime)(v))
+	}
+
+	query := `
+	SELECT id, user_id, measured_at, weight, body_fat, waist, chest, arms, thighs, resting_heart_rate, notes, created_at, updated_at, COUNT(*) OVER()
+	FROM body_measurement` + formatWhereClause(where) + ` ORDER BY measured_at ASC, id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	measurements := make([]*fwt.BodyMeasurement, 0)
+	for rows.Next() {
+		var m fwt.BodyMeasurement
+		if err := rows.Scan(
+			&m.ID,
+			&m.UserID,
+			(*NullTime)(&m.MeasuredAt),
+			&m.Weight,
+			&m.BodyFat,
+			&m.Waist,
+			&m.Chest,
+			&m.Arms,
+			&m.Thighs,
+			&m.RestingHeartRate,
+			&m.Notes,
+			(*NullTime)(&m.CreatedAt),
+			(*NullTime)(&m.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		measurements = append(measurements, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return measurements, n, nil
+}
+
+func updateBodyMeasurement(ctx context.Context, tx *Tx, id uint, upd fwt.BodyMeasurementUpdate) (*fwt.BodyMeasurement, error) {
+	m, err := findBodyMeasurementByID(ctx, tx, id)
+	if err != nil {
+		return m, err
+	} else if m.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this body measurement.")
+	}
+
+	if v := upd.MeasuredAt; v != nil {
+		m.MeasuredAt = *v
+	}
+	if v := upd.Weight; v != nil {
+		m.Weight = *v
+	}
+	if v := upd.BodyFat; v != nil {
+		m.BodyFat = *v
+	}
+	if v := upd.Waist; v != nil {
+		m.Waist = *v
+	}
+	if v := upd.Chest; v != nil {
+		m.Chest = *v
+	}
+	if v := upd.Arms; v != nil {
+		m.Arms = *v
+	}
+	if v := upd.Thighs; v != nil {
+		m.Thighs = *v
+	}
+	if v := upd.RestingHeartRate; v != nil {
+		m.RestingHeartRate = *v
+	}
+	if v := upd.Notes; v != nil {
+		m.Notes = *v
+	}
+	m.UpdatedAt = tx.now
+
+	if err := m.Validate(); err != nil {
+		return m, err
+	}
+
+	query := `
+	UPDATE body_measurement SET measured_at = $1, weight = $2, body_fat = $3, waist = $4, chest = $5, arms = $6, thighs = $7, resting_heart_rate = $8, notes = $9, updated_at = $10
+	WHERE id = $11
+	`
+	args := []interface{}{
+		(*NullTime)(&m.MeasuredAt),
+		m.Weight,
+		m.BodyFat,
+		m.Waist,
+		m.Chest,
+		m.Arms,
+		m.Thighs,
+		m.RestingHeartRate,
+		m.Notes,
+		(*NullTime)(&m.UpdatedAt),
+		m.ID,
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+func deleteBodyMeasurement(ctx context.Context, tx *Tx, id uint) error {
+	m, err := findBodyMeasurementByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if m.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this body measurement.")
+	}
+
+	query := `
+	DELETE FROM body_measurement WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, m.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// syncProfileWeight sets the weight of the user's profile, if any, to the
+// most recent measured weight, or zero when no weight has been measured.
+func syncProfileWeight(ctx context.Context, tx *Tx, userID uint) error {
+	weight, err := findLatestWeight(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE profile SET weight = $1, updated_at = $2, version = version + 1
+	WHERE user_id = $3 AND weight IS DISTINCT FROM $1
+	`
+	if _, err := tx.ExecContext(ctx, query, weight, (*NullTime)(&tx.now), userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findLatestWeight returns the most recent measured weight of the user, or
+// zero when no weight has been measured.
+func findLatestWeight(ctx context.Context, tx *Tx, userID uint) (float64, error) {
+	var weight float64
+	query := `
+	SELECT weight FROM body_measurement
+	WHERE user_id = $1 AND weight > 0
+	ORDER BY measured_at DESC, id DESC LIMIT 1
+	`
+	if err := tx.QueryRowxContext(ctx, query, userID).Scan(&weight); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	return weight, nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.CalendarFeedService = (*CalendarFeedService)(nil)
+
+type CalendarFeedService struct {
+	db *DB
+}
+
+func NewCalendarFeedService(db *DB) *CalendarFeedService {
+	return &CalendarFeedService{db: db}
+}
+
+func (s *CalendarFeedService) FindCalendarFeedByToken(ctx context.Context, token string) (feed *fwt.CalendarFeed, err error) {
+	err = s.db.run(ctx, "CalendarFeedService.FindCalendarFeedByToken", readTx, func(tx *Tx) error {
+		feed, err = findCalendarFeedByToken(ctx, tx, token)
+		return err
+	})
+	return feed, err
+}
+
+func (s *CalendarFeedService) RotateCalendarFeed(ctx context.Context) (feed *fwt.CalendarFeed, err error) {
+	err = s.db.run(ctx, "CalendarFeedService.RotateCalendarFeed", updateTx, func(tx *Tx) error {
+		feed, err = rotateCalendarFeed(ctx, tx)
+		return err
+	})
+	return feed, err
+}
+
+func findCalendarFeedByToken(ctx context.Context, tx *Tx, token string) (*fwt.CalendarFeed, error) {
+	query := `
+	SELECT id, user_id, token_hash, created_at FROM calendar_feed WHERE token_hash = $1
+	`
+	var feed fwt.CalendarFeed
+	if err := tx.QueryRowxContext(ctx, query, fwt.HashCalendarFeedToken(token)).Scan(
+		&feed.ID,
+		&feed.UserID,
+		&feed.TokenHash,
+		(*NullTime)(&feed.CreatedAt),
+	); err == sql.ErrNoRows {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "Calendar feed not found.")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// rotateCalendarFeed replaces the feed of the current user, so that the
+// previous token no longer finds it.
+func rotateCalendarFeed(ctx context.Context, tx *Tx) (*fwt.CalendarFeed, error) {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to rotate a calendar feed.")
+	}
+
+	token, hash, err := fwt.NewCalendarFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	feed := &fwt.CalendarFeed{UserID: userID, Token: token, TokenHash: hash, CreatedAt: tx.now}
+
+	query := `
+	DELETE FROM calendar_feed WHERE user_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, feed.UserID); err != nil {
+		return nil, err
+	}
+
+	query = `
+	INSERT INTO calendar_feed (user_id, token_hash, created_at)
+	VALUES ($1, $2, $3) RETURNING id
+	`
+	if err := tx.QueryRowxContext(ctx, query, feed.UserID, feed.TokenHash, (*NullTime)(&feed.CreatedAt)).Scan(&feed.ID); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
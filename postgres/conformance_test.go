@@ -0,0 +1,45 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt/fwttest"
+	"github.com/maliByatzes/fwt/postgres"
+)
+
+func TestConformance(t *testing.T) {
+	fwttest.TestServices(t, func(tb testing.TB) *fwttest.Services {
+		db := MustOpenDB(tb)
+		tb.Cleanup(func() { MustCloseDB(tb, db) })
+
+		return &fwttest.Services{
+			UserService:            postgres.NewUserService(db),
+			ProfileService:         postgres.NewProfileService(db),
+			WorkoutService:         postgres.NewWorkoutService(db),
+			ExerciseService:        postgres.NewExerciseService(db),
+			WorkoutExerciseService: postgres.NewWorkoutExerciseService(db),
+			WEStatusService:        postgres.NewWEStatusService(db),
+			BodyMeasurementService: postgres.NewBodyMeasurementService(db),
+			GoalService:            postgres.NewGoalService(db),
+			CalendarFeedService:    postgres.NewCalendarFeedService(db),
+			IdempotencyService:     postgres.NewIdempotencyService(db),
+			TxRunner:               db,
+			SetNow:                 func(now func() time.Time) { db.Now = now },
+			CountRows:              func(table string) (int, error) { return countRows(db, table) },
+		}
+	})
+}
+
+// countRows returns the number of rows in table.
+func countRows(db *postgres.DB, table string) (n int, err error) {
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(context.Background(), &n, `SELECT COUNT(*) FROM "`+table+`"`)
+	return n, err
+}
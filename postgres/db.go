@@ -4,12 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/rand/v2"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/maliByatzes/fwt"
+	"github.com/maliByatzes/fwt/metrics"
+	"github.com/maliByatzes/fwt/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DB struct {
@@ -18,12 +28,32 @@ type DB struct {
 	cancel func()
 	DSN    string
 	Now    func() time.Time
+	Logger *slog.Logger
+
+	// Isolation overrides the isolation level of individual service
+	// methods, keyed by "<Service>.<Method>". A method that joins the
+	// transaction of a RunInTx call fails unless that transaction runs at
+	// its level or a stricter one.
+	Isolation map[string]sql.IsolationLevel
+
+	// MaxRetries bounds how often a retryable transaction is rerun after a
+	// serialization failure or deadlock, waiting RetryBackoff before the
+	// first retry and twice as long before each following one.
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	unregisterStats func()
 }
 
 func NewDB(dsn string) *DB {
 	db := &DB{
-		DSN: dsn,
-		Now: time.Now,
+		DSN:    dsn,
+		Now:    time.Now,
+		Logger: slog.Default(),
+
+		Isolation:    DefaultIsolationLevels(),
+		MaxRetries:   3,
+		RetryBackoff: 10 * time.Millisecond,
 	}
 	db.ctx, db.cancel = context.WithCancel(context.Background())
 	return db
@@ -43,32 +73,341 @@ func (db *DB) Open() (err error) {
 		return err
 	}
 
-	log.Println("🚀 Connected to database successfully")
+	db.unregisterStats = metrics.RegisterDBStats(db.DB.DB, "postgres")
+
+	db.Logger.Info("🚀 Connected to database successfully")
 
 	return nil
 }
 
 func (db *DB) Close() error {
 	db.cancel()
+	if db.unregisterStats != nil {
+		db.unregisterStats()
+	}
 	if db.DB != nil {
 		return db.DB.Close()
 	}
 	return nil
 }
 
-func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) *Tx {
-	tx := db.DB.MustBeginTx(ctx, opts)
-	return &Tx{
-		Tx:  tx,
-		db:  db,
-		now: db.Now().UTC().Truncate(time.Second),
+// Ping verifies that the database is reachable.
+func (db *DB) Ping(ctx context.Context) error {
+	if db.DB == nil {
+		return fmt.Errorf("database is not open")
+	}
+	return db.DB.PingContext(ctx)
+}
+
+// SchemaVersion returns the migration version recorded by golang-migrate
+// and whether the last migration failed part way through.
+func (db *DB) SchemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	if db.DB == nil {
+		return 0, false, fmt.Errorf("database is not open")
+	}
+	err = db.DB.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+var _ fwt.TxRunner = (*DB)(nil)
+
+type txContextKey struct{}
+
+// TxOptions configures a transaction started by RunInTxOptions.
+type TxOptions = fwt.TxOptions
+
+// Options used by the services. Inserts are not retried so that a failure
+// never surfaces as a duplicate row to the caller.
+var (
+	readTx   = TxOptions{ReadOnly: true, Retry: true}
+	updateTx = TxOptions{Retry: true}
+	insertTx = TxOptions{}
+)
+
+// DefaultIsolationLevels returns the isolation levels of the service methods
+// that must not run at the default READ COMMITTED level.
+func DefaultIsolationLevels() map[string]sql.IsolationLevel {
+	return map[string]sql.IsolationLevel{
+		"UserService.DeleteUser":       sql.LevelSerializable,
+		"WorkoutService.UpdateWorkout": sql.LevelRepeatableRead,
+		"WorkoutService.DeleteWorkout": sql.LevelRepeatableRead,
+	}
+}
+
+// ParseIsolationLevels parses a comma separated list of
+// "<Service>.<Method>=<level>" pairs, e.g.
+// "WorkoutService.UpdateWorkout=serializable".
+func ParseIsolationLevels(s string) (map[string]sql.IsolationLevel, error) {
+	levels := make(map[string]sql.IsolationLevel)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		method, name, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid isolation level %q: expected <Service>.<Method>=<level>", pair)
+		}
+
+		level, ok := isolationLevels[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("invalid isolation level %q: unknown level", pair)
+		}
+		levels[strings.TrimSpace(method)] = level
 	}
+	return levels, nil
+}
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"default":         sql.LevelDefault,
+	"read committed":  sql.LevelReadCommitted,
+	"repeatable read": sql.LevelRepeatableRead,
+	"serializable":    sql.LevelSerializable,
+}
+
+// BeginTx starts a transaction, or joins the one carried by ctx when called
+// from within RunInTx. Committing or rolling back a joined transaction is a
+// no-op; the outcome is decided by RunInTx.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if outer, ok := ctx.Value(txContextKey{}).(*Tx); ok && outer.db == db {
+		return &Tx{Tx: outer.Tx, db: db, now: outer.now, joined: true}, nil
+	}
+
+	if db.DB == nil {
+		return nil, fmt.Errorf("database is not open")
+	}
+
+	tx, err := db.DB.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tx{
+		Tx:    tx,
+		db:    db,
+		now:   db.Now().UTC().Truncate(time.Second),
+		start: time.Now(),
+	}
+	if opts != nil {
+		t.isolation = opts.Isolation
+	}
+	return t, nil
+}
+
+// RunInTx implements fwt.TxRunner. fn is run once; a serialization failure
+// is returned to the caller rather than retried.
+func (db *DB) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.RunInTxOptions(ctx, TxOptions{}, fn)
+}
+
+// RunInTxOptions is like RunInTx but starts the transaction with opts. The
+// options are ignored when ctx already carries a transaction, and a joined
+// transaction is never retried as the outer one is already aborted.
+func (db *DB) RunInTxOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	return db.runTx(ctx, opts, func(ctx context.Context, tx *Tx) error {
+		return fn(ctx)
+	})
+}
+
+// run executes fn within a transaction for the named service method, using
+// the isolation level configured for it in db.Isolation. When ctx carries a
+// transaction that runs at a weaker level, fn is not run at all as the level
+// cannot be raised once the transaction has started.
+func (db *DB) run(ctx context.Context, method string, opts TxOptions, fn func(tx *Tx) error) error {
+	if level, ok := db.Isolation[method]; ok {
+		opts.Isolation = level
+	}
+	if outer, ok := ctx.Value(txContextKey{}).(*Tx); ok && outer.db == db && isolationRank(outer.isolation) < isolationRank(opts.Isolation) {
+		return fmt.Errorf("postgres: %s requires %s isolation but joined a %s transaction", method, opts.Isolation, isolationRank(outer.isolation))
+	}
+	return db.runTx(ctx, opts, func(ctx context.Context, tx *Tx) error {
+		return fn(tx)
+	})
+}
+
+func (db *DB) runTx(ctx context.Context, opts TxOptions, fn func(ctx context.Context, tx *Tx) error) error {
+	if outer, ok := ctx.Value(txContextKey{}).(*Tx); ok && outer.db == db {
+		return fn(ctx, outer)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := db.runTxOnce(ctx, opts, fn)
+		if err == nil || !opts.Retry || attempt >= db.MaxRetries || !isRetryable(err) {
+			return err
+		}
+
+		delay := db.retryDelay(attempt)
+		db.Logger.DebugContext(ctx, "retrying transaction", "attempt", attempt+1, "delay", delay, "error", err)
+		metrics.DBTransactionRetries.Inc()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (db *DB) runTxOnce(ctx context.Context, opts TxOptions, fn func(ctx context.Context, tx *Tx) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx), tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isolationRank orders isolation levels from weakest to strictest. The
+// default level of postgres is READ COMMITTED.
+func isolationRank(level sql.IsolationLevel) sql.IsolationLevel {
+	if level == sql.LevelDefault {
+		return sql.LevelReadCommitted
+	}
+	return level
+}
+
+// retryDelay doubles RetryBackoff on each attempt and adds up to 50% of
+// jitter so that conflicting transactions do not retry in lockstep.
+func (db *DB) retryDelay(attempt int) time.Duration {
+	d := db.RetryBackoff << attempt
+	if d <= 0 {
+		return 0
+	}
+	return d + rand.N(d/2+1)
+}
+
+// isRetryable reports whether err aborted the transaction because of a
+// serialization failure or a deadlock, in which case it is safe to rerun.
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
 }
 
 type Tx struct {
 	*sqlx.Tx
-	db  *DB
-	now time.Time
+	db        *DB
+	now       time.Time
+	start     time.Time
+	isolation sql.IsolationLevel
+	done      bool
+	joined    bool
+}
+
+func (tx *Tx) Commit() error {
+	if tx.joined {
+		return nil
+	}
+
+	err := tx.Tx.Commit()
+	if err != nil {
+		tx.observe("commit_error", err)
+	} else {
+		tx.observe("commit", nil)
+	}
+	return err
+}
+
+func (tx *Tx) Rollback() error {
+	if tx.joined {
+		return nil
+	}
+
+	err := tx.Tx.Rollback()
+	tx.observe("rollback", err)
+	return err
+}
+
+// observe records the transaction duration once, ignoring the deferred
+// Rollback that follows a successful Commit.
+func (tx *Tx) observe(outcome string, err error) {
+	if tx.done || err == sql.ErrTxDone {
+		return
+	}
+	tx.done = true
+	metrics.DBTransactionDuration.WithLabelValues(outcome).Observe(time.Since(tx.start).Seconds())
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, end := tx.db.instrumentQuery(ctx, query)
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	end(err)
+	return result, err
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, end := tx.db.instrumentQuery(ctx, query)
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	end(err)
+	return rows, err
+}
+
+func (tx *Tx) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	ctx, end := tx.db.instrumentQuery(ctx, query)
+	row := tx.Tx.QueryRowxContext(ctx, query, args...)
+	end(row.Err())
+	return row
+}
+
+// instrumentQuery starts a child span named after the function issuing the
+// statement, e.g. "postgres.findWorkouts", and logs the statement at debug
+// level once the returned function is called. The request ID and user ID
+// are attached by the logger's handler from ctx.
+func (db *DB) instrumentQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	start := time.Now()
+	statement := strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+
+	ctx, span := tracing.Tracer().Start(ctx, callerName(3),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(statement),
+		),
+	)
+
+	return ctx, func(err error) {
+		if err != nil && err != sql.ErrNoRows {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		if db.Logger == nil || !db.Logger.Enabled(ctx, slog.LevelDebug) {
+			return
+		}
+		attrs := []slog.Attr{
+			slog.String("query", statement),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		db.Logger.LogAttrs(ctx, slog.LevelDebug, "sql query", attrs...)
+	}
+}
+
+// callerName returns the short name of the function skip frames up the
+// stack, such as "postgres.createWorkout".
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "postgres.query"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
 }
 
 type NullTime time.Time
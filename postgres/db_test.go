@@ -1,14 +1,21 @@
 package postgres_test
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
+	"github.com/maliByatzes/fwt"
 	"github.com/maliByatzes/fwt/postgres"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 const (
@@ -25,6 +32,39 @@ func TestDB(t *testing.T) {
 	MustCloseDB(t, db)
 }
 
+func TestDB_Migrate(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	ctx := context.Background()
+	require.NoError(t, db.CheckSchema(ctx))
+
+	latest, err := postgres.LatestSchemaVersion()
+	require.NoError(t, err)
+
+	require.NoError(t, db.MigrateDown(ctx, 1))
+	status, err := db.MigrationStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, latest-1, status.Version)
+	require.True(t, status.Pending())
+	require.Error(t, db.CheckSchema(ctx))
+
+	require.NoError(t, db.MigrateUp(ctx))
+	require.NoError(t, db.CheckSchema(ctx))
+}
+
+func TestDB_Health(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	require.NoError(t, db.Ping(context.Background()))
+
+	version, dirty, err := db.SchemaVersion(context.Background())
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.NotZero(t, version)
+}
+
 func MustOpenDB(tb testing.TB) *postgres.DB {
 	tb.Helper()
 
@@ -73,11 +113,8 @@ func MustOpenDB(tb testing.TB) *postgres.DB {
 				tb.Fatalf("failed to connect to db: %v", err)
 			}
 
-			wd, _ := os.Getwd()
-			mgCmd := exec.Command("migrate", "-database", fmt.Sprintf(`%s`, dsn), "-path", wd+"/migrations", "up")
-			mgCmdOuput, err := mgCmd.CombinedOutput()
-			if err != nil {
-				tb.Fatalf("failed to run migrations: %v, output: %s", err, string(mgCmdOuput))
+			if err := db.MigrateUp(context.Background()); err != nil {
+				tb.Fatalf("failed to run migrations: %v", err)
 			}
 
 			return db
@@ -111,11 +148,8 @@ func MustOpenDB(tb testing.TB) *postgres.DB {
 		tb.Fatalf("failed to connect to db: %v", err)
 	}
 
-	wd, _ := os.Getwd()
-	mgCmd := exec.Command("migrate", "-database", fmt.Sprintf(`%s`, dsn), "-path", wd+"/migrations", "up")
-	mgCmdOuput, err := mgCmd.CombinedOutput()
-	if err != nil {
-		tb.Fatalf("failed to run up migrations: %v, output: %s", err, string(mgCmdOuput))
+	if err := db.MigrateUp(context.Background()); err != nil {
+		tb.Fatalf("failed to run up migrations: %v", err)
 	}
 
 	return db
@@ -124,12 +158,8 @@ func MustOpenDB(tb testing.TB) *postgres.DB {
 func MustCloseDB(tb testing.TB, db *postgres.DB) {
 	tb.Helper()
 
-	dsn := fmt.Sprintf("postgres://%s:%s@localhost:%s/%s?sslmode=disable", user, password, port, dbName)
-	wd, _ := os.Getwd()
-	mgCmd := exec.Command("migrate", "-database", fmt.Sprintf(`%s`, dsn), "-path", wd+"/migrations", "down", "-all")
-	mgCmdOuput, err := mgCmd.CombinedOutput()
-	if err != nil {
-		tb.Fatalf("failed to run down migrations: %v, output: %s", err, string(mgCmdOuput))
+	if err := db.MigrateDownAll(context.Background()); err != nil {
+		tb.Fatalf("failed to run down migrations: %v", err)
 	}
 
 	if err := db.Close(); err != nil {
@@ -141,3 +171,187 @@ func MustCloseDB(tb testing.TB, db *postgres.DB) {
 		tb.Fatalf("failed to stop db conatiner server: %v", err)
 	}
 }
+
+func TestDB_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(prev)
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	MustCreateUser(t, context.Background(), db, &fwt.User{
+		Username:       postgres.RandomUsername(),
+		Email:          postgres.RandomEmail(),
+		HashedPassword: postgres.RandomHashedPassword(),
+	})
+
+	spans := exporter.GetSpans()
+	require.NotEmpty(t, spans)
+	require.Equal(t, "postgres.createUser", spans[len(spans)-1].Name)
+}
+
+func TestParseIsolationLevels(t *testing.T) {
+	levels, err := postgres.ParseIsolationLevels("WorkoutService.UpdateWorkout=serializable, UserService.DeleteUser=Repeatable Read")
+	require.NoError(t, err)
+	require.Equal(t, map[string]sql.IsolationLevel{
+		"WorkoutService.UpdateWorkout": sql.LevelSerializable,
+		"UserService.DeleteUser":       sql.LevelRepeatableRead,
+	}, levels)
+
+	_, err = postgres.ParseIsolationLevels("WorkoutService.UpdateWorkout")
+	require.Error(t, err)
+
+	_, err = postgres.ParseIsolationLevels("WorkoutService.UpdateWorkout=snapshot")
+	require.Error(t, err)
+}
+
+func TestDB_BeginTx(t *testing.T) {
+	t.Run("ErrNotOpen", func(t *testing.T) {
+		db := postgres.NewDB("postgres://localhost")
+
+		_, err := db.BeginTx(context.Background(), nil)
+		require.Error(t, err)
+	})
+
+	t.Run("ErrCanceled", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := db.BeginTx(ctx, nil)
+		require.ErrorIs(t, err, context.Canceled)
+
+		_, _, err = postgres.NewUserService(db).FindUsers(ctx, fwt.UserFilter{})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("CanceledMidway", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := postgres.NewUserService(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			if err := s.CreateUser(ctx, &fwt.User{
+				Username:       postgres.RandomUsername(),
+				Email:          postgres.RandomEmail(),
+				HashedPassword: postgres.RandomHashedPassword(),
+			}); err != nil {
+				return err
+			}
+			cancel()
+			return nil
+		})
+		require.Error(t, err)
+
+		_, n, err := s.FindUsers(context.Background(), fwt.UserFilter{})
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+}
+
+func TestDB_RunInTxOptions_Retry(t *testing.T) {
+	// conflict runs fn in a REPEATABLE READ transaction that reads a user,
+	// has the user changed by a concurrent statement on the first attempt
+	// only, and then updates it, which aborts with a serialization failure.
+	conflict := func(t *testing.T, retry bool) (attempts int, err error) {
+		db := MustOpenDB(t)
+		t.Cleanup(func() { MustCloseDB(t, db) })
+		db.RetryBackoff = time.Millisecond
+		s := postgres.NewUserService(db)
+
+		user, ctx := MustCreateUser(t, context.Background(), db, &fwt.User{
+			Username:       postgres.RandomUsername(),
+			Email:          postgres.RandomEmail(),
+			HashedPassword: postgres.RandomHashedPassword(),
+		})
+
+		opts := postgres.TxOptions{Isolation: sql.LevelRepeatableRead, Retry: retry}
+		err = db.RunInTxOptions(ctx, opts, func(ctx context.Context) error {
+			attempts++
+			if _, err := s.FindUserByID(ctx, user.ID); err != nil {
+				return err
+			}
+
+			if attempts == 1 {
+				_, err := db.DB.ExecContext(context.Background(), `UPDATE "user" SET email = $1 WHERE id = $2`, postgres.RandomEmail(), user.ID)
+				require.NoError(t, err)
+			}
+
+			email := postgres.RandomEmail()
+			_, err := s.UpdateUser(ctx, user.ID, fwt.UserUpdate{Email: &email})
+			return err
+		})
+		return attempts, err
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		attempts, err := conflict(t, true)
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("ErrNoRetry", func(t *testing.T) {
+		attempts, err := conflict(t, false)
+		var pqErr *pq.Error
+		require.ErrorAs(t, err, &pqErr)
+		require.Equal(t, pq.ErrorCode("40001"), pqErr.Code)
+		require.Equal(t, 1, attempts)
+	})
+}
+
+func TestDB_RunInTx_Isolation(t *testing.T) {
+	// newWorkout creates a workout to delete with WorkoutService.DeleteWorkout,
+	// which is configured to run at REPEATABLE READ.
+	newWorkout := func(t *testing.T) (*postgres.DB, context.Context, *fwt.Workout) {
+		db := MustOpenDB(t)
+		t.Cleanup(func() { MustCloseDB(t, db) })
+		require.Equal(t, sql.LevelRepeatableRead, db.Isolation["WorkoutService.DeleteWorkout"])
+
+		user, ctx := MustCreateUser(t, context.Background(), db, &fwt.User{
+			Username:       postgres.RandomUsername(),
+			Email:          postgres.RandomEmail(),
+			HashedPassword: postgres.RandomHashedPassword(),
+		})
+		exercise := MustCreateExercise(t, ctx, db, &fwt.Exercise{Name: postgres.RandomString(12), Description: postgres.RandomString(50)})
+		workout := MustCreateWorkout(t, ctx, db, &fwt.Workout{
+			UserID:        user.ID,
+			Name:          postgres.RandomString(12),
+			ScheduledDate: time.Now().Add(time.Hour),
+			Exercises:     []*fwt.Exercise{exercise},
+		})
+		return db, ctx, workout
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		for _, level := range []sql.IsolationLevel{sql.LevelRepeatableRead, sql.LevelSerializable} {
+			db, ctx, workout := newWorkout(t)
+			s := postgres.NewWorkoutService(db)
+
+			err := db.RunInTxOptions(ctx, postgres.TxOptions{Isolation: level}, func(ctx context.Context) error {
+				return s.DeleteWorkout(ctx, workout.ID, nil)
+			})
+			require.NoError(t, err, level)
+
+			_, err = s.FindWorkoutByID(ctx, workout.ID)
+			require.Equal(t, fwt.ENOTFOUND, fwt.ErrorCode(err), level)
+		}
+	})
+
+	t.Run("ErrWeakerOuterTx", func(t *testing.T) {
+		db, ctx, workout := newWorkout(t)
+		s := postgres.NewWorkoutService(db)
+
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			return s.DeleteWorkout(ctx, workout.ID, nil)
+		})
+		require.ErrorContains(t, err, "WorkoutService.DeleteWorkout requires Repeatable Read isolation")
+
+		_, err = s.FindWorkoutByID(ctx, workout.ID)
+		require.NoError(t, err)
+	})
+}
@@ -17,46 +17,34 @@ func NewExerciseService(db *DB) *ExerciseService {
 	return &ExerciseService{db: db}
 }
 
-func (s *ExerciseService) FindExerciseByID(ctx context.Context, id uint) (*fwt.Exercise, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	exercise, err := findExerciseByID(ctx, tx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return exercise, nil
+func (s *ExerciseService) FindExerciseByID(ctx context.Context, id uint) (exercise *fwt.Exercise, err error) {
+	err = s.db.run(ctx, "ExerciseService.FindExerciseByID", readTx, func(tx *Tx) error {
+		exercise, err = findExerciseByID(ctx, tx, id)
+		return err
+	})
+	return exercise, err
 }
 
-func (s *ExerciseService) FindExerciseByName(ctx context.Context, name string) (*fwt.Exercise, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	exercise, err := findExerciseByName(ctx, tx, name)
-	if err != nil {
-		return nil, err
-	}
-
-	return exercise, nil
+func (s *ExerciseService) FindExerciseByName(ctx context.Context, name string) (exercise *fwt.Exercise, err error) {
+	err = s.db.run(ctx, "ExerciseService.FindExerciseByName", readTx, func(tx *Tx) error {
+		exercise, err = findExerciseByName(ctx, tx, name)
+		return err
+	})
+	return exercise, err
 }
 
-func (s *ExerciseService) FindExercises(ctx context.Context, filter fwt.ExerciseFilter) ([]*fwt.Exercise, int, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	return findExercises(ctx, tx, filter)
+func (s *ExerciseService) FindExercises(ctx context.Context, filter fwt.ExerciseFilter) (a []*fwt.Exercise, n int, err error) {
+	err = s.db.run(ctx, "ExerciseService.FindExercises", readTx, func(tx *Tx) error {
+		a, n, err = findExercises(ctx, tx, filter)
+		return err
+	})
+	return a, n, err
 }
 
 func (s *ExerciseService) CreateExercise(ctx context.Context, exercise *fwt.Exercise) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := createExercise(ctx, tx, exercise); err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return s.db.run(ctx, "ExerciseService.CreateExercise", insertTx, func(tx *Tx) error {
+		return createExercise(ctx, tx, exercise)
+	})
 }
 
 func createExercise(ctx context.Context, tx *Tx, exercise *fwt.Exercise) error {
@@ -122,7 +110,7 @@ func findExercises(ctx context.Context, tx *Tx, filter fwt.ExerciseFilter) (_ []
 
 	query := `
 	SELECT id, name, description, created_at, updated_at, COUNT(*) OVER()
-	FROM exercise` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Limit)
+	FROM exercise` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
 
 	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -74,6 +74,14 @@ func TestExerciseService_FindExercises(t *testing.T) {
 	require.Equal(t, len(a), 1)
 	require.Equal(t, a[0].ID, id)
 	require.Equal(t, n, 1)
+
+	// Regression: the limit was passed as the offset, so a page started
+	// at the wrong exercise whenever the two differed.
+	a, _, err = s.FindExercises(ctx, fwt.ExerciseFilter{Limit: 2, Offset: 5})
+	require.NoError(t, err)
+	require.Equal(t, len(a), 2)
+	require.Equal(t, a[0].ID, uint(6))
+	require.Equal(t, a[1].ID, uint(7))
 }
 
 func MustCreateExercise(tb testing.TB, ctx context.Context, db *postgres.DB, exercise *fwt.Exercise) *fwt.Exercise {
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.IdempotencyService = (*IdempotencyService)(nil)
+
+type IdempotencyService struct {
+	db *DB
+}
+
+func NewIdempotencyService(db *DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+func (s *IdempotencyService) FindIdempotencyKey(ctx context.Context, userID uint, key string) (v *fwt.IdempotencyKey, err error) {
+	err = s.db.run(ctx, "IdempotencyService.FindIdempotencyKey", readTx, func(tx *Tx) error {
+		v, err = findIdempotencyKey(ctx, tx, userID, key)
+		return err
+	})
+	return v, err
+}
+
+func (s *IdempotencyService) CreateIdempotencyKey(ctx context.Context, key *fwt.IdempotencyKey) error {
+	return s.db.run(ctx, "IdempotencyService.CreateIdempotencyKey", insertTx, func(tx *Tx) error {
+		return createIdempotencyKey(ctx, tx, key)
+	})
+}
+
+func (s *IdempotencyService) UpdateIdempotencyKey(ctx context.Context, id uint, upd fwt.IdempotencyKeyUpdate) (key *fwt.IdempotencyKey, err error) {
+	err = s.db.run(ctx, "IdempotencyService.UpdateIdempotencyKey", updateTx, func(tx *Tx) error {
+		key, err = updateIdempotencyKey(ctx, tx, id, upd)
+		return err
+	})
+	return key, err
+}
+
+func (s *IdempotencyService) DeleteIdempotencyKey(ctx context.Context, id uint) error {
+	return s.db.run(ctx, "IdempotencyService.DeleteIdempotencyKey", updateTx, func(tx *Tx) error {
+		return deleteIdempotencyKey(ctx, tx, id)
+	})
+}
+
+func createIdempotencyKey(ctx context.Context, tx *Tx, key *fwt.IdempotencyKey) error {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to create an idempotency key.")
+	}
+	key.UserID = userID
+	key.CreatedAt = tx.now
+
+	if err := key.Validate(); err != nil {
+		return err
+	}
+
+	// An expired key may be reused, so clear it out before inserting.
+	query := `
+	DELETE FROM idempotency_key WHERE user_id = $1 AND key = $2 AND expires_at <= $3
+	`
+	if _, err := tx.ExecContext(ctx, query, key.UserID, key.Key, (*NullTime)(&tx.now)); err != nil {
+		return err
+	}
+
+	query = `
+	INSERT INTO idempotency_key (user_id, key, request_hash, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id
+	`
+	args := []interface{}{
+		key.UserID,
+		key.Key,
+		key.RequestHash,
+		(*NullTime)(&key.CreatedAt),
+		(*NullTime)(&key.ExpiresAt),
+	}
+
+	err := tx.QueryRowxContext(ctx, query, args...).Scan(&key.ID)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "idempotency_key_user_id_key_key"`:
+			return fwt.Errorf(fwt.ECONFLICT, "This idempotency key is already in use.")
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findIdempotencyKey(ctx context.Context, tx *Tx, userID uint, key string) (*fwt.IdempotencyKey, error) {
+	query := `
+	SELECT id, user_id, key, request_hash, COALESCE(response_status, 0), COALESCE(response_body, ''), COALESCE(response_headers::text, ''), created_at, expires_at
+	FROM idempotency_key WHERE user_id = $1 AND key = $2 AND expires_at > $3
+	`
+	return scanIdempotencyKey(tx.QueryRowxContext(ctx, query, userID, key, (*NullTime)(&tx.now)))
+}
+
+func findIdempotencyKeyByID(ctx context.Context, tx *Tx, id uint) (*fwt.IdempotencyKey, error) {
+	query := `
+	SELECT id, user_id, key, request_hash, COALESCE(response_status, 0), COALESCE(response_body, ''), COALESCE(response_headers::text, ''), created_at, expires_at
+	FROM idempotency_key WHERE id = $1
+	`
+	return scanIdempotencyKey(tx.QueryRowxContext(ctx, query, id))
+}
+
+func scanIdempotencyKey(row *sqlx.Row) (*fwt.IdempotencyKey, error) {
+	var key fwt.IdempotencyKey
+	var headers string
+	if err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Key,
+		&key.RequestHash,
+		&key.ResponseStatus,
+		&key.ResponseBody,
+		&headers,
+		(*NullTime)(&key.CreatedAt),
+		(*NullTime)(&key.ExpiresAt),
+	); err == sql.ErrNoRows {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "Idempotency key not found.")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if headers != "" {
+		if err := json.Unmarshal([]byte(headers), &key.ResponseHeaders); err != nil {
+			return nil, err
+		}
+	}
+
+	return &key, nil
+}
+
+func updateIdempotencyKey(ctx context.Context, tx *Tx, id uint, upd fwt.IdempotencyKeyUpdate) (*fwt.IdempotencyKey, error) {
+	key, err := findIdempotencyKeyByID(ctx, tx, id)
+	if err != nil {
+		return key, err
+	} else if key.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this idempotency key.")
+	}
+
+	if v := upd.ResponseStatus; v != nil {
+		key.ResponseStatus = *v
+	}
+	if v := upd.ResponseBody; v != nil {
+		key.ResponseBody = v
+	}
+	if v := upd.ResponseHeaders; v != nil {
+		key.ResponseHeaders = v
+	}
+	if v := upd.ExpiresAt; v != nil {
+		key.ExpiresAt = *v
+	}
+
+	headers, err := json.Marshal(key.ResponseHeaders)
+	if err != nil {
+		return key, err
+	}
+
+	query := `
+	UPDATE idempotency_key SET response_status = $1, response_body = $2, response_headers = $3, expires_at = $4
+	WHERE id = $5 AND user_id = $6
+	`
+	args := []interface{}{
+		key.ResponseStatus,
+		key.ResponseBody,
+		string(headers),
+		(*NullTime)(&key.ExpiresAt),
+		key.ID,
+		key.UserID,
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+func deleteIdempotencyKey(ctx context.Context, tx *Tx, id uint) error {
+	key, err := findIdempotencyKeyByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if key.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this idempotency key.")
+	}
+
+	query := `
+	DELETE FROM idempotency_key WHERE id = $1 AND user_id = $2
+	`
+	if _, err := tx.ExecContext(ctx, query, key.ID, key.UserID); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package postgres_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/maliByatzes/fwt/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	entries, err := fs.ReadDir(postgres.Migrations(), ".")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	names := make(map[string]bool)
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	for name := range names {
+		if base, ok := strings.CutSuffix(name, ".up.sql"); ok {
+			require.True(t, names[base+".down.sql"], "missing down migration for %s", name)
+		}
+	}
+
+	latest, err := postgres.LatestSchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint(len(names)/2), latest)
+}
@@ -0,0 +1,5 @@
+ALTER TABLE "idempotency_key" DROP CONSTRAINT IF EXISTS "idempotency_key_user_id_fkey";
+
+DROP INDEX IF EXISTS "idempotency_key_user_id_key_key";
+
+DROP TABLE IF EXISTS "idempotency_key";
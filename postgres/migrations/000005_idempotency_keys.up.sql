@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "idempotency_key" (
+    "id" SERIAL NOT NULL,
+    "user_id" INTEGER NOT NULL,
+    "key" VARCHAR(255) NOT NULL,
+    "request_hash" VARCHAR(64) NOT NULL,
+    "response_status" INTEGER,
+    "response_body" BYTEA,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    CONSTRAINT "idempotency_key_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idempotency_key_user_id_key_key" ON "idempotency_key"("user_id", "key");
+
+ALTER TABLE "idempotency_key" ADD CONSTRAINT "idempotency_key_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE;
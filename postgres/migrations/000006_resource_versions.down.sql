@@ -0,0 +1,3 @@
+ALTER TABLE "profile" DROP COLUMN IF EXISTS "version";
+
+ALTER TABLE "workout" DROP COLUMN IF EXISTS "version";
@@ -0,0 +1,3 @@
+ALTER TABLE "workout" ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE "profile" ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
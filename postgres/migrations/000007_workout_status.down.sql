@@ -0,0 +1,11 @@
+ALTER TYPE "status_enum" RENAME TO "status_enum_old";
+CREATE TYPE "status_enum" AS ENUM('pending', 'completed');
+ALTER TABLE "workout_exercise_status" ALTER COLUMN "status" TYPE status_enum
+    USING (CASE WHEN "status" = 'completed' THEN 'completed' ELSE 'pending' END)::status_enum;
+DROP TYPE "status_enum_old";
+
+ALTER TABLE "workout" DROP COLUMN IF EXISTS "finished_at";
+ALTER TABLE "workout" DROP COLUMN IF EXISTS "started_at";
+ALTER TABLE "workout" DROP COLUMN IF EXISTS "status";
+
+DROP TYPE IF EXISTS "workout_status_enum";
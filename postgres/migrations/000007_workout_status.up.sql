@@ -0,0 +1,8 @@
+CREATE TYPE "workout_status_enum" AS ENUM('planned', 'in_progress', 'completed', 'partially_completed', 'skipped');
+
+ALTER TABLE "workout" ADD COLUMN "status" workout_status_enum NOT NULL DEFAULT 'planned';
+ALTER TABLE "workout" ADD COLUMN "started_at" TIMESTAMPTZ;
+ALTER TABLE "workout" ADD COLUMN "finished_at" TIMESTAMPTZ;
+
+ALTER TYPE "status_enum" ADD VALUE IF NOT EXISTS 'partially_completed';
+ALTER TYPE "status_enum" ADD VALUE IF NOT EXISTS 'skipped';
@@ -0,0 +1,4 @@
+ALTER TABLE "workout_exercise_status" DROP CONSTRAINT IF EXISTS "workout_exercise_status_workout_exercise_id_fkey";
+ALTER TABLE "workout_exercise_status" ADD CONSTRAINT "workout_exercise_status_workout_exercise_id_fkey" FOREIGN KEY ("workout_exercise_id") REFERENCES "workout_exercise"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+DROP INDEX IF EXISTS "workout_exercise_status_workout_exercise_id_key";
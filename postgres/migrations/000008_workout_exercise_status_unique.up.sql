@@ -0,0 +1,13 @@
+DELETE FROM "workout_exercise_status" AS a
+USING "workout_exercise_status" AS b
+WHERE a."workout_exercise_id" = b."workout_exercise_id" AND a."id" > b."id";
+
+INSERT INTO "workout_exercise_status" ("workout_exercise_id", "status", "comments", "created_at", "updated_at")
+SELECT we."id", 'pending', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM "workout_exercise" AS we
+WHERE NOT EXISTS (SELECT 1 FROM "workout_exercise_status" AS s WHERE s."workout_exercise_id" = we."id");
+
+CREATE UNIQUE INDEX "workout_exercise_status_workout_exercise_id_key" ON "workout_exercise_status"("workout_exercise_id");
+
+ALTER TABLE "workout_exercise_status" DROP CONSTRAINT IF EXISTS "workout_exercise_status_workout_exercise_id_fkey";
+ALTER TABLE "workout_exercise_status" ADD CONSTRAINT "workout_exercise_status_workout_exercise_id_fkey" FOREIGN KEY ("workout_exercise_id") REFERENCES "workout_exercise"("id") ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,5 @@
+ALTER TABLE "exercise_set" DROP CONSTRAINT IF EXISTS "exercise_set_workout_exercise_id_fkey";
+
+DROP INDEX IF EXISTS "exercise_set_workout_exercise_id_idx";
+
+DROP TABLE IF EXISTS "exercise_set";
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "exercise_set" (
+    "id" SERIAL NOT NULL,
+    "workout_exercise_id" INTEGER NOT NULL,
+    "reps" INTEGER NOT NULL CHECK ("reps" > 0),
+    "load" DECIMAL NOT NULL DEFAULT 0 CHECK ("load" >= 0),
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "exercise_set_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "exercise_set_workout_exercise_id_idx" ON "exercise_set"("workout_exercise_id");
+
+ALTER TABLE "exercise_set" ADD CONSTRAINT "exercise_set_workout_exercise_id_fkey" FOREIGN KEY ("workout_exercise_id") REFERENCES "workout_exercise"("id") ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,5 @@
+ALTER TABLE "body_measurement" DROP CONSTRAINT IF EXISTS "body_measurement_user_id_fkey";
+
+DROP INDEX IF EXISTS "body_measurement_user_id_measured_at_idx";
+
+DROP TABLE IF EXISTS "body_measurement";
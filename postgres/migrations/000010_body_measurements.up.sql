@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS "body_measurement" (
+    "id" SERIAL NOT NULL,
+    "user_id" INTEGER NOT NULL,
+    "measured_at" TIMESTAMPTZ NOT NULL,
+    "weight" DECIMAL NOT NULL DEFAULT 0,
+    "body_fat" DECIMAL NOT NULL DEFAULT 0,
+    "waist" DECIMAL NOT NULL DEFAULT 0,
+    "chest" DECIMAL NOT NULL DEFAULT 0,
+    "arms" DECIMAL NOT NULL DEFAULT 0,
+    "thighs" DECIMAL NOT NULL DEFAULT 0,
+    "resting_heart_rate" INTEGER NOT NULL DEFAULT 0,
+    "notes" TEXT NOT NULL DEFAULT '',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ NOT NULL,
+    CONSTRAINT "body_measurement_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "body_measurement_user_id_measured_at_idx" ON "body_measurement"("user_id", "measured_at");
+
+ALTER TABLE "body_measurement" ADD CONSTRAINT "body_measurement_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+INSERT INTO "body_measurement" ("user_id", "measured_at", "weight", "created_at", "updated_at")
+SELECT "user_id", "updated_at", "weight", "updated_at", "updated_at"
+FROM "profile"
+WHERE "weight" > 0;
@@ -0,0 +1,3 @@
+ALTER TABLE "profile" DROP COLUMN IF EXISTS "distance_unit";
+ALTER TABLE "profile" DROP COLUMN IF EXISTS "length_unit";
+ALTER TABLE "profile" DROP COLUMN IF EXISTS "weight_unit";
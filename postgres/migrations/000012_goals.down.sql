@@ -0,0 +1,7 @@
+ALTER TABLE "goal" DROP CONSTRAINT IF EXISTS "goal_exercise_id_fkey";
+
+ALTER TABLE "goal" DROP CONSTRAINT IF EXISTS "goal_user_id_fkey";
+
+DROP INDEX IF EXISTS "goal_user_id_idx";
+
+DROP TABLE IF EXISTS "goal";
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "goal" (
+    "id" SERIAL NOT NULL,
+    "user_id" INTEGER NOT NULL,
+    "type" TEXT NOT NULL CHECK ("type" IN ('body_weight', 'lift', 'frequency', 'volume')),
+    "exercise_id" INTEGER,
+    "target" DECIMAL NOT NULL CHECK ("target" > 0),
+    "start" DECIMAL NOT NULL DEFAULT 0,
+    "target_date" TIMESTAMPTZ,
+    "archived_at" TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ NOT NULL,
+    CONSTRAINT "goal_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "goal_user_id_idx" ON "goal"("user_id");
+
+ALTER TABLE "goal" ADD CONSTRAINT "goal_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "goal" ADD CONSTRAINT "goal_exercise_id_fkey" FOREIGN KEY ("exercise_id") REFERENCES "exercise"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
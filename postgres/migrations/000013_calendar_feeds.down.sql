@@ -0,0 +1,6 @@
+ALTER TABLE "calendar_feed" DROP CONSTRAINT IF EXISTS "calendar_feed_user_id_fkey";
+
+DROP INDEX IF EXISTS "calendar_feed_token_hash_key";
+DROP INDEX IF EXISTS "calendar_feed_user_id_key";
+
+DROP TABLE IF EXISTS "calendar_feed";
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "calendar_feed" (
+    "id" SERIAL NOT NULL,
+    "user_id" INTEGER NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "calendar_feed_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "calendar_feed_user_id_key" ON "calendar_feed"("user_id");
+CREATE UNIQUE INDEX "calendar_feed_token_hash_key" ON "calendar_feed"("token_hash");
+
+ALTER TABLE "calendar_feed" ADD CONSTRAINT "calendar_feed_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE "profile" DROP COLUMN IF EXISTS "timezone";
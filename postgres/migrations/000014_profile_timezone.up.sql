@@ -0,0 +1 @@
+ALTER TABLE "profile" ADD COLUMN "timezone" TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "user_deleted_at_idx";
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "deleted_at";
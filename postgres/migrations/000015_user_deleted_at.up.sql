@@ -0,0 +1,3 @@
+ALTER TABLE "user" ADD COLUMN "deleted_at" TIMESTAMPTZ;
+
+CREATE INDEX "user_deleted_at_idx" ON "user"("deleted_at") WHERE "deleted_at" IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE "idempotency_key" ADD COLUMN "response_headers" JSONB;
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/maliByatzes/fwt"
 )
@@ -17,71 +18,48 @@ func NewProfileService(db *DB) *ProfileService {
 	return &ProfileService{db: db}
 }
 
-func (s *ProfileService) FindProfileByID(ctx context.Context, id uint) (*fwt.Profile, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	profile, err := findProfileByID(ctx, tx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return profile, nil
-}
-
-func (s *ProfileService) FindProfileByUserID(ctx context.Context, userID uint) (*fwt.Profile, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	profile, err := findProfileByUserID(ctx, tx, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	return profile, nil
+func (s *ProfileService) FindProfileByID(ctx context.Context, id uint) (profile *fwt.Profile, err error) {
+	err = s.db.run(ctx, "ProfileService.FindProfileByID", readTx, func(tx *Tx) error {
+		profile, err = findProfileByID(ctx, tx, id)
+		return err
+	})
+	return profile, err
 }
 
-func (s *ProfileService) FindProfiles(ctx context.Context, filter fwt.ProfileFilter) ([]*fwt.Profile, int, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	return findProfiles(ctx, tx, filter)
+func (s *ProfileService) FindProfileByUserID(ctx context.Context, userID uint) (profile *fwt.Profile, err error) {
+	err = s.db.run(ctx, "ProfileService.FindProfileByUserID", readTx, func(tx *Tx) error {
+		profile, err = findProfileByUserID(ctx, tx, userID)
+		return err
+	})
+	return profile, err
 }
 
-func (s *ProfileService) CreateProfile(ctx context.Context, profile *fwt.Profile) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := createProfile(ctx, tx, profile); err != nil {
+func (s *ProfileService) FindProfiles(ctx context.Context, filter fwt.ProfileFilter) (a []*fwt.Profile, n int, err error) {
+	err = s.db.run(ctx, "ProfileService.FindProfiles", readTx, func(tx *Tx) error {
+		a, n, err = findProfiles(ctx, tx, filter)
 		return err
-	}
-
-	return tx.Commit()
+	})
+	return a, n, err
 }
 
-func (s *ProfileService) UpdateProfile(ctx context.Context, id uint, upd fwt.ProfileUpdate) (*fwt.Profile, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	profile, err := updateProfile(ctx, tx, id, upd)
-	if err != nil {
-		return profile, err
-	} else if err := tx.Commit(); err != nil {
-		return profile, err
-	}
-
-	return profile, nil
+func (s *ProfileService) CreateProfile(ctx context.Context, profile *fwt.Profile) error {
+	return s.db.run(ctx, "ProfileService.CreateProfile", insertTx, func(tx *Tx) error {
+		return createProfile(ctx, tx, profile)
+	})
 }
 
-func (s *ProfileService) DeleteProfile(ctx context.Context, id uint) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := deleteProfile(ctx, tx, id); err != nil {
+func (s *ProfileService) UpdateProfile(ctx context.Context, id uint, upd fwt.ProfileUpdate) (profile *fwt.Profile, err error) {
+	err = s.db.run(ctx, "ProfileService.UpdateProfile", updateTx, func(tx *Tx) error {
+		profile, err = updateProfile(ctx, tx, id, upd)
 		return err
-	}
+	})
+	return profile, err
+}
 
-	return tx.Commit()
+func (s *ProfileService) DeleteProfile(ctx context.Context, id uint, version *uint) error {
+	return s.db.run(ctx, "ProfileService.DeleteProfile", updateTx, func(tx *Tx) error {
+		return deleteProfile(ctx, tx, id, version)
+	})
 }
 
 func createProfile(ctx context.Context, tx *Tx, profile *fwt.Profile) error {
@@ -91,6 +69,11 @@ func createProfile(ctx context.Context, tx *Tx, profile *fwt.Profile) error {
 	}
 	profile.UserID = fwt.UserIDFromContext(ctx)
 
+	profile.Units = fwt.MetricUnits.Merge(profile.Units)
+	if profile.Timezone == "" {
+		profile.Timezone = fwt.DefaultTimezone
+	}
+	profile.Version = 1
 	profile.CreatedAt = tx.now
 	profile.UpdatedAt = profile.CreatedAt
 
@@ -106,9 +89,20 @@ func createProfile(ctx context.Context, tx *Tx, profile *fwt.Profile) error {
 		return &fwt.Error{Code: fwt.ECONFLICT, Message: "Profile already exists."}
 	}
 
+	// A profile created without a weight starts from the latest measured
+	// one; a weight given here is recorded as a new measurement instead.
+	measured := profile.Weight == 0
+	if measured {
+		weight, err := findLatestWeight(ctx, tx, profile.UserID)
+		if err != nil {
+			return err
+		}
+		profile.Weight = weight
+	}
+
 	query := `
-	INSERT INTO profile (user_id, first_name, last_name, date_of_birth, gender, height, weight, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id
+	INSERT INTO profile (user_id, first_name, last_name, date_of_birth, gender, height, weight, weight_unit, length_unit, distance_unit, timezone, version, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id
 	`
 	args := []interface{}{
 		profile.UserID,
@@ -118,6 +112,11 @@ func createProfile(ctx context.Context, tx *Tx, profile *fwt.Profile) error {
 		profile.Gender,
 		profile.Height,
 		profile.Weight,
+		profile.Units.Weight,
+		profile.Units.Length,
+		profile.Units.Distance,
+		profile.Timezone,
+		profile.Version,
 		(*NullTime)(&profile.CreatedAt),
 		(*NullTime)(&profile.UpdatedAt),
 	}
@@ -127,7 +126,10 @@ func createProfile(ctx context.Context, tx *Tx, profile *fwt.Profile) error {
 		return err
 	}
 
-	return nil
+	if measured {
+		return nil
+	}
+	return recordProfileWeight(ctx, tx, profile.Weight)
 }
 
 func findProfileByID(ctx context.Context, tx *Tx, id uint) (*fwt.Profile, error) {
@@ -150,6 +152,18 @@ func findProfileByUserID(ctx context.Context, tx *Tx, userID uint) (*fwt.Profile
 	return a[0], nil
 }
 
+// findUserLocation returns the location of the user's timezone, which is
+// UTC for users without a profile.
+func findUserLocation(ctx context.Context, tx *Tx, userID uint) (*time.Location, error) {
+	profile, err := findProfileByUserID(ctx, tx, userID)
+	if fwt.ErrorCode(err) == fwt.ENOTFOUND {
+		return time.UTC, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return profile.Location(), nil
+}
+
 func findProfiles(ctx context.Context, tx *Tx, filter fwt.ProfileFilter) (_ []*fwt.Profile, n int, err error) {
 	where, args := []string{}, []interface{}{}
 	argPos := 0
@@ -188,7 +202,7 @@ func findProfiles(ctx context.Context, tx *Tx, filter fwt.ProfileFilter) (_ []*f
 	}
 
 	query := `
-	SELECT id, user_id, first_name, last_name, date_of_birth, gender, height, weight, created_at, updated_at, COUNT(*) OVER()
+	SELECT id, user_id, first_name, last_name, date_of_birth, gender, height, weight, weight_unit, length_unit, distance_unit, timezone, version, created_at, updated_at, COUNT(*) OVER()
 	FROM profile` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
 
 	rows, err := tx.QueryContext(ctx, query, args...)
@@ -209,6 +223,11 @@ func findProfiles(ctx context.Context, tx *Tx, filter fwt.ProfileFilter) (_ []*f
 			&profile.Gender,
 			&profile.Height,
 			&profile.Weight,
+			&profile.Units.Weight,
+			&profile.Units.Length,
+			&profile.Units.Distance,
+			&profile.Timezone,
+			&profile.Version,
 			(*NullTime)(&profile.CreatedAt),
 			(*NullTime)(&profile.UpdatedAt),
 			&n,
@@ -231,6 +250,8 @@ func updateProfile(ctx context.Context, tx *Tx, id uint, upd fwt.ProfileUpdate)
 		return profile, err
 	} else if profile.UserID != fwt.UserIDFromContext(ctx) {
 		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this profile.")
+	} else if v := upd.Version; v != nil && *v != profile.Version {
+		return profile, fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
 	}
 
 	if v := upd.FirstName; v != nil {
@@ -253,10 +274,21 @@ func updateProfile(ctx context.Context, tx *Tx, id uint, upd fwt.ProfileUpdate)
 		profile.Height = *v
 	}
 
-	if v := upd.Weight; v != nil {
+	if v := upd.Weight; v != nil && *v != profile.Weight {
+		if err := recordProfileWeight(ctx, tx, *v); err != nil {
+			return profile, err
+		}
 		profile.Weight = *v
 	}
 
+	if v := upd.Units; v != nil {
+		profile.Units = profile.Units.Merge(*v)
+	}
+
+	if v := upd.Timezone; v != nil {
+		profile.Timezone = *v
+	}
+
 	profile.UpdatedAt = tx.now
 
 	if err := profile.Validate(); err != nil {
@@ -270,24 +302,34 @@ func updateProfile(ctx context.Context, tx *Tx, id uint, upd fwt.ProfileUpdate)
 		profile.Gender,
 		profile.Height,
 		profile.Weight,
+		profile.Units.Weight,
+		profile.Units.Length,
+		profile.Units.Distance,
+		profile.Timezone,
 		profile.UpdatedAt,
 		profile.ID,
 		profile.UserID,
+		profile.Version,
 	}
 	query := `
-	UPDATE profile SET first_name = $1, last_name = $2, date_of_birth = $3, gender = $4, height = $5, weight = $6, updated_at = $7
-	WHERE id = $8 AND user_id = $9
+	UPDATE profile SET first_name = $1, last_name = $2, date_of_birth = $3, gender = $4, height = $5, weight = $6, weight_unit = $7, length_unit = $8, distance_unit = $9, timezone = $10, updated_at = $11, version = version + 1
+	WHERE id = $12 AND user_id = $13 AND version = $14
 	`
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return profile, err
+	} else if n, err := result.RowsAffected(); err != nil {
+		return profile, err
+	} else if n == 0 {
+		return profile, fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
 	}
+	profile.Version++
 
 	return profile, nil
 }
 
-func deleteProfile(ctx context.Context, tx *Tx, id uint) error {
+func deleteProfile(ctx context.Context, tx *Tx, id uint, version *uint) error {
 	profile, err := findProfileByID(ctx, tx, id)
 	if err != nil {
 		return err
@@ -295,16 +337,35 @@ func deleteProfile(ctx context.Context, tx *Tx, id uint) error {
 		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this profile.")
 	}
 
+	if version == nil {
+		version = &profile.Version
+	}
+
 	args := []interface{}{
 		profile.ID,
 		profile.UserID,
+		*version,
 	}
 	query := `
-	DELETE FROM profile WHERE id = $1 AND user_id = $2
+	DELETE FROM profile WHERE id = $1 AND user_id = $2 AND version = $3
 	`
-	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	} else if n, err := result.RowsAffected(); err != nil {
 		return err
+	} else if n == 0 {
+		return fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
 	}
 
 	return nil
 }
+
+// recordProfileWeight adds a weight set through the profile to the body
+// measurement history, so that it is not lost when it changes again.
+func recordProfileWeight(ctx context.Context, tx *Tx, weight float64) error {
+	if weight <= 0 {
+		return nil
+	}
+	return createBodyMeasurement(ctx, tx, &fwt.BodyMeasurement{Weight: weight})
+}
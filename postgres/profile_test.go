@@ -97,6 +97,31 @@ func TestProfileService_UpdateProfile(t *testing.T) {
 		other, err := s.FindProfileByID(ctx, 1)
 		require.NoError(t, err)
 		require.Equal(t, up, other)
+		require.Equal(t, up.Version, profile0.Version+1)
+	})
+
+	t.Run("ErrStale", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := postgres.NewProfileService(db)
+		user, ctx := MustCreateUser(t, context.Background(), db, &fwt.User{
+			Username:       "jeff",
+			Email:          "jeff@email.com",
+			HashedPassword: "password",
+		})
+		profile0 := MustCreateProfile(t, ctx, db, &fwt.Profile{
+			UserID:    user.ID,
+			FirstName: "jeffina",
+		})
+
+		version := profile0.Version
+		newFirstName := "kyle"
+		_, err := s.UpdateProfile(ctx, profile0.ID, fwt.ProfileUpdate{FirstName: &newFirstName, Version: &version})
+		require.NoError(t, err)
+
+		_, err = s.UpdateProfile(ctx, profile0.ID, fwt.ProfileUpdate{FirstName: &newFirstName, Version: &version})
+		require.Error(t, err)
+		require.Equal(t, fwt.ErrorCode(err), fwt.ESTALE)
 	})
 }
 
@@ -115,7 +140,12 @@ func TestProfileService_DeleteProfile(t *testing.T) {
 		LastName:  "reboot",
 	})
 
-	err := s.DeleteProfile(ctx0, profile0.ID)
+	stale := profile0.Version + 1
+	err := s.DeleteProfile(ctx0, profile0.ID, &stale)
+	require.Error(t, err)
+	require.Equal(t, fwt.ErrorCode(err), fwt.ESTALE)
+
+	err = s.DeleteProfile(ctx0, profile0.ID, &profile0.Version)
 	require.NoError(t, err)
 
 	_, err = s.FindProfileByID(ctx0, profile0.ID)
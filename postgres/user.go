@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/maliByatzes/fwt"
 )
@@ -18,23 +19,20 @@ func NewUserService(db *DB) *UserService {
 	return &UserService{db: db}
 }
 
-func (s *UserService) FindUserByID(ctx context.Context, id uint) (*fwt.User, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	user, err := findUserByID(ctx, tx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return user, nil
+func (s *UserService) FindUserByID(ctx context.Context, id uint) (user *fwt.User, err error) {
+	err = s.db.run(ctx, "UserService.FindUserByID", readTx, func(tx *Tx) error {
+		user, err = findUserByID(ctx, tx, id)
+		return err
+	})
+	return user, err
 }
 
 func (s *UserService) Authenticate(ctx context.Context, username, password string) (*fwt.User, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	user, err := findUserByUsername(ctx, tx, username)
+	var user *fwt.User
+	err := s.db.run(ctx, "UserService.Authenticate", readTx, func(tx *Tx) (err error) {
+		user, err = findUserByUsername(ctx, tx, username)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -46,47 +44,54 @@ func (s *UserService) Authenticate(ctx context.Context, username, password strin
 	return user, nil
 }
 
-func (s *UserService) FindUsers(ctx context.Context, filter fwt.UserFilter) ([]*fwt.User, int, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	return findUsers(ctx, tx, filter)
+func (s *UserService) FindUsers(ctx context.Context, filter fwt.UserFilter) (a []*fwt.User, n int, err error) {
+	err = s.db.run(ctx, "UserService.FindUsers", readTx, func(tx *Tx) error {
+		a, n, err = findUsers(ctx, tx, filter)
+		return err
+	})
+	return a, n, err
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user *fwt.User) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+	return s.db.run(ctx, "UserService.CreateUser", insertTx, func(tx *Tx) error {
+		return createUser(ctx, tx, user)
+	})
+}
 
-	if err := createUser(ctx, tx, user); err != nil {
+func (s *UserService) UpdateUser(ctx context.Context, id uint, upd fwt.UserUpdate) (user *fwt.User, err error) {
+	err = s.db.run(ctx, "UserService.UpdateUser", updateTx, func(tx *Tx) error {
+		user, err = updateUser(ctx, tx, id, upd)
 		return err
-	}
-
-	return tx.Commit()
+	})
+	return user, err
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	user, err := updateUser(ctx, tx, id, upd)
-	if err != nil {
-		return user, err
-	} else if err := tx.Commit(); err != nil {
-		return user, err
-	}
-
-	return user, nil
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	return s.db.run(ctx, "UserService.DeleteUser", updateTx, func(tx *Tx) error {
+		return deleteUser(ctx, tx, id)
+	})
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+func (s *UserService) ScheduleUserDeletion(ctx context.Context, id uint) (user *fwt.User, err error) {
+	err = s.db.run(ctx, "UserService.ScheduleUserDeletion", updateTx, func(tx *Tx) error {
+		user, err = setUserDeletedAt(ctx, tx, id, tx.now)
+		return err
+	})
+	return user, err
+}
 
-	if err := deleteUser(ctx, tx, id); err != nil {
+func (s *UserService) RestoreUser(ctx context.Context, id uint) (user *fwt.User, err error) {
+	err = s.db.run(ctx, "UserService.RestoreUser", updateTx, func(tx *Tx) error {
+		user, err = setUserDeletedAt(ctx, tx, id, time.Time{})
 		return err
-	}
+	})
+	return user, err
+}
 
-	return tx.Commit()
+func (s *UserService) PurgeDeletedUser(ctx context.Context, id uint, before time.Time) error {
+	return s.db.run(ctx, "UserService.PurgeDeletedUser", updateTx, func(tx *Tx) error {
+		return purgeDeletedUser(ctx, tx, id, before)
+	})
 }
 
 func createUser(ctx context.Context, tx *Tx, user *fwt.User) error {
@@ -167,7 +172,12 @@ func findUsers(ctx context.Context, tx *Tx, filter fwt.UserFilter) (_ []*fwt.Use
 		where, args = append(where, fmt.Sprintf("email = $%d", argPosition)), append(args, *v)
 	}
 
-	query := `SELECT id, username, email, hashed_password, created_at, updated_at, COUNT(*) OVER() FROM "user"` + formatWhereClause(where) +
+	if v := filter.DeletedBefore; v != nil {
+		argPosition++
+		where, args = append(where, fmt.Sprintf("deleted_at <= $%d", argPosition)), append(args, (*NullTime)(v))
+	}
+
+	query := `SELECT id, username, email, hashed_password, created_at, updated_at, deleted_at, COUNT(*) OVER() FROM "user"` + formatWhereClause(where) +
 		` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
 
 	rows, err := tx.QueryContext(ctx, query, args...)
@@ -186,6 +196,7 @@ func findUsers(ctx context.Context, tx *Tx, filter fwt.UserFilter) (_ []*fwt.Use
 			&user.HashedPassword,
 			(*NullTime)(&user.CreatedAt),
 			(*NullTime)(&user.UpdatedAt),
+			(*NullTime)(&user.DeletedAt),
 			&n,
 		); err != nil {
 			return nil, n, err
@@ -255,11 +266,79 @@ func deleteUser(ctx context.Context, tx *Tx, id uint) error {
 		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this user")
 	}
 
+	return purgeUser(ctx, tx, id)
+}
+
+// setUserDeletedAt schedules the deletion of a user when deletedAt is set
+// and cancels it otherwise.
+func setUserDeletedAt(ctx context.Context, tx *Tx, id uint, deletedAt time.Time) (*fwt.User, error) {
+	user, err := findUserByID(ctx, tx, id)
+	if err != nil {
+		return user, err
+	} else if user.ID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this user.")
+	}
+
+	if !deletedAt.IsZero() && user.Deleted() {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User is already scheduled for deletion.")
+	} else if deletedAt.IsZero() && !user.Deleted() {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User is not scheduled for deletion.")
+	} else if deletedAt.IsZero() && tx.now.After(user.PurgeAt()) {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User can no longer be restored as the deletion grace period has ended.")
+	}
+
+	user.DeletedAt = deletedAt
+	user.UpdatedAt = tx.now
+
 	query := `
-	DELETE FROM "user" WHERE id = $1
+	UPDATE "user" SET deleted_at = $1, updated_at = $2
+	WHERE id = $3
 	`
-	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+	if _, err := tx.ExecContext(ctx, query, (*NullTime)(&user.DeletedAt), (*NullTime)(&user.UpdatedAt), id); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// purgeDeletedUser removes a user deleted before the given time. The check
+// is made again here so that a user restored since being found is kept.
+func purgeDeletedUser(ctx context.Context, tx *Tx, id uint, before time.Time) error {
+	user, err := findUserByID(ctx, tx, id)
+	if err != nil {
 		return err
+	} else if !user.Deleted() || user.DeletedAt.After(before) {
+		return fwt.Errorf(fwt.ECONFLICT, "User is not due to be purged.")
+	}
+
+	return purgeUser(ctx, tx, id)
+}
+
+// purgeUser removes a user and everything they own, children before their
+// parents, so that no foreign key is violated and no row is left behind.
+func purgeUser(ctx context.Context, tx *Tx, id uint) error {
+	const userWorkoutExercises = `
+	SELECT we.id FROM workout_exercise we
+	JOIN workout w ON w.id = we.workout_id
+	WHERE w.user_id = $1
+	`
+
+	for _, query := range []string{
+		`DELETE FROM exercise_set WHERE workout_exercise_id IN (` + userWorkoutExercises + `)`,
+		`DELETE FROM workout_exercise_status WHERE workout_exercise_id IN (` + userWorkoutExercises + `)`,
+		`DELETE FROM workout_exercise WHERE workout_id IN (SELECT id FROM workout WHERE user_id = $1)`,
+		`DELETE FROM workout WHERE user_id = $1`,
+		`DELETE FROM workout_report WHERE user_id = $1`,
+		`DELETE FROM goal WHERE user_id = $1`,
+		`DELETE FROM body_measurement WHERE user_id = $1`,
+		`DELETE FROM profile WHERE user_id = $1`,
+		`DELETE FROM calendar_feed WHERE user_id = $1`,
+		`DELETE FROM idempotency_key WHERE user_id = $1`,
+		`DELETE FROM "user" WHERE id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -267,11 +346,11 @@ func deleteUser(ctx context.Context, tx *Tx, id uint) error {
 
 func formatLimitOffset(limit, offset int) string {
 	if limit > 0 && offset > 0 {
-		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 	} else if limit > 0 {
-		return fmt.Sprintf("LIMIT %d", limit)
+		return fmt.Sprintf(" LIMIT %d", limit)
 	} else if offset > 0 {
-		return fmt.Sprintf("OFFSET %d", offset)
+		return fmt.Sprintf(" OFFSET %d", offset)
 	}
 	return ""
 }
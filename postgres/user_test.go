@@ -219,6 +219,37 @@ func TestUserService_FindUsers(t *testing.T) {
 		require.Equal(t, a[0].Email, email)
 		require.Equal(t, n, 1)
 	})
+
+	// Regression: LIMIT and OFFSET were appended without a leading space,
+	// so any paginated query was a syntax error.
+	t.Run("LimitOffset", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := postgres.NewUserService(db)
+
+		ctx := context.Background()
+		for i := 0; i < 4; i++ {
+			MustCreateUser(t, ctx, db, &fwt.User{Username: postgres.RandomUsername(), Email: postgres.RandomEmail(), HashedPassword: postgres.RandomHashedPassword()})
+		}
+
+		for _, tt := range []struct {
+			filter fwt.UserFilter
+			ids    []uint
+		}{
+			{fwt.UserFilter{Limit: 2}, []uint{1, 2}},
+			{fwt.UserFilter{Offset: 3}, []uint{4}},
+			{fwt.UserFilter{Limit: 2, Offset: 1}, []uint{2, 3}},
+		} {
+			a, n, err := s.FindUsers(ctx, tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, n, 4)
+			ids := make([]uint, len(a))
+			for i := range a {
+				ids[i] = a[i].ID
+			}
+			require.Equal(t, tt.ids, ids)
+		}
+	})
 }
 
 func MustCreateUser(tb testing.TB, ctx context.Context, db *postgres.DB, user *fwt.User) (*fwt.User, context.Context) {
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/maliByatzes/fwt"
@@ -17,81 +18,145 @@ func NewWorkoutService(db *DB) *WorkoutService {
 	return &WorkoutService{db: db}
 }
 
-func (s *WorkoutService) FindWorkoutByID(ctx context.Context, id uint) (*fwt.Workout, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+func (s *WorkoutService) FindWorkoutByID(ctx context.Context, id uint) (workout *fwt.Workout, err error) {
+	err = s.db.run(ctx, "WorkoutService.FindWorkoutByID", readTx, func(tx *Tx) error {
+		workout, err = findWorkoutByID(ctx, tx, id)
+		return err
+	})
+	return workout, err
+}
 
-	workout, err := findWorkoutByID(ctx, tx, id)
-	if err != nil {
-		return nil, err
-	}
+func (s *WorkoutService) FindWorkoutByIDUserID(ctx context.Context, id uint, userID uint) (workout *fwt.Workout, err error) {
+	err = s.db.run(ctx, "WorkoutService.FindWorkoutByIDUserID", readTx, func(tx *Tx) error {
+		workout, err = findWorkoutByIDUserID(ctx, tx, id, userID)
+		return err
+	})
+	return workout, err
+}
 
-	return workout, nil
+func (s *WorkoutService) FindWorkouts(ctx context.Context, filter fwt.WorkoutFilter) (a []*fwt.Workout, n int, err error) {
+	err = s.db.run(ctx, "WorkoutService.FindWorkouts", readTx, func(tx *Tx) error {
+		a, n, err = findWorkouts(ctx, tx, filter)
+		return err
+	})
+	return a, n, err
 }
 
-func (s *WorkoutService) FindWorkoutByIDUserID(ctx context.Context, id uint, userID uint) (*fwt.Workout, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+func (s *WorkoutService) CreateWorkout(ctx context.Context, workout *fwt.Workout) error {
+	return s.db.run(ctx, "WorkoutService.CreateWorkout", insertTx, func(tx *Tx) error {
+		if err := createWorkout(ctx, tx, workout); err != nil {
+			return err
+		}
 
-	workout, err := findWorkoutByIDUserID(ctx, tx, id, userID)
-	if err != nil {
-		return nil, err
-	}
+		for _, ex := range workout.Exercises {
+			exercise, err := findExerciseByName(ctx, tx, ex.Name)
+			if err != nil {
+				return err
+			}
 
-	return workout, nil
+			if err := createWorkoutExercise(ctx, tx, &fwt.WorkoutExercise{
+				WorkoutID:  workout.ID,
+				ExerciseID: exercise.ID,
+				Order:      1, // Hard-code for now...
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *WorkoutService) UpdateWorkout(ctx context.Context, id uint, upd fwt.WorkoutUpdate) (workout *fwt.Workout, err error) {
+	err = s.db.run(ctx, "WorkoutService.UpdateWorkout", updateTx, func(tx *Tx) error {
+		workout, err = updateWorkout(ctx, tx, id, upd)
+		return err
+	})
+	return workout, err
 }
 
-func (s *WorkoutService) FindWorkouts(ctx context.Context, filter fwt.WorkoutFilter) ([]*fwt.Workout, int, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+func (s *WorkoutService) RemoveExercisesFromWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	var workout *fwt.Workout
+	err := s.db.run(ctx, "WorkoutService.RemoveExercisesFromWorkout", updateTx, func(tx *Tx) (err error) {
+		workout, err = removeExercisesFromWorkout(ctx, tx, id, exercises)
+		return err
+	})
+	return workout, err
+}
 
-	return findWorkouts(ctx, tx, filter)
+func (s *WorkoutService) AddExercisesToWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	var workout *fwt.Workout
+	err := s.db.run(ctx, "WorkoutService.AddExercisesToWorkout", updateTx, func(tx *Tx) (err error) {
+		workout, err = addExercisesToWorkout(ctx, tx, id, exercises)
+		return err
+	})
+	return workout, err
 }
 
-func (s *WorkoutService) CreateWorkout(ctx context.Context, workout *fwt.Workout) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+func (s *WorkoutService) StartWorkout(ctx context.Context, id uint) (workout *fwt.Workout, err error) {
+	err = s.db.run(ctx, "WorkoutService.StartWorkout", updateTx, func(tx *Tx) error {
+		workout, err = startWorkout(ctx, tx, id)
+		return err
+	})
+	return workout, err
+}
 
-	if err := createWorkout(ctx, tx, workout); err != nil {
+func (s *WorkoutService) FinishWorkout(ctx context.Context, id uint) (workout *fwt.Workout, err error) {
+	err = s.db.run(ctx, "WorkoutService.FinishWorkout", updateTx, func(tx *Tx) error {
+		workout, err = finishWorkout(ctx, tx, id)
 		return err
-	}
+	})
+	return workout, err
+}
 
-	for _, ex := range workout.Exercises {
-		exercise, err := findExerciseByName(ctx, tx, ex.Name)
+func (s *WorkoutService) SkipWorkout(ctx context.Context, id uint) (workout *fwt.Workout, err error) {
+	err = s.db.run(ctx, "WorkoutService.SkipWorkout", updateTx, func(tx *Tx) error {
+		workout, err = skipWorkout(ctx, tx, id)
+		return err
+	})
+	return workout, err
+}
+
+func (s *WorkoutService) LogWorkout(ctx context.Context, workout *fwt.Workout) error {
+	return s.db.run(ctx, "WorkoutService.LogWorkout", insertTx, func(tx *Tx) error {
+		return logWorkout(ctx, tx, workout)
+	})
+}
+
+func (s *WorkoutService) QuickStartWorkout(ctx context.Context, workout *fwt.Workout) error {
+	return s.db.run(ctx, "WorkoutService.QuickStartWorkout", insertTx, func(tx *Tx) error {
+		loc, err := findUserLocation(ctx, tx, fwt.UserIDFromContext(ctx))
 		if err != nil {
 			return err
 		}
 
-		if err := createWorkoutExercise(ctx, tx, &fwt.WorkoutExercise{
-			WorkoutID:  workout.ID,
-			ExerciseID: exercise.ID,
-			Order:      1, // Hard-code for now...
-		}); err != nil {
+		workout.QuickStart(tx.now.In(loc))
+		if err := createWorkout(ctx, tx, workout); err != nil {
 			return err
 		}
-	}
-
-	return tx.Commit()
-}
 
-func (s *WorkoutService) UpdateWorkout(ctx context.Context, id uint, upd fwt.WorkoutUpdate) (*fwt.Workout, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+		for _, ex := range workout.Exercises {
+			exercise, err := findExerciseByName(ctx, tx, ex.Name)
+			if err != nil {
+				return err
+			}
 
-	workout, err := updateWorkout(ctx, tx, id, upd)
-	if err != nil {
-		return workout, err
-	} else if err := tx.Commit(); err != nil {
-		return workout, err
-	}
+			if err := addExerciseToWorkout(ctx, tx, workout, exercise); err != nil {
+				return err
+			}
+		}
 
-	return workout, nil
+		return nil
+	})
 }
 
-func (s *WorkoutService) RemoveExercisesFromWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
+func (s *WorkoutService) DeleteWorkout(ctx context.Context, id uint, version *uint) error {
+	return s.db.run(ctx, "WorkoutService.DeleteWorkout", updateTx, func(tx *Tx) error {
+		return deleteWorkout(ctx, tx, id, version)
+	})
+}
 
+func removeExercisesFromWorkout(ctx context.Context, tx *Tx, id uint, exercises []string) (*fwt.Workout, error) {
 	workout, err := findWorkoutByID(ctx, tx, id)
 	if err != nil {
 		return nil, err
@@ -112,17 +177,14 @@ func (s *WorkoutService) RemoveExercisesFromWorkout(ctx context.Context, id uint
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := touchWorkout(ctx, tx, workout); err != nil {
 		return workout, err
 	}
 
 	return workout, nil
 }
 
-func (s *WorkoutService) AddExercisesToWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
+func addExercisesToWorkout(ctx context.Context, tx *Tx, id uint, exercises []string) (*fwt.Workout, error) {
 	workout, err := findWorkoutByID(ctx, tx, id)
 	if err != nil {
 		return nil, err
@@ -137,33 +199,21 @@ func (s *WorkoutService) AddExercisesToWorkout(ctx context.Context, id uint, exe
 		}
 
 		if !implContains2(workout.Exercises, exercise) {
-			err = addExerciseToWorkout(ctx, tx, workout, exercise)
-			if err != nil {
-				return workout, nil
+			if err := addExerciseToWorkout(ctx, tx, workout, exercise); err != nil {
+				return workout, err
 			}
 
 			workout.Exercises = append(workout.Exercises, exercise)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := touchWorkout(ctx, tx, workout); err != nil {
 		return workout, err
 	}
 
 	return workout, nil
 }
 
-func (s *WorkoutService) DeleteWorkout(ctx context.Context, id uint) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := deleteWorkout(ctx, tx, id); err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
 func createWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
 	user := fwt.UserFromContext(ctx)
 	if user == nil {
@@ -171,26 +221,38 @@ func createWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
 	}
 	workout.UserID = fwt.UserIDFromContext(ctx)
 
+	workout.Version = 1
+	if workout.Status == "" {
+		workout.Status = fwt.WorkoutStatusPlanned
+	}
+	workout.ScheduledDate = fwt.Date(workout.ScheduledDate)
 	workout.CreatedAt = tx.now
 	workout.UpdatedAt = workout.CreatedAt
 
-	if err := workout.Validate(); err != nil {
+	loc, err := findUserLocation(ctx, tx, workout.UserID)
+	if err != nil {
+		return err
+	} else if err := workout.Validate(tx.now.In(loc)); err != nil {
 		return err
 	}
 
 	query := `
-	INSERT INTO workout (user_id, name, scheduled_date, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5) RETURNING id
+	INSERT INTO workout (user_id, name, scheduled_date, status, started_at, finished_at, version, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id
 	`
 	args := []interface{}{
 		workout.UserID,
 		workout.Name,
 		workout.ScheduledDate,
+		workout.Status,
+		(*NullTime)(&workout.StartedAt),
+		(*NullTime)(&workout.FinishedAt),
+		workout.Version,
 		(*NullTime)(&workout.CreatedAt),
 		(*NullTime)(&workout.UpdatedAt),
 	}
 
-	err := tx.QueryRowxContext(ctx, query, args...).Scan(&workout.ID)
+	err = tx.QueryRowxContext(ctx, query, args...).Scan(&workout.ID)
 	if err != nil {
 		return err
 	}
@@ -198,6 +260,54 @@ func createWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
 	return nil
 }
 
+// logWorkout records a workout that already happened. Each exercise is
+// stored with its logged status and sets.
+func logWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
+	loc, err := findUserLocation(ctx, tx, fwt.UserIDFromContext(ctx))
+	if err != nil {
+		return err
+	} else if err := workout.Log(tx.now.In(loc)); err != nil {
+		return err
+	} else if err := createWorkout(ctx, tx, workout); err != nil {
+		return err
+	}
+
+	for _, ex := range workout.Exercises {
+		exercise, err := findExerciseByName(ctx, tx, ex.Name)
+		if err != nil {
+			return err
+		}
+
+		we := &fwt.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, Order: 1}
+		if err := createWorkoutExercise(ctx, tx, we); err != nil {
+			return err
+		}
+
+		status, err := findWEStatusByWEID(ctx, tx, we.ID)
+		if err != nil {
+			return err
+		}
+		if ex.Status, err = updateWEStatus(ctx, tx, status.ID, fwt.WEStatusUpdate{
+			Status:      &ex.Status.Status,
+			Comments:    &ex.Status.Comments,
+			CompletedAt: &ex.Status.CompletedAt,
+		}); err != nil {
+			return err
+		}
+
+		for _, set := range ex.Sets {
+			set.WorkoutExerciseID = we.ID
+			if err := createSet(ctx, tx, set); err != nil {
+				return err
+			}
+		}
+		ex.ID, ex.Description = exercise.ID, exercise.Description
+		ex.CreatedAt, ex.UpdatedAt = exercise.CreatedAt, exercise.UpdatedAt
+	}
+
+	return nil
+}
+
 func findWorkoutByID(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
 	a, _, err := findWorkouts(ctx, tx, fwt.WorkoutFilter{ID: &id})
 	if err != nil {
@@ -240,12 +350,26 @@ func findWorkouts(ctx context.Context, tx *Tx, filter fwt.WorkoutFilter) (_ []*f
 		argPos++
 		where, args = append(where, fmt.Sprintf("w.scheduled_date = $%d", argPos)), append(args, *v)
 	}
+	if v := filter.From; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("w.scheduled_date >= $%d", argPos)), append(args, *v)
+	}
+	if v := filter.To; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("w.scheduled_date <= $%d", argPos)), append(args, *v)
+	}
+	if v := filter.Status; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("w.status = $%d", argPos)), append(args, *v)
+	}
 
 	query := `
-	SELECT w.id, w.user_id, w.name, w.scheduled_date, w.created_at, w.updated_at, e.id, e.name, e.description, e.created_at, e.updated_at, COUNT(*) OVER()
+	SELECT w.id, w.user_id, w.name, w.scheduled_date, w.status, w.started_at, w.finished_at, w.version, w.created_at, w.updated_at, e.id, e.name, e.description, e.created_at, e.updated_at,
+		s.id, we.id, s.status, s.comments, s.completed_at, s.created_at, s.updated_at, COUNT(*) OVER()
 	FROM workout AS w
-	INNER JOIN workout_exercise AS we ON we.workout_id = w.id
-	INNER JOIN exercise as e ON e.id = we.exercise_id` + formatWhereClause(where) + ` ORDER BY w.id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+	LEFT JOIN workout_exercise AS we ON we.workout_id = w.id
+	LEFT JOIN exercise as e ON e.id = we.exercise_id
+	LEFT JOIN workout_exercise_status AS s ON s.workout_exercise_id = we.id` + formatWhereClause(where) + ` ORDER BY w.id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
 
 	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -254,36 +378,86 @@ func findWorkouts(ctx context.Context, tx *Tx, filter fwt.WorkoutFilter) (_ []*f
 	defer rows.Close()
 
 	workouts := make([]*fwt.Workout, 0)
+	byWE := make(map[uint]*fwt.Exercise)
 	for rows.Next() {
 		var workout fwt.Workout
 		var exercise fwt.Exercise
+		var status fwt.WEStatus
+		var exerciseID, statusID, weID sql.NullInt64
+		var exerciseName, description, statusName, comments sql.NullString
 		if err := rows.Scan(
 			&workout.ID,
 			&workout.UserID,
 			&workout.Name,
 			&workout.ScheduledDate,
+			&workout.Status,
+			(*NullTime)(&workout.StartedAt),
+			(*NullTime)(&workout.FinishedAt),
+			&workout.Version,
 			(*NullTime)(&workout.CreatedAt),
 			(*NullTime)(&workout.UpdatedAt),
-			&exercise.ID,
-			&exercise.Name,
-			&exercise.Description,
+			&exerciseID,
+			&exerciseName,
+			&description,
 			(*NullTime)(&exercise.CreatedAt),
 			(*NullTime)(&exercise.UpdatedAt),
+			&statusID,
+			&weID,
+			&statusName,
+			&comments,
+			(*NullTime)(&status.CompletedAt),
+			(*NullTime)(&status.CreatedAt),
+			(*NullTime)(&status.UpdatedAt),
 			&n,
 		); err != nil {
 			return nil, n, err
 		}
 
+		w := &workout
 		if index := implContains(workouts, &workout); index != -1 {
-			workouts[index].Exercises = append(workouts[index].Exercises, &exercise)
+			w = workouts[index]
 		} else {
-			workout.Exercises = append(workout.Exercises, &exercise)
-			workouts = append(workouts, &workout)
+			workouts = append(workouts, w)
+		}
+
+		// Workouts that were quick started may not have any exercises yet.
+		if !exerciseID.Valid {
+			continue
 		}
+		exercise.ID, exercise.Name, exercise.Description = uint(exerciseID.Int64), exerciseName.String, description.String
+
+		if statusID.Valid {
+			status.ID, status.WorkoutExerciseID = uint(statusID.Int64), uint(weID.Int64)
+			status.Status, status.Comments = statusName.String, comments.String
+			exercise.Status = &status
+		}
+
+		w.Exercises = append(w.Exercises, &exercise)
+		byWE[uint(weID.Int64)] = &exercise
 	}
 	if err := rows.Err(); err != nil {
 		return nil, 0, err
 	}
+	rows.Close()
+
+	if len(workouts) == 0 {
+		return workouts, n, nil
+	}
+
+	ids := make([]int64, 0, len(workouts))
+	for _, w := range workouts {
+		ids = append(ids, int64(w.ID))
+	}
+
+	sets, err := findSetsByWorkoutIDs(ctx, tx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for weID, a := range sets {
+		if exercise := byWE[weID]; exercise != nil {
+			exercise.Sets = a
+		}
+	}
 
 	return workouts, n, nil
 }
@@ -294,17 +468,22 @@ func updateWorkout(ctx context.Context, tx *Tx, id uint, upd fwt.WorkoutUpdate)
 		return workout, err
 	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
 		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this workout.")
+	} else if v := upd.Version; v != nil && *v != workout.Version {
+		return workout, fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
 	}
 
 	if v := upd.Name; v != nil {
 		workout.Name = *v
 	}
 	if v := upd.ScheduledDate; v != nil {
-		workout.ScheduledDate = *v
+		workout.ScheduledDate = fwt.Date(*v)
 	}
 	workout.UpdatedAt = tx.now
 
-	if err := workout.Validate(); err != nil {
+	loc, err := findUserLocation(ctx, tx, workout.UserID)
+	if err != nil {
+		return workout, err
+	} else if err := workout.Validate(tx.now.In(loc)); err != nil {
 		return workout, err
 	}
 
@@ -314,20 +493,129 @@ func updateWorkout(ctx context.Context, tx *Tx, id uint, upd fwt.WorkoutUpdate)
 		workout.UpdatedAt,
 		workout.ID,
 		workout.UserID,
+		workout.Version,
 	}
 	query := `
-	UPDATE workout SET name = $1, scheduled_date = $2, updated_at = $3
-	WHERE id = $4 AND user_id = $5
+	UPDATE workout SET name = $1, scheduled_date = $2, updated_at = $3, version = version + 1
+	WHERE id = $4 AND user_id = $5 AND version = $6
 	`
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return workout, err
+	} else if n, err := result.RowsAffected(); err != nil {
+		return workout, err
+	} else if n == 0 {
+		return workout, fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
 	}
+	workout.Version++
 
 	return workout, nil
 }
 
+// touchWorkout bumps the version of a workout whose exercises have changed so
+// that cached copies are invalidated.
+func touchWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
+	query := `
+	UPDATE workout SET updated_at = $1, version = version + 1
+	WHERE id = $2 RETURNING version
+	`
+	if err := tx.QueryRowxContext(ctx, query, (*NullTime)(&tx.now), workout.ID).Scan(&workout.Version); err != nil {
+		return err
+	}
+	workout.UpdatedAt = tx.now
+
+	return nil
+}
+
+func startWorkout(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
+	return transitionWorkout(ctx, tx, id, func(workout *fwt.Workout) (string, error) {
+		return fwt.WorkoutStatusInProgress, nil
+	})
+}
+
+func finishWorkout(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
+	return transitionWorkout(ctx, tx, id, func(workout *fwt.Workout) (string, error) {
+		statuses, _, err := findWEStatuses(ctx, tx, fwt.WEStatusFilter{WorkoutID: &workout.ID})
+		if err != nil {
+			return "", err
+		}
+		return fwt.FinishedWorkoutStatus(statuses), nil
+	})
+}
+
+func skipWorkout(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
+	return transitionWorkout(ctx, tx, id, func(workout *fwt.Workout) (string, error) {
+		return fwt.WorkoutStatusSkipped, nil
+	})
+}
+
+// transitionWorkout moves a workout owned by the current user to the status
+// returned by next and saves it.
+func transitionWorkout(ctx context.Context, tx *Tx, id uint, next func(workout *fwt.Workout) (string, error)) (*fwt.Workout, error) {
+	workout, err := findWorkoutByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this workout.")
+	}
+
+	status, err := next(workout)
+	if err != nil {
+		return workout, err
+	} else if err := workout.Transition(status, tx.now); err != nil {
+		return workout, err
+	}
+
+	return workout, updateWorkoutStatus(ctx, tx, workout)
+}
+
+// updateWorkoutStatus saves the status, start and finish times of a workout
+// after a transition and bumps its version.
+func updateWorkoutStatus(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
+	workout.UpdatedAt = tx.now
+
+	query := `
+	UPDATE workout SET status = $1, started_at = $2, finished_at = $3, updated_at = $4, version = version + 1
+	WHERE id = $5 RETURNING version
+	`
+	args := []interface{}{
+		workout.Status,
+		(*NullTime)(&workout.StartedAt),
+		(*NullTime)(&workout.FinishedAt),
+		(*NullTime)(&workout.UpdatedAt),
+		workout.ID,
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&workout.Version)
+}
+
+// completeWorkout completes the workout of a workout exercise once all of
+// its exercises are completed. Workouts that are already finished or
+// skipped are left alone.
+func completeWorkout(ctx context.Context, tx *Tx, workoutExerciseID uint) error {
+	we, err := findWorkoutExerciseByID(ctx, tx, workoutExerciseID)
+	if err != nil {
+		return err
+	}
+
+	statuses, _, err := findWEStatuses(ctx, tx, fwt.WEStatusFilter{WorkoutID: &we.WorkoutID})
+	if err != nil {
+		return err
+	} else if !fwt.AllExercisesCompleted(statuses) {
+		return nil
+	}
+
+	workout, err := findWorkoutByID(ctx, tx, we.WorkoutID)
+	if err != nil {
+		return err
+	} else if err := workout.Transition(fwt.WorkoutStatusCompleted, tx.now); err != nil {
+		return nil
+	}
+
+	return updateWorkoutStatus(ctx, tx, workout)
+}
+
 func removeExerciseFromWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout, exercise *fwt.Exercise) error {
 	a, _, err := findWorkoutExercises(ctx, tx, fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID, ExerciseID: &exercise.ID})
 	if err != nil {
@@ -356,7 +644,7 @@ func addExerciseToWorkout(ctx context.Context, tx *Tx, wokout *fwt.Workout, exer
 	return nil
 }
 
-func deleteWorkout(ctx context.Context, tx *Tx, id uint) error {
+func deleteWorkout(ctx context.Context, tx *Tx, id uint, version *uint) error {
 	workout, err := findWorkoutByID(ctx, tx, id)
 	if err != nil {
 		return err
@@ -376,16 +664,26 @@ func deleteWorkout(ctx context.Context, tx *Tx, id uint) error {
 		}
 	}
 
+	if version == nil {
+		version = &workout.Version
+	}
+
 	args := []interface{}{
 		workout.ID,
 		workout.UserID,
+		*version,
 	}
 	query := `
-	DELETE FROM workout WHERE id = $1 AND user_id = $2
+	DELETE FROM workout WHERE id = $1 AND user_id = $2 AND version = $3
 	`
 
-	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	} else if n, err := result.RowsAffected(); err != nil {
 		return err
+	} else if n == 0 {
+		return fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
 	}
 
 	return nil
@@ -17,34 +17,26 @@ func NewWorkoutExerciseService(db *DB) *WorkoutExerciseService {
 	return &WorkoutExerciseService{db: db}
 }
 
-func (s *WorkoutExerciseService) FindWorkoutExerciseByID(ctx context.Context, id uint) (*fwt.WorkoutExercise, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	we, err := findWorkoutExerciseByID(ctx, tx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return we, nil
+func (s *WorkoutExerciseService) FindWorkoutExerciseByID(ctx context.Context, id uint) (we *fwt.WorkoutExercise, err error) {
+	err = s.db.run(ctx, "WorkoutExerciseService.FindWorkoutExerciseByID", readTx, func(tx *Tx) error {
+		we, err = findWorkoutExerciseByID(ctx, tx, id)
+		return err
+	})
+	return we, err
 }
 
-func (s *WorkoutExerciseService) FindWorkoutExercises(ctx context.Context, filter fwt.WorkoutExerciseFilter) ([]*fwt.WorkoutExercise, int, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	return findWorkoutExercises(ctx, tx, filter)
+func (s *WorkoutExerciseService) FindWorkoutExercises(ctx context.Context, filter fwt.WorkoutExerciseFilter) (a []*fwt.WorkoutExercise, n int, err error) {
+	err = s.db.run(ctx, "WorkoutExerciseService.FindWorkoutExercises", readTx, func(tx *Tx) error {
+		a, n, err = findWorkoutExercises(ctx, tx, filter)
+		return err
+	})
+	return a, n, err
 }
 
 func (s *WorkoutExerciseService) CreateWorkoutExercise(ctx context.Context, workoutExercise *fwt.WorkoutExercise) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := createWorkoutExercise(ctx, tx, workoutExercise); err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return s.db.run(ctx, "WorkoutExerciseService.CreateWorkoutExercise", insertTx, func(tx *Tx) error {
+		return createWorkoutExercise(ctx, tx, workoutExercise)
+	})
 }
 
 func (s *WorkoutExerciseService) UpdateWorkoutExercise(ctx context.Context, id uint, upd fwt.WorkoutExerciseUpdate) (*fwt.WorkoutExercise, error) {
@@ -52,14 +44,9 @@ func (s *WorkoutExerciseService) UpdateWorkoutExercise(ctx context.Context, id u
 }
 
 func (s *WorkoutExerciseService) DeleteWorkoutExercise(ctx context.Context, id uint) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := deleteWorkoutExercise(ctx, tx, id); err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return s.db.run(ctx, "WorkoutExerciseService.DeleteWorkoutExercise", updateTx, func(tx *Tx) error {
+		return deleteWorkoutExercise(ctx, tx, id)
+	})
 }
 
 func createWorkoutExercise(ctx context.Context, tx *Tx, workoutExercise *fwt.WorkoutExercise) error {
@@ -108,7 +95,7 @@ func createWorkoutExercise(ctx context.Context, tx *Tx, workoutExercise *fwt.Wor
 
 	if err := createWEStatus(ctx, tx, &fwt.WEStatus{
 		WorkoutExerciseID: workoutExercise.ID,
-		Status:            "pending",
+		Status:            fwt.WEStatusPending,
 	}); err != nil {
 		return err
 	}
@@ -188,16 +175,21 @@ func deleteWorkoutExercise(ctx context.Context, tx *Tx, id uint) error {
 		return err
 	}
 
-	we, err := findWEStatusByWEID(ctx, tx, workoutExercise.ID)
-	if err != nil {
+	query := `
+	DELETE FROM exercise_set WHERE workout_exercise_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, workoutExercise.ID); err != nil {
 		return err
 	}
 
-	if err := deleteWEStatus(ctx, tx, we.ID); err != nil {
+	query = `
+	DELETE FROM workout_exercise_status WHERE workout_exercise_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, workoutExercise.ID); err != nil {
 		return err
 	}
 
-	query := `
+	query = `
 	DELETE FROM workout_exercise WHERE id = $1
 	`
 	if _, err := tx.ExecContext(ctx, query, id); err != nil {
@@ -17,71 +17,53 @@ func NewWEStatusService(db *DB) *WEStatusService {
 	return &WEStatusService{db: db}
 }
 
-func (s *WEStatusService) FindWEStatusByID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	we, err := findWEStatusByID(ctx, tx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return we, nil
+func (s *WEStatusService) FindWEStatusByID(ctx context.Context, id uint) (we *fwt.WEStatus, err error) {
+	err = s.db.run(ctx, "WEStatusService.FindWEStatusByID", readTx, func(tx *Tx) error {
+		we, err = findWEStatusByID(ctx, tx, id)
+		return err
+	})
+	return we, err
 }
 
-func (s *WEStatusService) FindWEStatusByWEID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	we, err := findWEStatusByWEID(ctx, tx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	return we, nil
+func (s *WEStatusService) FindWEStatusByWEID(ctx context.Context, id uint) (we *fwt.WEStatus, err error) {
+	err = s.db.run(ctx, "WEStatusService.FindWEStatusByWEID", readTx, func(tx *Tx) error {
+		we, err = findWEStatusByWEID(ctx, tx, id)
+		return err
+	})
+	return we, err
 }
 
-func (s *WEStatusService) FindWEStatuses(ctx context.Context, filter fwt.WEStatusFilter) ([]*fwt.WEStatus, int, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	return findWEStatuses(ctx, tx, filter)
+func (s *WEStatusService) FindWEStatuses(ctx context.Context, filter fwt.WEStatusFilter) (a []*fwt.WEStatus, n int, err error) {
+	err = s.db.run(ctx, "WEStatusService.FindWEStatuses", readTx, func(tx *Tx) error {
+		a, n, err = findWEStatuses(ctx, tx, filter)
+		return err
+	})
+	return a, n, err
 }
 
 func (s *WEStatusService) CreateWEStatus(ctx context.Context, we *fwt.WEStatus) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := createWEStatus(ctx, tx, we); err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return s.db.run(ctx, "WEStatusService.CreateWEStatus", insertTx, func(tx *Tx) error {
+		return createWEStatus(ctx, tx, we)
+	})
 }
 
 func (s *WEStatusService) UpdateWEStatus(ctx context.Context, id uint, upd fwt.WEStatusUpdate) (*fwt.WEStatus, error) {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	we, err := updateWEStatus(ctx, tx, id, upd)
+	var we *fwt.WEStatus
+	err := s.db.run(ctx, "WEStatusService.UpdateWEStatus", updateTx, func(tx *Tx) (err error) {
+		we, err = updateWEStatus(ctx, tx, id, upd)
+		return err
+	})
 	if err != nil {
 		return nil, err
-	} else if err := tx.Commit(); err != nil {
-		return nil, err
 	}
 
 	return we, nil
 }
 
 func (s *WEStatusService) DeleteWEStatus(ctx context.Context, id uint) error {
-	tx := s.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
-
-	if err := deleteWEStatus(ctx, tx, id); err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return s.db.run(ctx, "WEStatusService.DeleteWEStatus", updateTx, func(tx *Tx) error {
+		return deleteWEStatus(ctx, tx, id)
+	})
 }
 
 func createWEStatus(ctx context.Context, tx *Tx, we *fwt.WEStatus) error {
@@ -112,7 +94,12 @@ func createWEStatus(ctx context.Context, tx *Tx, we *fwt.WEStatus) error {
 
 	err := tx.QueryRowxContext(ctx, query, args...).Scan(&we.ID)
 	if err != nil {
-		return err
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "workout_exercise_status_workout_exercise_id_key"`:
+			return fwt.Errorf(fwt.ECONFLICT, "WEStatus already exists.")
+		default:
+			return err
+		}
 	}
 
 	return nil
@@ -134,6 +121,10 @@ func findWEStatuses(ctx context.Context, tx *Tx, filter fwt.WEStatusFilter) (_ [
 		argPos++
 		where, args = append(where, fmt.Sprintf("status = $%d", argPos)), append(args, *v)
 	}
+	if v := filter.WorkoutID; v != nil {
+		argPos++
+		where, args = append(where, fmt.Sprintf("workout_exercise_id IN (SELECT id FROM workout_exercise WHERE workout_id = $%d)", argPos)), append(args, *v)
+	}
 
 	query := `
 	SELECT id, workout_exercise_id, status, comments, completed_at, created_at, updated_at, COUNT(*) OVER()
@@ -231,6 +222,21 @@ func updateWEStatus(ctx context.Context, tx *Tx, id uint, upd fwt.WEStatusUpdate
 		return we, err
 	}
 
+	// Statuses are returned with their workout, so any change to them is a
+	// new version of it.
+	workoutExercise, err := findWorkoutExerciseByID(ctx, tx, we.WorkoutExerciseID)
+	if err != nil {
+		return we, err
+	} else if err := touchWorkout(ctx, tx, &fwt.Workout{ID: workoutExercise.WorkoutID}); err != nil {
+		return we, err
+	}
+
+	if we.Status == fwt.WEStatusCompleted {
+		if err := completeWorkout(ctx, tx, we.WorkoutExerciseID); err != nil {
+			return we, err
+		}
+	}
+
 	return we, nil
 }
 
@@ -5,6 +5,10 @@ import (
 	"time"
 )
 
+// Profile holds the personal details of a user. Height is in centimetres
+// and Weight in kilograms; Units is the user's preferred way of seeing
+// and entering them, defaulting to metric. Timezone is an IANA name, such
+// as Europe/Berlin, that decides where the user's days and weeks begin.
 type Profile struct {
 	ID          uint      `json:"id"`
 	UserID      uint      `json:"user_id"`
@@ -14,6 +18,9 @@ type Profile struct {
 	Gender      string    `json:"gender"`
 	Height      float64   `json:"height"`
 	Weight      float64   `json:"weight"`
+	Units       Units     `json:"units"`
+	Timezone    string    `json:"timezone"`
+	Version     uint      `json:"version"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -22,7 +29,20 @@ func (s *Profile) Validate() error {
 	if s.UserID == uint(0) {
 		return Errorf(EINVALID, "UserID is required.")
 	}
-	return nil
+	if _, err := LoadLocation(s.Timezone); err != nil {
+		return err
+	}
+	return s.Units.Validate()
+}
+
+// Location returns the location of the profile's timezone, or UTC if it
+// cannot be loaded.
+func (s *Profile) Location() *time.Location {
+	loc, err := LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
 
 type ProfileService interface {
@@ -31,7 +51,10 @@ type ProfileService interface {
 	FindProfiles(ctx context.Context, filter ProfileFilter) ([]*Profile, int, error)
 	CreateProfile(ctx context.Context, profile *Profile) error
 	UpdateProfile(ctx context.Context, id uint, upd ProfileUpdate) (*Profile, error)
-	DeleteProfile(ctx context.Context, id uint) error
+
+	// DeleteProfile deletes the profile. When version is set it must match
+	// the current version of the profile or the delete fails with ESTALE.
+	DeleteProfile(ctx context.Context, id uint, version *uint) error
 }
 
 type ProfileFilter struct {
@@ -55,4 +78,13 @@ type ProfileUpdate struct {
 	Gender      *string    `json:"gender"`
 	Height      *float64   `json:"height"`
 	Weight      *float64   `json:"weight"`
+
+	// Units, when set, replaces the units that it names and keeps the rest.
+	Units *Units `json:"units"`
+
+	Timezone *string `json:"timezone"`
+
+	// Version, when set, must match the current version of the profile
+	// or the update fails with ESTALE.
+	Version *uint `json:"version"`
 }
@@ -0,0 +1,30 @@
+package fwt
+
+import "time"
+
+// Set is one set of an exercise within a workout: a number of repetitions
+// performed with a load in kilograms. Sets are recorded when a workout is
+// logged and returned with the exercises of a workout.
+type Set struct {
+	ID                uint      `json:"id"`
+	WorkoutExerciseID uint      `json:"workout_exercise_id"`
+	Reps              uint      `json:"reps"`
+	Load              float64   `json:"load"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (s *Set) Validate() error {
+	if s.WorkoutExerciseID <= 0 {
+		return Errorf(EINVALID, "WorkoutExerciseID is required.")
+	}
+
+	if s.Reps <= 0 {
+		return Errorf(EINVALID, "Reps is required.")
+	}
+
+	if s.Load < 0 {
+		return Errorf(EINVALID, "Load is invalid.")
+	}
+
+	return nil
+}
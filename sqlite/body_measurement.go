@@ -0,0 +1,314 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.BodyMeasurementService = (*BodyMeasurementService)(nil)
+
+type BodyMeasurementService struct {
+	db *DB
+}
+
+func NewBodyMeasurementService(db *DB) *BodyMeasurementService {
+	return &BodyMeasurementService{db: db}
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurementByID(ctx context.Context, id uint) (*fwt.BodyMeasurement, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findBodyMeasurementByID(ctx, tx, id)
+}
+
+func (s *BodyMeasurementService) FindBodyMeasurements(ctx context.Context, filter fwt.BodyMeasurementFilter) ([]*fwt.BodyMeasurement, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findBodyMeasurements(ctx, tx, filter)
+}
+
+func (s *BodyMeasurementService) CreateBodyMeasurement(ctx context.Context, m *fwt.BodyMeasurement) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createBodyMeasurement(ctx, tx, m); err != nil {
+		return err
+	} else if err := syncProfileWeight(ctx, tx, m.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *BodyMeasurementService) UpdateBodyMeasurement(ctx context.Context, id uint, upd fwt.BodyMeasurementUpdate) (*fwt.BodyMeasurement, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	m, err := updateBodyMeasurement(ctx, tx, id, upd)
+	if err != nil {
+		return m, err
+	} else if err := syncProfileWeight(ctx, tx, m.UserID); err != nil {
+		return m, err
+	} else if err := tx.Commit(); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+func (s *BodyMeasurementService) DeleteBodyMeasurement(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteBodyMeasurement(ctx, tx, id); err != nil {
+		return err
+	} else if err := syncProfileWeight(ctx, tx, fwt.UserIDFromContext(ctx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createBodyMeasurement(ctx context.Context, tx *Tx, m *fwt.BodyMeasurement) error {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to record a body measurement.")
+	}
+	m.UserID = userID
+
+	if m.MeasuredAt.IsZero() {
+		m.MeasuredAt = tx.now
+	}
+	m.CreatedAt = tx.now
+	m.UpdatedAt = m.CreatedAt
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO body_measurement (user_id, measured_at, weight, body_fat, waist, chest, arms, thighs, resting_heart_rate, notes, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		m.UserID,
+		(*NullTime)(&m.MeasuredAt),
+		m.Weight,
+		m.BodyFat,
+		m.Waist,
+		m.Chest,
+		m.Arms,
+		m.Thighs,
+		m.RestingHeartRate,
+		m.Notes,
+		(*NullTime)(&m.CreatedAt),
+		(*NullTime)(&m.UpdatedAt),
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&m.ID)
+}
+
+func findBodyMeasurementByID(ctx context.Context, tx *Tx, id uint) (*fwt.BodyMeasurement, error) {
+	a, _, err := findBodyMeasurements(ctx, tx, fwt.BodyMeasurementFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Body Measurement not found."}
+	}
+	return a[0], nil
+}
+
+func findBodyMeasurements(ctx context.Context, tx *Tx, filter fwt.BodyMeasurementFilter) (_ []*fwt.BodyMeasurement, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+	if v := filter.From; v != nil {
+		where, args = append(where, "measured_at >= ?"), append(args, (*NullTime)(v))
+	}
+	if v := filter.To; v != nil {
+		where, args = append(where, "measured_at <= ?"), append(args, (*NullTime)(v))
+	}
+
+	query := `
+	SELECT id, user_id, measured_at, weight, body_fat, waist, chest, arms, thighs, resting_heart_rate, notes, created_at, updated_at, COUNT(*) OVER()
+	FROM body_measurement` + formatWhereClause(where) + ` ORDER BY measured_at ASC, id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	measurements := make([]*fwt.BodyMeasurement, 0)
+	for rows.Next() {
+		var m fwt.BodyMeasurement
+		if err := rows.Scan(
+			&m.ID,
+			&m.UserID,
+			(*NullTime)(&m.MeasuredAt),
+			&m.Weight,
+			&m.BodyFat,
+			&m.Waist,
+			&m.Chest,
+			&m.Arms,
+			&m.Thighs,
+			&m.RestingHeartRate,
+			&m.Notes,
+			(*NullTime)(&m.CreatedAt),
+			(*NullTime)(&m.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		measurements = append(measurements, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return measurements, n, nil
+}
+
+func updateBodyMeasurement(ctx context.Context, tx *Tx, id uint, upd fwt.BodyMeasurementUpdate) (*fwt.BodyMeasurement, error) {
+	m, err := findBodyMeasurementByID(ctx, tx, id)
+	if err != nil {
+		return m, err
+	} else if m.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this body measurement.")
+	}
+
+	if v := upd.MeasuredAt; v != nil {
+		m.MeasuredAt = *v
+	}
+	if v := upd.Weight; v != nil {
+		m.Weight = *v
+	}
+	if v := upd.BodyFat; v != nil {
+		m.BodyFat = *v
+	}
+	if v := upd.Waist; v != nil {
+		m.Waist = *v
+	}
+	if v := upd.Chest; v != nil {
+		m.Chest = *v
+	}
+	if v := upd.Arms; v != nil {
+		m.Arms = *v
+	}
+	if v := upd.Thighs; v != nil {
+		m.Thighs = *v
+	}
+	if v := upd.RestingHeartRate; v != nil {
+		m.RestingHeartRate = *v
+	}
+	if v := upd.Notes; v != nil {
+		m.Notes = *v
+	}
+	m.UpdatedAt = tx.now
+
+	if err := m.Validate(); err != nil {
+		return m, err
+	}
+
+	query := `
+	UPDATE body_measurement SET measured_at = ?, weight = ?, body_fat = ?, waist = ?, chest = ?, arms = ?, thighs = ?, resting_heart_rate = ?, notes = ?, updated_at = ?
+	WHERE id = ?
+	`
+	args := []interface{}{
+		(*NullTime)(&m.MeasuredAt),
+		m.Weight,
+		m.BodyFat,
+		m.Waist,
+		m.Chest,
+		m.Arms,
+		m.Thighs,
+		m.RestingHeartRate,
+		m.Notes,
+		(*NullTime)(&m.UpdatedAt),
+		m.ID,
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+func deleteBodyMeasurement(ctx context.Context, tx *Tx, id uint) error {
+	m, err := findBodyMeasurementByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if m.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this body measurement.")
+	}
+
+	query := `
+	DELETE FROM body_measurement WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, m.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// syncProfileWeight sets the weight of the user's profile, if any, to the
+// most recent measured weight, or zero when no weight has been measured.
+func syncProfileWeight(ctx context.Context, tx *Tx, userID uint) error {
+	weight, err := findLatestWeight(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE profile SET weight = ?, updated_at = ?, version = version + 1
+	WHERE user_id = ? AND weight <> ?
+	`
+	if _, err := tx.ExecContext(ctx, query, weight, (*NullTime)(&tx.now), userID, weight); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findLatestWeight returns the most recent measured weight of the user, or
+// zero when no weight has been measured.
+func findLatestWeight(ctx context.Context, tx *Tx, userID uint) (float64, error) {
+	var weight float64
+	query := `
+	SELECT weight FROM body_measurement
+	WHERE user_id = ? AND weight > 0
+	ORDER BY measured_at DESC, id DESC LIMIT 1
+	`
+	if err := tx.QueryRowxContext(ctx, query, userID).Scan(&weight); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	return weight, nil
+}
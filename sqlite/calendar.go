@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.CalendarFeedService = (*CalendarFeedService)(nil)
+
+type CalendarFeedService struct {
+	db *DB
+}
+
+func NewCalendarFeedService(db *DB) *CalendarFeedService {
+	return &CalendarFeedService{db: db}
+}
+
+func (s *CalendarFeedService) FindCalendarFeedByToken(ctx context.Context, token string) (*fwt.CalendarFeed, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findCalendarFeedByToken(ctx, tx, token)
+}
+
+func (s *CalendarFeedService) RotateCalendarFeed(ctx context.Context) (*fwt.CalendarFeed, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	feed, err := rotateCalendarFeed(ctx, tx)
+	if err != nil {
+		return feed, err
+	} else if err := tx.Commit(); err != nil {
+		return feed, err
+	}
+
+	return feed, nil
+}
+
+func findCalendarFeedByToken(ctx context.Context, tx *Tx, token string) (*fwt.CalendarFeed, error) {
+	query := `
+	SELECT id, user_id, token_hash, created_at FROM calendar_feed WHERE token_hash = ?
+	`
+	var feed fwt.CalendarFeed
+	if err := tx.QueryRowxContext(ctx, query, fwt.HashCalendarFeedToken(token)).Scan(
+		&feed.ID,
+		&feed.UserID,
+		&feed.TokenHash,
+		(*NullTime)(&feed.CreatedAt),
+	); err == sql.ErrNoRows {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "Calendar feed not found.")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// rotateCalendarFeed replaces the feed of the current user, so that the
+// previous token no longer finds it.
+func rotateCalendarFeed(ctx context.Context, tx *Tx) (*fwt.CalendarFeed, error) {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to rotate a calendar feed.")
+	}
+
+	token, hash, err := fwt.NewCalendarFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	feed := &fwt.CalendarFeed{UserID: userID, Token: token, TokenHash: hash, CreatedAt: tx.now}
+
+	query := `
+	DELETE FROM calendar_feed WHERE user_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, feed.UserID); err != nil {
+		return nil, err
+	}
+
+	query = `
+	INSERT INTO calendar_feed (user_id, token_hash, created_at)
+	VALUES (?, ?, ?) RETURNING id
+	`
+	if err := tx.QueryRowxContext(ctx, query, feed.UserID, feed.TokenHash, (*NullTime)(&feed.CreatedAt)).Scan(&feed.ID); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
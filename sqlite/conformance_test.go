@@ -0,0 +1,45 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maliByatzes/fwt/fwttest"
+	"github.com/maliByatzes/fwt/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	fwttest.TestServices(t, func(tb testing.TB) *fwttest.Services {
+		db := MustOpenDB(tb)
+		tb.Cleanup(func() { MustCloseDB(tb, db) })
+
+		return &fwttest.Services{
+			UserService:            sqlite.NewUserService(db),
+			ProfileService:         sqlite.NewProfileService(db),
+			WorkoutService:         sqlite.NewWorkoutService(db),
+			ExerciseService:        sqlite.NewExerciseService(db),
+			WorkoutExerciseService: sqlite.NewWorkoutExerciseService(db),
+			WEStatusService:        sqlite.NewWEStatusService(db),
+			BodyMeasurementService: sqlite.NewBodyMeasurementService(db),
+			GoalService:            sqlite.NewGoalService(db),
+			CalendarFeedService:    sqlite.NewCalendarFeedService(db),
+			IdempotencyService:     sqlite.NewIdempotencyService(db),
+			TxRunner:               db,
+			SetNow:                 func(now func() time.Time) { db.Now = now },
+			CountRows:              func(table string) (int, error) { return countRows(db, table) },
+		}
+	})
+}
+
+// countRows returns the number of rows in table.
+func countRows(db *sqlite.DB, table string) (n int, err error) {
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(context.Background(), &n, `SELECT COUNT(*) FROM "`+table+`"`)
+	return n, err
+}
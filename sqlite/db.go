@@ -0,0 +1,252 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/maliByatzes/fwt"
+	"github.com/maliByatzes/fwt/metrics"
+	_ "modernc.org/sqlite"
+)
+
+// pragmas are applied to every connection. Foreign keys are off by default
+// in SQLite and must be enabled explicitly.
+var pragmas = []string{
+	"foreign_keys(1)",
+	"busy_timeout(5000)",
+	"journal_mode(WAL)",
+}
+
+type DB struct {
+	DB     *sqlx.DB
+	ctx    context.Context
+	cancel func()
+	DSN    string
+	Now    func() time.Time
+	Logger *slog.Logger
+
+	unregisterStats func()
+}
+
+// NewDB returns a DB for the database file at dsn, e.g. "fwt.db" or
+// "file:fwt.db?mode=rwc". Use ":memory:" for a throwaway database.
+func NewDB(dsn string) *DB {
+	db := &DB{
+		DSN:    dsn,
+		Now:    time.Now,
+		Logger: slog.Default(),
+	}
+	db.ctx, db.cancel = context.WithCancel(context.Background())
+	return db
+}
+
+func (db *DB) Open() (err error) {
+	if db.DSN == "" {
+		return fmt.Errorf("dsn required.")
+	}
+
+	db.DB, err = sqlx.Open("sqlite", withPragmas(db.DSN))
+	if err != nil {
+		return err
+	}
+
+	// SQLite allows a single writer. Funnelling everything through one
+	// connection avoids SQLITE_BUSY errors and keeps ":memory:" databases
+	// from being split across connections.
+	db.DB.SetMaxOpenConns(1)
+	db.DB.SetConnMaxLifetime(0)
+
+	if err = db.DB.Ping(); err != nil {
+		return err
+	}
+
+	db.unregisterStats = metrics.RegisterDBStats(db.DB.DB, "sqlite")
+
+	db.Logger.Info("🚀 Opened sqlite database successfully", "dsn", db.DSN)
+
+	return nil
+}
+
+func (db *DB) Close() error {
+	db.cancel()
+	if db.unregisterStats != nil {
+		db.unregisterStats()
+	}
+	if db.DB != nil {
+		return db.DB.Close()
+	}
+	return nil
+}
+
+// Ping verifies that the database is reachable.
+func (db *DB) Ping(ctx context.Context) error {
+	if db.DB == nil {
+		return fmt.Errorf("database is not open")
+	}
+	return db.DB.PingContext(ctx)
+}
+
+// SchemaVersion returns the migration version recorded by golang-migrate
+// and whether the last migration failed part way through.
+func (db *DB) SchemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	if db.DB == nil {
+		return 0, false, fmt.Errorf("database is not open")
+	}
+	err = db.DB.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+var _ fwt.TxRunner = (*DB)(nil)
+
+type txContextKey struct{}
+
+// BeginTx starts a transaction, or joins the one carried by ctx when called
+// from within RunInTx. Committing or rolling back a joined transaction is a
+// no-op; the outcome is decided by RunInTx.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if outer, ok := ctx.Value(txContextKey{}).(*Tx); ok && outer.db == db {
+		return &Tx{Tx: outer.Tx, db: db, now: outer.now, joined: true}, nil
+	}
+
+	tx, err := db.DB.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{
+		Tx:  tx,
+		db:  db,
+		now: db.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// RunInTx implements fwt.TxRunner.
+func (db *DB) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.RunInTxOptions(ctx, fwt.TxOptions{}, fn)
+}
+
+// RunInTxOptions implements fwt.TxRunner. SQLite transactions are always
+// serializable and never fail with a serialization error, so only ReadOnly
+// is used.
+func (db *DB) RunInTxOptions(ctx context.Context, opts fwt.TxOptions, fn func(ctx context.Context) error) error {
+	if outer, ok := ctx.Value(txContextKey{}).(*Tx); ok && outer.db == db {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type Tx struct {
+	*sqlx.Tx
+	db     *DB
+	now    time.Time
+	joined bool
+}
+
+func (tx *Tx) Commit() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Commit()
+}
+
+func (tx *Tx) Rollback() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Rollback()
+}
+
+func withPragmas(dsn string) string {
+	var b strings.Builder
+	b.WriteString(dsn)
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	for _, p := range pragmas {
+		b.WriteString(sep + "_pragma=" + p)
+		sep = "&"
+	}
+	return b.String()
+}
+
+func formatLimitOffset(limit, offset int) string {
+	if limit > 0 && offset > 0 {
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	} else if limit > 0 {
+		return fmt.Sprintf(" LIMIT %d", limit)
+	} else if offset > 0 {
+		return fmt.Sprintf(" LIMIT -1 OFFSET %d", offset)
+	}
+	return ""
+}
+
+func formatWhereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+// isUniqueViolation reports whether err was caused by the unique index on
+// the given table columns, e.g. "user.username".
+func isUniqueViolation(err error, columns string) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: "+columns)
+}
+
+// NullTime stores times as RFC 3339 text in UTC, which SQLite compares
+// correctly as strings. The zero time is stored as NULL.
+type NullTime time.Time
+
+func (n *NullTime) Scan(value interface{}) error {
+	if value == nil {
+		*(*time.Time)(n) = time.Time{}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		*(*time.Time)(n) = v
+	case []byte:
+		parsedTime, err := time.Parse(time.RFC3339, string(v))
+		if err != nil {
+			return err
+		}
+		*(*time.Time)(n) = parsedTime
+	case string:
+		parsedTime, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+		*(*time.Time)(n) = parsedTime
+	default:
+		return fmt.Errorf("NullTime: cannot scan type %T into NullTime", value)
+	}
+
+	return nil
+}
+
+func (n *NullTime) Value() (driver.Value, error) {
+	if n == nil || (*time.Time)(n).IsZero() {
+		return nil, nil
+	}
+	return (*time.Time)(n).UTC().Format(time.RFC3339), nil
+}
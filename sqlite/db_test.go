@@ -0,0 +1,86 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/maliByatzes/fwt/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	ctx := context.Background()
+	require.NoError(t, db.Ping(ctx))
+	require.NoError(t, db.CheckSchema(ctx))
+
+	latest, err := sqlite.LatestSchemaVersion()
+	require.NoError(t, err)
+
+	version, dirty, err := db.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, latest, version)
+}
+
+func TestDB_Migrate(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	ctx := context.Background()
+	require.Error(t, db.MigrateDown(ctx, 0))
+	require.NoError(t, db.MigrateDown(ctx, 1))
+
+	status, err := db.MigrationStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, status.Latest-1, status.Version)
+
+	require.NoError(t, db.MigrateDownAll(ctx))
+
+	status, err = db.MigrationStatus(ctx)
+	require.NoError(t, err)
+	require.Zero(t, status.Version)
+	require.True(t, status.Pending())
+	require.Error(t, db.CheckSchema(ctx))
+
+	require.NoError(t, db.MigrateUp(ctx))
+	require.NoError(t, db.CheckSchema(ctx))
+}
+
+func TestDB_File(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "fwt.db")
+
+	db := sqlite.NewDB(dsn)
+	require.NoError(t, db.Open())
+	require.NoError(t, db.MigrateUp(context.Background()))
+	require.NoError(t, db.Close())
+
+	db = sqlite.NewDB(dsn)
+	require.NoError(t, db.Open())
+	defer db.Close()
+	require.NoError(t, db.CheckSchema(context.Background()))
+}
+
+// MustOpenDB returns a migrated in-memory database.
+func MustOpenDB(tb testing.TB) *sqlite.DB {
+	tb.Helper()
+
+	db := sqlite.NewDB(":memory:")
+	if err := db.Open(); err != nil {
+		tb.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.MigrateUp(context.Background()); err != nil {
+		tb.Fatalf("failed to run migrations: %v", err)
+	}
+	return db
+}
+
+func MustCloseDB(tb testing.TB, db *sqlite.DB) {
+	tb.Helper()
+	if err := db.Close(); err != nil {
+		tb.Fatal(err)
+	}
+}
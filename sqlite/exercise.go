@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.ExerciseService = (*ExerciseService)(nil)
+
+type ExerciseService struct {
+	db *DB
+}
+
+func NewExerciseService(db *DB) *ExerciseService {
+	return &ExerciseService{db: db}
+}
+
+func (s *ExerciseService) FindExerciseByID(ctx context.Context, id uint) (*fwt.Exercise, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findExerciseByID(ctx, tx, id)
+}
+
+func (s *ExerciseService) FindExerciseByName(ctx context.Context, name string) (*fwt.Exercise, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findExerciseByName(ctx, tx, name)
+}
+
+func (s *ExerciseService) FindExercises(ctx context.Context, filter fwt.ExerciseFilter) ([]*fwt.Exercise, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findExercises(ctx, tx, filter)
+}
+
+func (s *ExerciseService) CreateExercise(ctx context.Context, exercise *fwt.Exercise) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createExercise(ctx, tx, exercise); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createExercise(ctx context.Context, tx *Tx, exercise *fwt.Exercise) error {
+	exercise.CreatedAt = tx.now
+	exercise.UpdatedAt = exercise.CreatedAt
+
+	if err := exercise.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO exercise (name, description, created_at, updated_at)
+	VALUES (?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		exercise.Name,
+		exercise.Description,
+		(*NullTime)(&exercise.CreatedAt),
+		(*NullTime)(&exercise.UpdatedAt),
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&exercise.ID)
+}
+
+func findExerciseByID(ctx context.Context, tx *Tx, id uint) (*fwt.Exercise, error) {
+	a, _, err := findExercises(ctx, tx, fwt.ExerciseFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Exercise not found."}
+	}
+	return a[0], nil
+}
+
+func findExerciseByName(ctx context.Context, tx *Tx, name string) (*fwt.Exercise, error) {
+	a, _, err := findExercises(ctx, tx, fwt.ExerciseFilter{Name: &name})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Exercise not found."}
+	}
+	return a[0], nil
+}
+
+func findExercises(ctx context.Context, tx *Tx, filter fwt.ExerciseFilter) (_ []*fwt.Exercise, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.Name; v != nil {
+		where, args = append(where, "name = ?"), append(args, *v)
+	}
+
+	query := `
+	SELECT id, name, description, created_at, updated_at, COUNT(*) OVER()
+	FROM exercise` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	exercises := make([]*fwt.Exercise, 0)
+	for rows.Next() {
+		var exercise fwt.Exercise
+		if err := rows.Scan(
+			&exercise.ID,
+			&exercise.Name,
+			&exercise.Description,
+			(*NullTime)(&exercise.CreatedAt),
+			(*NullTime)(&exercise.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		exercises = append(exercises, &exercise)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return exercises, n, nil
+}
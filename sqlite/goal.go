@@ -0,0 +1,369 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.GoalService = (*GoalService)(nil)
+
+type GoalService struct {
+	db *DB
+}
+
+func NewGoalService(db *DB) *GoalService {
+	return &GoalService{db: db}
+}
+
+func (s *GoalService) FindGoalByID(ctx context.Context, id uint) (*fwt.Goal, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findGoalByID(ctx, tx, id)
+}
+
+func (s *GoalService) FindGoals(ctx context.Context, filter fwt.GoalFilter) ([]*fwt.Goal, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findGoals(ctx, tx, filter)
+}
+
+func (s *GoalService) CreateGoal(ctx context.Context, goal *fwt.Goal) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createGoal(ctx, tx, goal); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *GoalService) ArchiveGoal(ctx context.Context, id uint) (*fwt.Goal, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	goal, err := archiveGoal(ctx, tx, id)
+	if err != nil {
+		return goal, err
+	} else if err := tx.Commit(); err != nil {
+		return goal, err
+	}
+
+	return goal, nil
+}
+
+func (s *GoalService) FindGoalProgress(ctx context.Context, id uint) (*fwt.GoalProgress, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	goal, err := findGoalByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := findUserLocation(ctx, tx, goal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := tx.now.In(loc)
+
+	start, end := goal.SampleRange(now)
+	samples, err := findGoalSamples(ctx, tx, goal, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return goal.Evaluate(samples, now), nil
+}
+
+func createGoal(ctx context.Context, tx *Tx, goal *fwt.Goal) error {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to create a goal.")
+	}
+	goal.UserID = userID
+
+	goal.ArchivedAt = time.Time{}
+	goal.CreatedAt = tx.now
+	goal.UpdatedAt = goal.CreatedAt
+
+	if err := goal.Validate(); err != nil {
+		return err
+	}
+
+	if goal.Type == fwt.GoalTypeLift {
+		if _, err := findExerciseByID(ctx, tx, goal.ExerciseID); err != nil {
+			return err
+		}
+	} else {
+		goal.ExerciseID = 0
+	}
+
+	// Record where the user starts from. Periodic goals start afresh every
+	// period.
+	goal.Start = 0
+	switch goal.Type {
+	case fwt.GoalTypeBodyWeight:
+		weight, err := findStartingWeight(ctx, tx, goal.UserID)
+		if err != nil {
+			return err
+		}
+		goal.Start = weight
+	case fwt.GoalTypeLift:
+		samples, err := findGoalSamples(ctx, tx, goal, time.Time{}, time.Time{})
+		if err != nil {
+			return err
+		}
+		for _, s := range samples {
+			goal.Start = max(goal.Start, s.Value)
+		}
+	}
+	if err := goal.ValidateStart(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO goal (user_id, type, exercise_id, target, start, target_date, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`
+	var exerciseID sql.NullInt64
+	if goal.ExerciseID != 0 {
+		exerciseID = sql.NullInt64{Int64: int64(goal.ExerciseID), Valid: true}
+	}
+	args := []interface{}{
+		goal.UserID,
+		goal.Type,
+		exerciseID,
+		goal.Target,
+		goal.Start,
+		(*NullTime)(&goal.TargetDate),
+		(*NullTime)(&goal.CreatedAt),
+		(*NullTime)(&goal.UpdatedAt),
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&goal.ID)
+}
+
+// findStartingWeight returns the latest measured weight of the user, falling
+// back to the weight on their profile, or zero when neither is known.
+func findStartingWeight(ctx context.Context, tx *Tx, userID uint) (float64, error) {
+	weight, err := findLatestWeight(ctx, tx, userID)
+	if err != nil || weight > 0 {
+		return weight, err
+	}
+
+	profile, err := findProfileByUserID(ctx, tx, userID)
+	if fwt.ErrorCode(err) == fwt.ENOTFOUND {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return profile.Weight, nil
+}
+
+func findGoalByID(ctx context.Context, tx *Tx, id uint) (*fwt.Goal, error) {
+	a, _, err := findGoals(ctx, tx, fwt.GoalFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Goal not found."}
+	}
+	return a[0], nil
+}
+
+func findGoals(ctx context.Context, tx *Tx, filter fwt.GoalFilter) (_ []*fwt.Goal, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+	if v := filter.Type; v != nil {
+		where, args = append(where, "type = ?"), append(args, *v)
+	}
+	if v := filter.Archived; v != nil {
+		if *v {
+			where = append(where, "archived_at IS NOT NULL")
+		} else {
+			where = append(where, "archived_at IS NULL")
+		}
+	}
+
+	query := `
+	SELECT id, user_id, type, exercise_id, target, start, target_date, archived_at, created_at, updated_at, COUNT(*) OVER()
+	FROM goal` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	goals := make([]*fwt.Goal, 0)
+	for rows.Next() {
+		var goal fwt.Goal
+		var exerciseID sql.NullInt64
+		if err := rows.Scan(
+			&goal.ID,
+			&goal.UserID,
+			&goal.Type,
+			&exerciseID,
+			&goal.Target,
+			&goal.Start,
+			(*NullTime)(&goal.TargetDate),
+			(*NullTime)(&goal.ArchivedAt),
+			(*NullTime)(&goal.CreatedAt),
+			(*NullTime)(&goal.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+		goal.ExerciseID = uint(exerciseID.Int64)
+
+		goals = append(goals, &goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return goals, n, nil
+}
+
+func archiveGoal(ctx context.Context, tx *Tx, id uint) (*fwt.Goal, error) {
+	goal, err := findGoalByID(ctx, tx, id)
+	if err != nil {
+		return goal, err
+	} else if goal.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to archive this goal.")
+	} else if !goal.ArchivedAt.IsZero() {
+		return goal, fwt.Errorf(fwt.ECONFLICT, "Goal is already archived.")
+	}
+
+	goal.ArchivedAt = tx.now
+	goal.UpdatedAt = tx.now
+
+	query := `
+	UPDATE goal SET archived_at = ?, updated_at = ? WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, (*NullTime)(&goal.ArchivedAt), (*NullTime)(&goal.UpdatedAt), goal.ID); err != nil {
+		return goal, err
+	}
+
+	return goal, nil
+}
+
+// findGoalSamples returns the observations towards goal taken in the
+// half-open interval from start to end, ordered by time. A zero start or
+// end leaves that side of the interval open. Workouts count from when they
+// were finished. Workouts logged without times count on their scheduled
+// date, which is compared with the dates of start and end in their own
+// location.
+func findGoalSamples(ctx context.Context, tx *Tx, goal *fwt.Goal, start, end time.Time) ([]fwt.GoalSample, error) {
+	if goal.Type == fwt.GoalTypeBodyWeight {
+		filter := fwt.BodyMeasurementFilter{UserID: &goal.UserID}
+		if !start.IsZero() {
+			filter.From = &start
+		}
+		ms, _, err := findBodyMeasurements(ctx, tx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		samples := make([]fwt.GoalSample, 0, len(ms))
+		for _, m := range ms {
+			if m.Weight > 0 && (end.IsZero() || m.MeasuredAt.Before(end)) {
+				samples = append(samples, fwt.GoalSample{At: m.MeasuredAt, Value: m.Weight})
+			}
+		}
+		return samples, nil
+	}
+
+	at := "COALESCE(w.finished_at, w.scheduled_date)"
+	where := []string{"w.user_id = ?", "w.status IN (?, ?)"}
+	args := []interface{}{goal.UserID, fwt.WorkoutStatusCompleted, fwt.WorkoutStatusPartiallyCompleted}
+
+	finished, dated := []string{"w.finished_at IS NOT NULL"}, []string{"w.finished_at IS NULL"}
+	var datedArgs []interface{}
+	if !start.IsZero() {
+		startDate := fwt.Date(start)
+		finished, args = append(finished, "w.finished_at >= ?"), append(args, (*NullTime)(&start))
+		dated, datedArgs = append(dated, "w.scheduled_date >= ?"), append(datedArgs, (*NullTime)(&startDate))
+	}
+	if !end.IsZero() {
+		endDate := fwt.Date(end)
+		finished, args = append(finished, "w.finished_at < ?"), append(args, (*NullTime)(&end))
+		dated, datedArgs = append(dated, "w.scheduled_date < ?"), append(datedArgs, (*NullTime)(&endDate))
+	}
+	if len(finished) > 1 {
+		where = append(where, "(("+strings.Join(finished, " AND ")+") OR ("+strings.Join(dated, " AND ")+"))")
+	}
+	args = append(args, datedArgs...)
+
+	var query string
+	if goal.Type == fwt.GoalTypeFrequency {
+		query = `SELECT ` + at + `, 0, 0 FROM workout AS w` + formatWhereClause(where) + ` ORDER BY 1 ASC`
+	} else {
+		if goal.Type == fwt.GoalTypeLift {
+			where, args = append(where, "we.exercise_id = ?"), append(args, goal.ExerciseID)
+		}
+		query = `
+		SELECT ` + at + `, s.reps, s.load
+		FROM exercise_set AS s
+		JOIN workout_exercise AS we ON we.id = s.workout_exercise_id
+		JOIN workout AS w ON w.id = we.workout_id` + formatWhereClause(where) + ` ORDER BY 1 ASC, s.id ASC`
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := make([]fwt.GoalSample, 0)
+	for rows.Next() {
+		var s fwt.GoalSample
+		var reps uint
+		var load float64
+		if err := rows.Scan((*NullTime)(&s.At), &reps, &load); err != nil {
+			return nil, err
+		}
+
+		switch goal.Type {
+		case fwt.GoalTypeFrequency:
+			s.Value = 1
+		case fwt.GoalTypeLift:
+			s.Value = fwt.EstimateOneRepMax(reps, load)
+		case fwt.GoalTypeVolume:
+			s.Value = float64(reps) * load
+		}
+		samples = append(samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,225 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.IdempotencyService = (*IdempotencyService)(nil)
+
+type IdempotencyService struct {
+	db *DB
+}
+
+func NewIdempotencyService(db *DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+func (s *IdempotencyService) FindIdempotencyKey(ctx context.Context, userID uint, key string) (*fwt.IdempotencyKey, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findIdempotencyKey(ctx, tx, userID, key)
+}
+
+func (s *IdempotencyService) CreateIdempotencyKey(ctx context.Context, key *fwt.IdempotencyKey) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createIdempotencyKey(ctx, tx, key); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *IdempotencyService) UpdateIdempotencyKey(ctx context.Context, id uint, upd fwt.IdempotencyKeyUpdate) (*fwt.IdempotencyKey, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	key, err := updateIdempotencyKey(ctx, tx, id, upd)
+	if err != nil {
+		return key, err
+	} else if err := tx.Commit(); err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+func (s *IdempotencyService) DeleteIdempotencyKey(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteIdempotencyKey(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createIdempotencyKey(ctx context.Context, tx *Tx, key *fwt.IdempotencyKey) error {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to create an idempotency key.")
+	}
+	key.UserID = userID
+	key.CreatedAt = tx.now
+
+	if err := key.Validate(); err != nil {
+		return err
+	}
+
+	// An expired key may be reused, so clear it out before inserting.
+	query := `
+	DELETE FROM idempotency_key WHERE user_id = ? AND key = ? AND expires_at <= ?
+	`
+	if _, err := tx.ExecContext(ctx, query, key.UserID, key.Key, (*NullTime)(&tx.now)); err != nil {
+		return err
+	}
+
+	query = `
+	INSERT INTO idempotency_key (user_id, key, request_hash, created_at, expires_at)
+	VALUES (?, ?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		key.UserID,
+		key.Key,
+		key.RequestHash,
+		(*NullTime)(&key.CreatedAt),
+		(*NullTime)(&key.ExpiresAt),
+	}
+
+	if err := tx.QueryRowxContext(ctx, query, args...).Scan(&key.ID); err != nil {
+		if isUniqueViolation(err, "idempotency_key.user_id, idempotency_key.key") {
+			return fwt.Errorf(fwt.ECONFLICT, "This idempotency key is already in use.")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func findIdempotencyKey(ctx context.Context, tx *Tx, userID uint, key string) (*fwt.IdempotencyKey, error) {
+	query := `
+	SELECT id, user_id, key, request_hash, COALESCE(response_status, 0), COALESCE(response_body, X''), COALESCE(response_headers, ''), created_at, expires_at
+	FROM idempotency_key WHERE user_id = ? AND key = ? AND expires_at > ?
+	`
+	return scanIdempotencyKey(tx.QueryRowxContext(ctx, query, userID, key, (*NullTime)(&tx.now)))
+}
+
+func findIdempotencyKeyByID(ctx context.Context, tx *Tx, id uint) (*fwt.IdempotencyKey, error) {
+	query := `
+	SELECT id, user_id, key, request_hash, COALESCE(response_status, 0), COALESCE(response_body, X''), COALESCE(response_headers, ''), created_at, expires_at
+	FROM idempotency_key WHERE id = ?
+	`
+	return scanIdempotencyKey(tx.QueryRowxContext(ctx, query, id))
+}
+
+func scanIdempotencyKey(row *sqlx.Row) (*fwt.IdempotencyKey, error) {
+	var key fwt.IdempotencyKey
+	var headers string
+	if err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Key,
+		&key.RequestHash,
+		&key.ResponseStatus,
+		&key.ResponseBody,
+		&headers,
+		(*NullTime)(&key.CreatedAt),
+		(*NullTime)(&key.ExpiresAt),
+	); err == sql.ErrNoRows {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "Idempotency key not found.")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if headers != "" {
+		if err := json.Unmarshal([]byte(headers), &key.ResponseHeaders); err != nil {
+			return nil, err
+		}
+	}
+
+	return &key, nil
+}
+
+func updateIdempotencyKey(ctx context.Context, tx *Tx, id uint, upd fwt.IdempotencyKeyUpdate) (*fwt.IdempotencyKey, error) {
+	key, err := findIdempotencyKeyByID(ctx, tx, id)
+	if err != nil {
+		return key, err
+	} else if key.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this idempotency key.")
+	}
+
+	if v := upd.ResponseStatus; v != nil {
+		key.ResponseStatus = *v
+	}
+	if v := upd.ResponseBody; v != nil {
+		key.ResponseBody = v
+	}
+	if v := upd.ResponseHeaders; v != nil {
+		key.ResponseHeaders = v
+	}
+	if v := upd.ExpiresAt; v != nil {
+		key.ExpiresAt = *v
+	}
+
+	headers, err := json.Marshal(key.ResponseHeaders)
+	if err != nil {
+		return key, err
+	}
+
+	query := `
+	UPDATE idempotency_key SET response_status = ?, response_body = ?, response_headers = ?, expires_at = ?
+	WHERE id = ? AND user_id = ?
+	`
+	args := []interface{}{
+		key.ResponseStatus,
+		key.ResponseBody,
+		string(headers),
+		(*NullTime)(&key.ExpiresAt),
+		key.ID,
+		key.UserID,
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+func deleteIdempotencyKey(ctx context.Context, tx *Tx, id uint) error {
+	key, err := findIdempotencyKeyByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if key.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this idempotency key.")
+	}
+
+	query := `
+	DELETE FROM idempotency_key WHERE id = ? AND user_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, key.ID, key.UserID); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/maliByatzes/fwt"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migrations returns the embedded migration files.
+func Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationsFS, "migrations")
+	return sub
+}
+
+// LatestSchemaVersion returns the highest migration version embedded in the
+// binary, which is the version the code expects the database to be at.
+func LatestSchemaVersion() (uint, error) {
+	entries, err := fs.ReadDir(Migrations(), ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(v))
+	}
+	return latest, nil
+}
+
+// migrate runs fn with a migrate instance using the open database. The
+// instance is deliberately not closed: closing the sqlite driver closes the
+// *sql.DB it was given.
+func (db *DB) migrate(ctx context.Context, fn func(m *migrate.Migrate) error) error {
+	if db.DB == nil {
+		return fmt.Errorf("database is not open")
+	}
+
+	src, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	driver, err := migratesqlite.WithInstance(db.DB.DB, &migratesqlite.Config{})
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "sqlite", driver)
+	if err != nil {
+		return err
+	}
+	m.Log = migrateLogger{db: db}
+
+	if err := fn(m); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// MigrateUp applies all pending migrations.
+func (db *DB) MigrateUp(ctx context.Context) error {
+	return db.migrate(ctx, func(m *migrate.Migrate) error {
+		return m.Up()
+	})
+}
+
+// MigrateDown rolls back the given number of migrations, which must be
+// positive. Use MigrateDownAll to roll back every migration.
+func (db *DB) MigrateDown(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("invalid number of steps: %d", steps)
+	}
+	return db.migrate(ctx, func(m *migrate.Migrate) error {
+		return m.Steps(-steps)
+	})
+}
+
+// MigrateDownAll rolls back every migration, dropping the whole schema.
+func (db *DB) MigrateDownAll(ctx context.Context) error {
+	return db.migrate(ctx, func(m *migrate.Migrate) error {
+		return m.Down()
+	})
+}
+
+// MigrateForce sets the recorded version without running any migration and
+// clears the dirty flag. It is used to recover from a failed migration.
+func (db *DB) MigrateForce(ctx context.Context, version int) error {
+	return db.migrate(ctx, func(m *migrate.Migrate) error {
+		return m.Force(version)
+	})
+}
+
+func (db *DB) MigrationStatus(ctx context.Context) (fwt.MigrationStatus, error) {
+	latest, err := LatestSchemaVersion()
+	if err != nil {
+		return fwt.MigrationStatus{}, err
+	}
+
+	var status fwt.MigrationStatus
+	err = db.migrate(ctx, func(m *migrate.Migrate) error {
+		v, dirty, err := m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			return nil
+		}
+		status.Version, status.Dirty = v, dirty
+		return err
+	})
+	status.Latest = latest
+	return status, err
+}
+
+// CheckSchema returns an error if the database has not been migrated to the
+// version embedded in the binary or a previous migration failed.
+func (db *DB) CheckSchema(ctx context.Context) error {
+	status, err := db.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status.Dirty:
+		return fmt.Errorf("schema version %d is dirty; fix it and run `fwt migrate force`", status.Version)
+	case status.Pending():
+		return fmt.Errorf("schema version %d is behind %d; run `fwt migrate up`", status.Version, status.Latest)
+	case status.Version > status.Latest:
+		return fmt.Errorf("schema version %d is newer than this binary supports (%d)", status.Version, status.Latest)
+	}
+	return nil
+}
+
+type migrateLogger struct {
+	db *DB
+}
+
+func (l migrateLogger) Printf(format string, v ...interface{}) {
+	if l.db.Logger == nil {
+		return
+	}
+	l.db.Logger.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l migrateLogger) Verbose() bool {
+	return false
+}
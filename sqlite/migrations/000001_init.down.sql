@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "idempotency_key";
+DROP TABLE IF EXISTS "workout_exercise_status";
+DROP TABLE IF EXISTS "workout_exercise";
+DROP TABLE IF EXISTS "exercise";
+DROP TABLE IF EXISTS "workout";
+DROP TABLE IF EXISTS "profile";
+DROP TABLE IF EXISTS "user";
@@ -0,0 +1,76 @@
+CREATE TABLE IF NOT EXISTS "user" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "username" TEXT NOT NULL,
+    "email" TEXT NOT NULL,
+    "hashed_password" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX "user_username_key" ON "user"("username");
+
+CREATE UNIQUE INDEX "user_email_key" ON "user"("email");
+
+CREATE TABLE IF NOT EXISTS "profile" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "user_id" INTEGER NOT NULL REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "first_name" TEXT NOT NULL DEFAULT '',
+    "last_name" TEXT NOT NULL DEFAULT '',
+    "date_of_birth" TEXT,
+    "gender" TEXT NOT NULL DEFAULT '',
+    "height" REAL NOT NULL DEFAULT 0,
+    "weight" REAL NOT NULL DEFAULT 0,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "workout" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "user_id" INTEGER NOT NULL REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "name" TEXT NOT NULL,
+    "scheduled_date" TEXT NOT NULL,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "exercise" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" TEXT NOT NULL,
+    "description" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    "updated_at" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS "workout_exercise" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "workout_id" INTEGER NOT NULL REFERENCES "workout"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "exercise_id" INTEGER NOT NULL REFERENCES "exercise"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "order" INTEGER NOT NULL,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "workout_exercise_status" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "workout_exercise_id" INTEGER NOT NULL REFERENCES "workout_exercise"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "status" TEXT NOT NULL CHECK ("status" IN ('pending', 'completed')),
+    "comments" TEXT NOT NULL DEFAULT '',
+    "completed_at" TEXT,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "idempotency_key" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "user_id" INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "key" TEXT NOT NULL,
+    "request_hash" TEXT NOT NULL,
+    "response_status" INTEGER,
+    "response_body" BLOB,
+    "created_at" TEXT NOT NULL,
+    "expires_at" TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX "idempotency_key_user_id_key_key" ON "idempotency_key"("user_id", "key");
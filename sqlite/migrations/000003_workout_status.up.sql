@@ -0,0 +1,17 @@
+ALTER TABLE "workout" ADD COLUMN "status" TEXT NOT NULL DEFAULT 'planned' CHECK ("status" IN ('planned', 'in_progress', 'completed', 'partially_completed', 'skipped'));
+ALTER TABLE "workout" ADD COLUMN "started_at" TEXT;
+ALTER TABLE "workout" ADD COLUMN "finished_at" TEXT;
+
+CREATE TABLE "workout_exercise_status_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "workout_exercise_id" INTEGER NOT NULL REFERENCES "workout_exercise"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "status" TEXT NOT NULL CHECK ("status" IN ('pending', 'completed', 'partially_completed', 'skipped')),
+    "comments" TEXT NOT NULL DEFAULT '',
+    "completed_at" TEXT,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+INSERT INTO "workout_exercise_status_new" SELECT * FROM "workout_exercise_status";
+DROP TABLE "workout_exercise_status";
+ALTER TABLE "workout_exercise_status_new" RENAME TO "workout_exercise_status";
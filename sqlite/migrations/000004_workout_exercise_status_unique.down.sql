@@ -0,0 +1,13 @@
+CREATE TABLE "workout_exercise_status_old" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "workout_exercise_id" INTEGER NOT NULL REFERENCES "workout_exercise"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "status" TEXT NOT NULL CHECK ("status" IN ('pending', 'completed', 'partially_completed', 'skipped')),
+    "comments" TEXT NOT NULL DEFAULT '',
+    "completed_at" TEXT,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+INSERT INTO "workout_exercise_status_old" SELECT * FROM "workout_exercise_status";
+DROP TABLE "workout_exercise_status";
+ALTER TABLE "workout_exercise_status_old" RENAME TO "workout_exercise_status";
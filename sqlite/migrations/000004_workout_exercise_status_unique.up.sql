@@ -0,0 +1,22 @@
+CREATE TABLE "workout_exercise_status_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "workout_exercise_id" INTEGER NOT NULL REFERENCES "workout_exercise"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "status" TEXT NOT NULL CHECK ("status" IN ('pending', 'completed', 'partially_completed', 'skipped')),
+    "comments" TEXT NOT NULL DEFAULT '',
+    "completed_at" TEXT,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+INSERT INTO "workout_exercise_status_new"
+SELECT * FROM "workout_exercise_status"
+WHERE "id" IN (SELECT MIN("id") FROM "workout_exercise_status" GROUP BY "workout_exercise_id");
+DROP TABLE "workout_exercise_status";
+ALTER TABLE "workout_exercise_status_new" RENAME TO "workout_exercise_status";
+
+INSERT INTO "workout_exercise_status" ("workout_exercise_id", "status", "comments", "created_at", "updated_at")
+SELECT we."id", 'pending', '', strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM "workout_exercise" AS we
+WHERE NOT EXISTS (SELECT 1 FROM "workout_exercise_status" AS s WHERE s."workout_exercise_id" = we."id");
+
+CREATE UNIQUE INDEX "workout_exercise_status_workout_exercise_id_key" ON "workout_exercise_status"("workout_exercise_id");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "exercise_set_workout_exercise_id_idx";
+
+DROP TABLE IF EXISTS "exercise_set";
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS "exercise_set" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "workout_exercise_id" INTEGER NOT NULL REFERENCES "workout_exercise"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "reps" INTEGER NOT NULL CHECK ("reps" > 0),
+    "load" REAL NOT NULL DEFAULT 0 CHECK ("load" >= 0),
+    "created_at" TEXT NOT NULL
+);
+
+CREATE INDEX "exercise_set_workout_exercise_id_idx" ON "exercise_set"("workout_exercise_id");
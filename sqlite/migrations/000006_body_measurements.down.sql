@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "body_measurement_user_id_measured_at_idx";
+
+DROP TABLE IF EXISTS "body_measurement";
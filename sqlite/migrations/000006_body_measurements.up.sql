@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS "body_measurement" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "user_id" INTEGER NOT NULL REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "measured_at" TEXT NOT NULL,
+    "weight" REAL NOT NULL DEFAULT 0,
+    "body_fat" REAL NOT NULL DEFAULT 0,
+    "waist" REAL NOT NULL DEFAULT 0,
+    "chest" REAL NOT NULL DEFAULT 0,
+    "arms" REAL NOT NULL DEFAULT 0,
+    "thighs" REAL NOT NULL DEFAULT 0,
+    "resting_heart_rate" INTEGER NOT NULL DEFAULT 0,
+    "notes" TEXT NOT NULL DEFAULT '',
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+CREATE INDEX "body_measurement_user_id_measured_at_idx" ON "body_measurement"("user_id", "measured_at");
+
+INSERT INTO "body_measurement" ("user_id", "measured_at", "weight", "created_at", "updated_at")
+SELECT "user_id", "updated_at", "weight", "updated_at", "updated_at"
+FROM "profile"
+WHERE "weight" > 0;
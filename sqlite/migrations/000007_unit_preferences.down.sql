@@ -0,0 +1,3 @@
+ALTER TABLE "profile" DROP COLUMN "distance_unit";
+ALTER TABLE "profile" DROP COLUMN "length_unit";
+ALTER TABLE "profile" DROP COLUMN "weight_unit";
@@ -0,0 +1,3 @@
+ALTER TABLE "profile" ADD COLUMN "weight_unit" TEXT NOT NULL DEFAULT 'kg' CHECK ("weight_unit" IN ('kg', 'lb'));
+ALTER TABLE "profile" ADD COLUMN "length_unit" TEXT NOT NULL DEFAULT 'cm' CHECK ("length_unit" IN ('cm', 'in'));
+ALTER TABLE "profile" ADD COLUMN "distance_unit" TEXT NOT NULL DEFAULT 'km' CHECK ("distance_unit" IN ('km', 'mi'));
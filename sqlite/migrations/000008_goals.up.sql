@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "goal" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "user_id" INTEGER NOT NULL REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "type" TEXT NOT NULL CHECK ("type" IN ('body_weight', 'lift', 'frequency', 'volume')),
+    "exercise_id" INTEGER REFERENCES "exercise"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    "target" REAL NOT NULL CHECK ("target" > 0),
+    "start" REAL NOT NULL DEFAULT 0,
+    "target_date" TEXT,
+    "archived_at" TEXT,
+    "created_at" TEXT NOT NULL,
+    "updated_at" TEXT NOT NULL
+);
+
+CREATE INDEX "goal_user_id_idx" ON "goal"("user_id");
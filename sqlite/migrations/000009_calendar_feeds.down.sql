@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "calendar_feed_token_hash_key";
+DROP INDEX IF EXISTS "calendar_feed_user_id_key";
+
+DROP TABLE IF EXISTS "calendar_feed";
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS "calendar_feed" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "user_id" INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "token_hash" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX "calendar_feed_user_id_key" ON "calendar_feed"("user_id");
+CREATE UNIQUE INDEX "calendar_feed_token_hash_key" ON "calendar_feed"("token_hash");
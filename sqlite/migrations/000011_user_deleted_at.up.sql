@@ -0,0 +1,3 @@
+ALTER TABLE "user" ADD COLUMN "deleted_at" TEXT;
+
+CREATE INDEX "user_deleted_at_idx" ON "user"("deleted_at");
@@ -0,0 +1,379 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.ProfileService = (*ProfileService)(nil)
+
+type ProfileService struct {
+	db *DB
+}
+
+func NewProfileService(db *DB) *ProfileService {
+	return &ProfileService{db: db}
+}
+
+func (s *ProfileService) FindProfileByID(ctx context.Context, id uint) (*fwt.Profile, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findProfileByID(ctx, tx, id)
+}
+
+func (s *ProfileService) FindProfileByUserID(ctx context.Context, userID uint) (*fwt.Profile, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findProfileByUserID(ctx, tx, userID)
+}
+
+func (s *ProfileService) FindProfiles(ctx context.Context, filter fwt.ProfileFilter) ([]*fwt.Profile, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findProfiles(ctx, tx, filter)
+}
+
+func (s *ProfileService) CreateProfile(ctx context.Context, profile *fwt.Profile) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createProfile(ctx, tx, profile); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *ProfileService) UpdateProfile(ctx context.Context, id uint, upd fwt.ProfileUpdate) (*fwt.Profile, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	profile, err := updateProfile(ctx, tx, id, upd)
+	if err != nil {
+		return profile, err
+	} else if err := tx.Commit(); err != nil {
+		return profile, err
+	}
+
+	return profile, nil
+}
+
+func (s *ProfileService) DeleteProfile(ctx context.Context, id uint, version *uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteProfile(ctx, tx, id, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createProfile(ctx context.Context, tx *Tx, profile *fwt.Profile) error {
+	userID := fwt.UserIDFromContext(ctx)
+	if userID == 0 {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged in to create a profile.")
+	}
+	profile.UserID = userID
+
+	profile.Units = fwt.MetricUnits.Merge(profile.Units)
+	if profile.Timezone == "" {
+		profile.Timezone = fwt.DefaultTimezone
+	}
+	profile.Version = 1
+	profile.CreatedAt = tx.now
+	profile.UpdatedAt = profile.CreatedAt
+
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := findProfileByUserID(ctx, tx, profile.UserID); err == nil {
+		return &fwt.Error{Code: fwt.ECONFLICT, Message: "Profile already exists."}
+	} else if fwt.ErrorCode(err) != fwt.ENOTFOUND {
+		return err
+	}
+
+	// A profile created without a weight starts from the latest measured
+	// one; a weight given here is recorded as a new measurement instead.
+	measured := profile.Weight == 0
+	if measured {
+		weight, err := findLatestWeight(ctx, tx, profile.UserID)
+		if err != nil {
+			return err
+		}
+		profile.Weight = weight
+	}
+
+	query := `
+	INSERT INTO profile (user_id, first_name, last_name, date_of_birth, gender, height, weight, weight_unit, length_unit, distance_unit, timezone, version, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		profile.UserID,
+		profile.FirstName,
+		profile.LastName,
+		(*NullTime)(&profile.DateOfBirth),
+		profile.Gender,
+		profile.Height,
+		profile.Weight,
+		profile.Units.Weight,
+		profile.Units.Length,
+		profile.Units.Distance,
+		profile.Timezone,
+		profile.Version,
+		(*NullTime)(&profile.CreatedAt),
+		(*NullTime)(&profile.UpdatedAt),
+	}
+
+	if err := tx.QueryRowxContext(ctx, query, args...).Scan(&profile.ID); err != nil {
+		return err
+	}
+
+	if measured {
+		return nil
+	}
+	return recordProfileWeight(ctx, tx, profile.Weight)
+}
+
+func findProfileByID(ctx context.Context, tx *Tx, id uint) (*fwt.Profile, error) {
+	a, _, err := findProfiles(ctx, tx, fwt.ProfileFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Profile not found."}
+	}
+	return a[0], nil
+}
+
+func findProfileByUserID(ctx context.Context, tx *Tx, userID uint) (*fwt.Profile, error) {
+	a, _, err := findProfiles(ctx, tx, fwt.ProfileFilter{UserID: &userID})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Profile not found."}
+	}
+	return a[0], nil
+}
+
+// findUserLocation returns the location of the user's timezone, which is
+// UTC for users without a profile.
+func findUserLocation(ctx context.Context, tx *Tx, userID uint) (*time.Location, error) {
+	profile, err := findProfileByUserID(ctx, tx, userID)
+	if fwt.ErrorCode(err) == fwt.ENOTFOUND {
+		return time.UTC, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return profile.Location(), nil
+}
+
+func findProfiles(ctx context.Context, tx *Tx, filter fwt.ProfileFilter) (_ []*fwt.Profile, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+	if v := filter.FirstName; v != nil {
+		where, args = append(where, "first_name = ?"), append(args, *v)
+	}
+	if v := filter.LastName; v != nil {
+		where, args = append(where, "last_name = ?"), append(args, *v)
+	}
+	if v := filter.DateOfBirth; v != nil {
+		where, args = append(where, "date_of_birth = ?"), append(args, (*NullTime)(v))
+	}
+	if v := filter.Gender; v != nil {
+		where, args = append(where, "gender = ?"), append(args, *v)
+	}
+	if v := filter.Height; v != nil {
+		where, args = append(where, "height = ?"), append(args, *v)
+	}
+	if v := filter.Weight; v != nil {
+		where, args = append(where, "weight = ?"), append(args, *v)
+	}
+
+	query := `
+	SELECT id, user_id, first_name, last_name, date_of_birth, gender, height, weight, weight_unit, length_unit, distance_unit, timezone, version, created_at, updated_at, COUNT(*) OVER()
+	FROM profile` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	profiles := make([]*fwt.Profile, 0)
+	for rows.Next() {
+		var profile fwt.Profile
+		if err := rows.Scan(
+			&profile.ID,
+			&profile.UserID,
+			&profile.FirstName,
+			&profile.LastName,
+			(*NullTime)(&profile.DateOfBirth),
+			&profile.Gender,
+			&profile.Height,
+			&profile.Weight,
+			&profile.Units.Weight,
+			&profile.Units.Length,
+			&profile.Units.Distance,
+			&profile.Timezone,
+			&profile.Version,
+			(*NullTime)(&profile.CreatedAt),
+			(*NullTime)(&profile.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		profiles = append(profiles, &profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return profiles, n, nil
+}
+
+func updateProfile(ctx context.Context, tx *Tx, id uint, upd fwt.ProfileUpdate) (*fwt.Profile, error) {
+	profile, err := findProfileByID(ctx, tx, id)
+	if err != nil {
+		return profile, err
+	} else if profile.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this profile.")
+	} else if v := upd.Version; v != nil && *v != profile.Version {
+		return profile, fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
+	}
+
+	if v := upd.FirstName; v != nil {
+		profile.FirstName = *v
+	}
+	if v := upd.LastName; v != nil {
+		profile.LastName = *v
+	}
+	if v := upd.DateOfBirth; v != nil {
+		profile.DateOfBirth = *v
+	}
+	if v := upd.Gender; v != nil {
+		profile.Gender = *v
+	}
+	if v := upd.Height; v != nil {
+		profile.Height = *v
+	}
+	if v := upd.Weight; v != nil && *v != profile.Weight {
+		if err := recordProfileWeight(ctx, tx, *v); err != nil {
+			return profile, err
+		}
+		profile.Weight = *v
+	}
+
+	if v := upd.Units; v != nil {
+		profile.Units = profile.Units.Merge(*v)
+	}
+
+	if v := upd.Timezone; v != nil {
+		profile.Timezone = *v
+	}
+
+	profile.UpdatedAt = tx.now
+
+	if err := profile.Validate(); err != nil {
+		return profile, err
+	}
+
+	query := `
+	UPDATE profile SET first_name = ?, last_name = ?, date_of_birth = ?, gender = ?, height = ?, weight = ?, weight_unit = ?, length_unit = ?, distance_unit = ?, timezone = ?, updated_at = ?, version = version + 1
+	WHERE id = ? AND user_id = ? AND version = ?
+	`
+	args := []interface{}{
+		profile.FirstName,
+		profile.LastName,
+		(*NullTime)(&profile.DateOfBirth),
+		profile.Gender,
+		profile.Height,
+		profile.Weight,
+		profile.Units.Weight,
+		profile.Units.Length,
+		profile.Units.Distance,
+		profile.Timezone,
+		(*NullTime)(&profile.UpdatedAt),
+		profile.ID,
+		profile.UserID,
+		profile.Version,
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return profile, err
+	} else if n, err := result.RowsAffected(); err != nil {
+		return profile, err
+	} else if n == 0 {
+		return profile, fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
+	}
+	profile.Version++
+
+	return profile, nil
+}
+
+func deleteProfile(ctx context.Context, tx *Tx, id uint, version *uint) error {
+	profile, err := findProfileByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if profile.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this profile.")
+	}
+
+	if version == nil {
+		version = &profile.Version
+	}
+
+	query := `
+	DELETE FROM profile WHERE id = ? AND user_id = ? AND version = ?
+	`
+	result, err := tx.ExecContext(ctx, query, profile.ID, profile.UserID, *version)
+	if err != nil {
+		return err
+	} else if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fwt.Errorf(fwt.ESTALE, "Profile has been modified since it was last fetched.")
+	}
+
+	return nil
+}
+
+// recordProfileWeight adds a weight set through the profile to the body
+// measurement history, so that it is not lost when it changes again.
+func recordProfileWeight(ctx context.Context, tx *Tx, weight float64) error {
+	if weight <= 0 {
+		return nil
+	}
+	return createBodyMeasurement(ctx, tx, &fwt.BodyMeasurement{Weight: weight})
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/maliByatzes/fwt"
+)
+
+func createSet(ctx context.Context, tx *Tx, set *fwt.Set) error {
+	set.CreatedAt = tx.now
+
+	if err := set.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO exercise_set (workout_exercise_id, reps, load, created_at)
+	VALUES (?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		set.WorkoutExerciseID,
+		set.Reps,
+		set.Load,
+		(*NullTime)(&set.CreatedAt),
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&set.ID)
+}
+
+// findSetsByWorkoutIDs returns the sets of the given workouts keyed by the
+// ID of their workout exercise.
+func findSetsByWorkoutIDs(ctx context.Context, tx *Tx, ids []interface{}) (map[uint][]*fwt.Set, error) {
+	query := `
+	SELECT s.id, s.workout_exercise_id, s.reps, s.load, s.created_at
+	FROM exercise_set AS s
+	INNER JOIN workout_exercise AS we ON we.id = s.workout_exercise_id
+	WHERE we.workout_id IN (?` + strings.Repeat(", ?", len(ids)-1) + `)
+	ORDER BY s.id ASC`
+
+	rows, err := tx.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sets := make(map[uint][]*fwt.Set)
+	for rows.Next() {
+		var set fwt.Set
+		if err := rows.Scan(
+			&set.ID,
+			&set.WorkoutExerciseID,
+			&set.Reps,
+			&set.Load,
+			(*NullTime)(&set.CreatedAt),
+		); err != nil {
+			return nil, err
+		}
+
+		sets[set.WorkoutExerciseID] = append(sets[set.WorkoutExerciseID], &set)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
@@ -0,0 +1,379 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.UserService = (*UserService)(nil)
+
+type UserService struct {
+	db *DB
+}
+
+func NewUserService(db *DB) *UserService {
+	return &UserService{db: db}
+}
+
+func (s *UserService) FindUserByID(ctx context.Context, id uint) (*fwt.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findUserByID(ctx, tx, id)
+}
+
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (*fwt.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := findUserByUsername(ctx, tx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.VerifyPassword(password, user.HashedPassword); err != nil {
+		return nil, &fwt.Error{Code: fwt.ENOTAUTHORIZED, Message: "Incorrect credentials"}
+	}
+
+	return user, nil
+}
+
+func (s *UserService) FindUsers(ctx context.Context, filter fwt.UserFilter) ([]*fwt.User, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findUsers(ctx, tx, filter)
+}
+
+func (s *UserService) CreateUser(ctx context.Context, user *fwt.User) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createUser(ctx, tx, user); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := updateUser(ctx, tx, id, upd)
+	if err != nil {
+		return user, err
+	} else if err := tx.Commit(); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteUser(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *UserService) ScheduleUserDeletion(ctx context.Context, id uint) (*fwt.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := setUserDeletedAt(ctx, tx, id, tx.now)
+	if err != nil {
+		return user, err
+	} else if err := tx.Commit(); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) RestoreUser(ctx context.Context, id uint) (*fwt.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := setUserDeletedAt(ctx, tx, id, time.Time{})
+	if err != nil {
+		return user, err
+	} else if err := tx.Commit(); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) PurgeDeletedUser(ctx context.Context, id uint, before time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := purgeDeletedUser(ctx, tx, id, before); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createUser(ctx context.Context, tx *Tx, user *fwt.User) error {
+	user.CreatedAt = tx.now
+	user.UpdatedAt = user.CreatedAt
+
+	if err := user.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO "user" (username, email, hashed_password, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{user.Username, user.Email, user.HashedPassword, (*NullTime)(&user.CreatedAt), (*NullTime)(&user.UpdatedAt)}
+
+	err := tx.QueryRowxContext(ctx, query, args...).Scan(&user.ID)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "user.username"):
+			return fwt.Errorf(fwt.ECONFLICT, "This username already exists.")
+		case isUniqueViolation(err, "user.email"):
+			return fwt.Errorf(fwt.ECONFLICT, "This email already exists.")
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findUserByID(ctx context.Context, tx *Tx, id uint) (*fwt.User, error) {
+	a, _, err := findUsers(ctx, tx, fwt.UserFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "User not found."}
+	}
+	return a[0], nil
+}
+
+func findUserByUsername(ctx context.Context, tx *Tx, username string) (*fwt.User, error) {
+	a, _, err := findUsers(ctx, tx, fwt.UserFilter{Username: &username})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "User not found."}
+	}
+	return a[0], nil
+}
+
+func findUsers(ctx context.Context, tx *Tx, filter fwt.UserFilter) (_ []*fwt.User, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.Username; v != nil {
+		where, args = append(where, "username = ?"), append(args, *v)
+	}
+	if v := filter.Email; v != nil {
+		where, args = append(where, "email = ?"), append(args, *v)
+	}
+	if v := filter.DeletedBefore; v != nil {
+		where, args = append(where, "deleted_at IS NOT NULL AND deleted_at <= ?"), append(args, (*NullTime)(v))
+	}
+
+	query := `SELECT id, username, email, hashed_password, created_at, updated_at, deleted_at, COUNT(*) OVER() FROM "user"` + formatWhereClause(where) +
+		` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	users := make([]*fwt.User, 0)
+	for rows.Next() {
+		var user fwt.User
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.HashedPassword,
+			(*NullTime)(&user.CreatedAt),
+			(*NullTime)(&user.UpdatedAt),
+			(*NullTime)(&user.DeletedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, n, nil
+}
+
+func updateUser(ctx context.Context, tx *Tx, id uint, upd fwt.UserUpdate) (*fwt.User, error) {
+	user, err := findUserByID(ctx, tx, id)
+	if err != nil {
+		return user, err
+	} else if user.ID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this user.")
+	}
+
+	if v := upd.Username; v != nil {
+		user.Username = *v
+	}
+	if v := upd.Email; v != nil {
+		user.Email = *v
+	}
+
+	user.UpdatedAt = tx.now
+
+	if err := user.Validate(); err != nil {
+		return user, err
+	}
+
+	query := `
+	UPDATE "user" SET username = ?, email = ?, updated_at = ?
+	WHERE id = ?
+	`
+	args := []interface{}{
+		user.Username,
+		user.Email,
+		(*NullTime)(&user.UpdatedAt),
+		user.ID,
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		switch {
+		case isUniqueViolation(err, "user.username"):
+			return user, fwt.Errorf(fwt.ECONFLICT, "This username already exists.")
+		case isUniqueViolation(err, "user.email"):
+			return user, fwt.Errorf(fwt.ECONFLICT, "This email already exists.")
+		default:
+			return user, err
+		}
+	}
+
+	return user, nil
+}
+
+func deleteUser(ctx context.Context, tx *Tx, id uint) error {
+	if user, err := findUserByID(ctx, tx, id); err != nil {
+		return err
+	} else if user.ID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this user")
+	}
+
+	return purgeUser(ctx, tx, id)
+}
+
+// setUserDeletedAt schedules the deletion of a user when deletedAt is set
+// and cancels it otherwise.
+func setUserDeletedAt(ctx context.Context, tx *Tx, id uint, deletedAt time.Time) (*fwt.User, error) {
+	user, err := findUserByID(ctx, tx, id)
+	if err != nil {
+		return user, err
+	} else if user.ID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this user.")
+	}
+
+	if !deletedAt.IsZero() && user.Deleted() {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User is already scheduled for deletion.")
+	} else if deletedAt.IsZero() && !user.Deleted() {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User is not scheduled for deletion.")
+	} else if deletedAt.IsZero() && tx.now.After(user.PurgeAt()) {
+		return user, fwt.Errorf(fwt.ECONFLICT, "User can no longer be restored as the deletion grace period has ended.")
+	}
+
+	user.DeletedAt = deletedAt
+	user.UpdatedAt = tx.now
+
+	query := `
+	UPDATE "user" SET deleted_at = ?, updated_at = ?
+	WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, (*NullTime)(&user.DeletedAt), (*NullTime)(&user.UpdatedAt), id); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// purgeDeletedUser removes a user deleted before the given time. The check
+// is made again here so that a user restored since being found is kept.
+func purgeDeletedUser(ctx context.Context, tx *Tx, id uint, before time.Time) error {
+	user, err := findUserByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if !user.Deleted() || user.DeletedAt.After(before) {
+		return fwt.Errorf(fwt.ECONFLICT, "User is not due to be purged.")
+	}
+
+	return purgeUser(ctx, tx, id)
+}
+
+// purgeUser removes a user and everything they own, children before their
+// parents, so that no foreign key is violated and no row is left behind.
+func purgeUser(ctx context.Context, tx *Tx, id uint) error {
+	const userWorkoutExercises = `
+	SELECT we.id FROM workout_exercise we
+	JOIN workout w ON w.id = we.workout_id
+	WHERE w.user_id = ?
+	`
+
+	for _, query := range []string{
+		`DELETE FROM exercise_set WHERE workout_exercise_id IN (` + userWorkoutExercises + `)`,
+		`DELETE FROM workout_exercise_status WHERE workout_exercise_id IN (` + userWorkoutExercises + `)`,
+		`DELETE FROM workout_exercise WHERE workout_id IN (SELECT id FROM workout WHERE user_id = ?)`,
+		`DELETE FROM workout WHERE user_id = ?`,
+		`DELETE FROM goal WHERE user_id = ?`,
+		`DELETE FROM body_measurement WHERE user_id = ?`,
+		`DELETE FROM profile WHERE user_id = ?`,
+		`DELETE FROM calendar_feed WHERE user_id = ?`,
+		`DELETE FROM idempotency_key WHERE user_id = ?`,
+		`DELETE FROM "user" WHERE id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
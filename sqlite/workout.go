@@ -0,0 +1,750 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WorkoutService = (*WorkoutService)(nil)
+
+type WorkoutService struct {
+	db *DB
+}
+
+func NewWorkoutService(db *DB) *WorkoutService {
+	return &WorkoutService{db: db}
+}
+
+func (s *WorkoutService) FindWorkoutByID(ctx context.Context, id uint) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findWorkoutByID(ctx, tx, id)
+}
+
+func (s *WorkoutService) FindWorkoutByIDUserID(ctx context.Context, id uint, userID uint) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findWorkoutByIDUserID(ctx, tx, id, userID)
+}
+
+func (s *WorkoutService) FindWorkouts(ctx context.Context, filter fwt.WorkoutFilter) ([]*fwt.Workout, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findWorkouts(ctx, tx, filter)
+}
+
+func (s *WorkoutService) CreateWorkout(ctx context.Context, workout *fwt.Workout) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createWorkout(ctx, tx, workout); err != nil {
+		return err
+	}
+
+	for _, ex := range workout.Exercises {
+		exercise, err := findExerciseByName(ctx, tx, ex.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := addExerciseToWorkout(ctx, tx, workout, exercise); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *WorkoutService) UpdateWorkout(ctx context.Context, id uint, upd fwt.WorkoutUpdate) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	workout, err := updateWorkout(ctx, tx, id, upd)
+	if err != nil {
+		return workout, err
+	} else if err := tx.Commit(); err != nil {
+		return workout, err
+	}
+
+	return workout, nil
+}
+
+func (s *WorkoutService) RemoveExercisesFromWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	workout, err := findWorkoutByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to modify this workout.")
+	}
+
+	for _, exName := range exercises {
+		for index, e := range workout.Exercises {
+			if e.Name == exName {
+				if err := removeExerciseFromWorkout(ctx, tx, workout, e); err != nil {
+					return workout, err
+				}
+
+				workout.Exercises = append(workout.Exercises[:index], workout.Exercises[index+1:]...)
+				break
+			}
+		}
+	}
+
+	if err := touchWorkout(ctx, tx, workout); err != nil {
+		return workout, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return workout, err
+	}
+
+	return workout, nil
+}
+
+func (s *WorkoutService) AddExercisesToWorkout(ctx context.Context, id uint, exercises []string) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	workout, err := findWorkoutByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to modify this workout.")
+	}
+
+	for _, exName := range exercises {
+		exercise, err := findExerciseByName(ctx, tx, exName)
+		if err != nil {
+			return workout, err
+		}
+
+		if !hasExercise(workout.Exercises, exercise) {
+			if err := addExerciseToWorkout(ctx, tx, workout, exercise); err != nil {
+				return workout, err
+			}
+
+			workout.Exercises = append(workout.Exercises, exercise)
+		}
+	}
+
+	if err := touchWorkout(ctx, tx, workout); err != nil {
+		return workout, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return workout, err
+	}
+
+	return workout, nil
+}
+
+func (s *WorkoutService) StartWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	workout, err := startWorkout(ctx, tx, id)
+	if err != nil {
+		return workout, err
+	} else if err := tx.Commit(); err != nil {
+		return workout, err
+	}
+
+	return workout, nil
+}
+
+func (s *WorkoutService) FinishWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	workout, err := finishWorkout(ctx, tx, id)
+	if err != nil {
+		return workout, err
+	} else if err := tx.Commit(); err != nil {
+		return workout, err
+	}
+
+	return workout, nil
+}
+
+func (s *WorkoutService) SkipWorkout(ctx context.Context, id uint) (*fwt.Workout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	workout, err := skipWorkout(ctx, tx, id)
+	if err != nil {
+		return workout, err
+	} else if err := tx.Commit(); err != nil {
+		return workout, err
+	}
+
+	return workout, nil
+}
+
+func (s *WorkoutService) LogWorkout(ctx context.Context, workout *fwt.Workout) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := logWorkout(ctx, tx, workout); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *WorkoutService) QuickStartWorkout(ctx context.Context, workout *fwt.Workout) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	loc, err := findUserLocation(ctx, tx, fwt.UserIDFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	workout.QuickStart(tx.now.In(loc))
+	if err := createWorkout(ctx, tx, workout); err != nil {
+		return err
+	}
+
+	for _, ex := range workout.Exercises {
+		exercise, err := findExerciseByName(ctx, tx, ex.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := addExerciseToWorkout(ctx, tx, workout, exercise); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *WorkoutService) DeleteWorkout(ctx context.Context, id uint, version *uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteWorkout(ctx, tx, id, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
+	if fwt.UserFromContext(ctx) == nil {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must be logged to create workout")
+	}
+	workout.UserID = fwt.UserIDFromContext(ctx)
+
+	workout.Version = 1
+	if workout.Status == "" {
+		workout.Status = fwt.WorkoutStatusPlanned
+	}
+	workout.ScheduledDate = fwt.Date(workout.ScheduledDate)
+	workout.CreatedAt = tx.now
+	workout.UpdatedAt = workout.CreatedAt
+
+	loc, err := findUserLocation(ctx, tx, workout.UserID)
+	if err != nil {
+		return err
+	} else if err := workout.Validate(tx.now.In(loc)); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO workout (user_id, name, scheduled_date, status, started_at, finished_at, version, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		workout.UserID,
+		workout.Name,
+		(*NullTime)(&workout.ScheduledDate),
+		workout.Status,
+		(*NullTime)(&workout.StartedAt),
+		(*NullTime)(&workout.FinishedAt),
+		workout.Version,
+		(*NullTime)(&workout.CreatedAt),
+		(*NullTime)(&workout.UpdatedAt),
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&workout.ID)
+}
+
+// logWorkout records a workout that already happened. Each exercise is
+// stored with its logged status and sets.
+func logWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
+	loc, err := findUserLocation(ctx, tx, fwt.UserIDFromContext(ctx))
+	if err != nil {
+		return err
+	} else if err := workout.Log(tx.now.In(loc)); err != nil {
+		return err
+	} else if err := createWorkout(ctx, tx, workout); err != nil {
+		return err
+	}
+
+	for _, ex := range workout.Exercises {
+		exercise, err := findExerciseByName(ctx, tx, ex.Name)
+		if err != nil {
+			return err
+		}
+
+		we := &fwt.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, Order: 1}
+		if err := createWorkoutExercise(ctx, tx, we); err != nil {
+			return err
+		}
+
+		status, err := findWEStatusByWEID(ctx, tx, we.ID)
+		if err != nil {
+			return err
+		}
+		if ex.Status, err = updateWEStatus(ctx, tx, status.ID, fwt.WEStatusUpdate{
+			Status:      &ex.Status.Status,
+			Comments:    &ex.Status.Comments,
+			CompletedAt: &ex.Status.CompletedAt,
+		}); err != nil {
+			return err
+		}
+
+		for _, set := range ex.Sets {
+			set.WorkoutExerciseID = we.ID
+			if err := createSet(ctx, tx, set); err != nil {
+				return err
+			}
+		}
+		ex.ID, ex.Description = exercise.ID, exercise.Description
+		ex.CreatedAt, ex.UpdatedAt = exercise.CreatedAt, exercise.UpdatedAt
+	}
+
+	return nil
+}
+
+func findWorkoutByID(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
+	a, _, err := findWorkouts(ctx, tx, fwt.WorkoutFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Workout not found."}
+	}
+	return a[0], nil
+}
+
+func findWorkoutByIDUserID(ctx context.Context, tx *Tx, id uint, userID uint) (*fwt.Workout, error) {
+	a, _, err := findWorkouts(ctx, tx, fwt.WorkoutFilter{ID: &id, UserID: &userID})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, &fwt.Error{Code: fwt.ENOTFOUND, Message: "Workout not found."}
+	}
+	return a[0], nil
+}
+
+// findWorkouts pages over workouts and then loads the exercises of the
+// returned page in a second query, so that the limit and the total count
+// apply to workouts rather than to workout/exercise pairs.
+func findWorkouts(ctx context.Context, tx *Tx, filter fwt.WorkoutFilter) (_ []*fwt.Workout, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+	if v := filter.Name; v != nil {
+		where, args = append(where, "name = ?"), append(args, *v)
+	}
+	if v := filter.ScheduledDate; v != nil {
+		where, args = append(where, "scheduled_date = ?"), append(args, (*NullTime)(v))
+	}
+	if v := filter.From; v != nil {
+		where, args = append(where, "scheduled_date >= ?"), append(args, (*NullTime)(v))
+	}
+	if v := filter.To; v != nil {
+		where, args = append(where, "scheduled_date <= ?"), append(args, (*NullTime)(v))
+	}
+	if v := filter.Status; v != nil {
+		where, args = append(where, "status = ?"), append(args, *v)
+	}
+
+	query := `
+	SELECT id, user_id, name, scheduled_date, status, started_at, finished_at, version, created_at, updated_at, COUNT(*) OVER()
+	FROM workout` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	workouts := make([]*fwt.Workout, 0)
+	byID := make(map[uint]*fwt.Workout)
+	for rows.Next() {
+		var workout fwt.Workout
+		if err := rows.Scan(
+			&workout.ID,
+			&workout.UserID,
+			&workout.Name,
+			(*NullTime)(&workout.ScheduledDate),
+			&workout.Status,
+			(*NullTime)(&workout.StartedAt),
+			(*NullTime)(&workout.FinishedAt),
+			&workout.Version,
+			(*NullTime)(&workout.CreatedAt),
+			(*NullTime)(&workout.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		workouts = append(workouts, &workout)
+		byID[workout.ID] = &workout
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	rows.Close()
+
+	if len(workouts) == 0 {
+		return workouts, n, nil
+	}
+
+	ids := make([]interface{}, 0, len(workouts))
+	for _, w := range workouts {
+		ids = append(ids, w.ID)
+	}
+
+	query = `
+	SELECT we.workout_id, e.id, e.name, e.description, e.created_at, e.updated_at,
+		s.id, we.id, s.status, s.comments, s.completed_at, s.created_at, s.updated_at
+	FROM workout_exercise AS we
+	INNER JOIN exercise AS e ON e.id = we.exercise_id
+	LEFT JOIN workout_exercise_status AS s ON s.workout_exercise_id = we.id
+	WHERE we.workout_id IN (?` + strings.Repeat(", ?", len(ids)-1) + `)
+	ORDER BY we.id ASC`
+
+	exRows, err := tx.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer exRows.Close()
+
+	byWE := make(map[uint]*fwt.Exercise)
+	for exRows.Next() {
+		var workoutID uint
+		var exercise fwt.Exercise
+		var status fwt.WEStatus
+		var statusID sql.NullInt64
+		var statusName, comments sql.NullString
+		if err := exRows.Scan(
+			&workoutID,
+			&exercise.ID,
+			&exercise.Name,
+			&exercise.Description,
+			(*NullTime)(&exercise.CreatedAt),
+			(*NullTime)(&exercise.UpdatedAt),
+			&statusID,
+			&status.WorkoutExerciseID,
+			&statusName,
+			&comments,
+			(*NullTime)(&status.CompletedAt),
+			(*NullTime)(&status.CreatedAt),
+			(*NullTime)(&status.UpdatedAt),
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if statusID.Valid {
+			status.ID = uint(statusID.Int64)
+			status.Status, status.Comments = statusName.String, comments.String
+			exercise.Status = &status
+		}
+
+		w := byID[workoutID]
+		w.Exercises = append(w.Exercises, &exercise)
+		byWE[status.WorkoutExerciseID] = &exercise
+	}
+	if err := exRows.Err(); err != nil {
+		return nil, 0, err
+	}
+	exRows.Close()
+
+	sets, err := findSetsByWorkoutIDs(ctx, tx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for weID, a := range sets {
+		if exercise := byWE[weID]; exercise != nil {
+			exercise.Sets = a
+		}
+	}
+
+	return workouts, n, nil
+}
+
+func updateWorkout(ctx context.Context, tx *Tx, id uint, upd fwt.WorkoutUpdate) (*fwt.Workout, error) {
+	workout, err := findWorkoutByID(ctx, tx, id)
+	if err != nil {
+		return workout, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this workout.")
+	} else if v := upd.Version; v != nil && *v != workout.Version {
+		return workout, fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
+	}
+
+	if v := upd.Name; v != nil {
+		workout.Name = *v
+	}
+	if v := upd.ScheduledDate; v != nil {
+		workout.ScheduledDate = fwt.Date(*v)
+	}
+	workout.UpdatedAt = tx.now
+
+	loc, err := findUserLocation(ctx, tx, workout.UserID)
+	if err != nil {
+		return workout, err
+	} else if err := workout.Validate(tx.now.In(loc)); err != nil {
+		return workout, err
+	}
+
+	query := `
+	UPDATE workout SET name = ?, scheduled_date = ?, updated_at = ?, version = version + 1
+	WHERE id = ? AND user_id = ? AND version = ?
+	`
+	args := []interface{}{
+		workout.Name,
+		(*NullTime)(&workout.ScheduledDate),
+		(*NullTime)(&workout.UpdatedAt),
+		workout.ID,
+		workout.UserID,
+		workout.Version,
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return workout, err
+	} else if n, err := result.RowsAffected(); err != nil {
+		return workout, err
+	} else if n == 0 {
+		return workout, fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
+	}
+	workout.Version++
+
+	return workout, nil
+}
+
+// touchWorkout bumps the version of a workout whose exercises have changed so
+// that cached copies are invalidated.
+func touchWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
+	query := `
+	UPDATE workout SET updated_at = ?, version = version + 1
+	WHERE id = ? RETURNING version
+	`
+	if err := tx.QueryRowxContext(ctx, query, (*NullTime)(&tx.now), workout.ID).Scan(&workout.Version); err != nil {
+		return err
+	}
+	workout.UpdatedAt = tx.now
+
+	return nil
+}
+
+func startWorkout(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
+	return transitionWorkout(ctx, tx, id, func(workout *fwt.Workout) (string, error) {
+		return fwt.WorkoutStatusInProgress, nil
+	})
+}
+
+func finishWorkout(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
+	return transitionWorkout(ctx, tx, id, func(workout *fwt.Workout) (string, error) {
+		statuses, _, err := findWEStatuses(ctx, tx, fwt.WEStatusFilter{WorkoutID: &workout.ID})
+		if err != nil {
+			return "", err
+		}
+		return fwt.FinishedWorkoutStatus(statuses), nil
+	})
+}
+
+func skipWorkout(ctx context.Context, tx *Tx, id uint) (*fwt.Workout, error) {
+	return transitionWorkout(ctx, tx, id, func(workout *fwt.Workout) (string, error) {
+		return fwt.WorkoutStatusSkipped, nil
+	})
+}
+
+// transitionWorkout moves a workout owned by the current user to the status
+// returned by next and saves it.
+func transitionWorkout(ctx context.Context, tx *Tx, id uint, next func(workout *fwt.Workout) (string, error)) (*fwt.Workout, error) {
+	workout, err := findWorkoutByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return nil, fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to update this workout.")
+	}
+
+	status, err := next(workout)
+	if err != nil {
+		return workout, err
+	} else if err := workout.Transition(status, tx.now); err != nil {
+		return workout, err
+	}
+
+	return workout, updateWorkoutStatus(ctx, tx, workout)
+}
+
+// updateWorkoutStatus saves the status, start and finish times of a workout
+// after a transition and bumps its version.
+func updateWorkoutStatus(ctx context.Context, tx *Tx, workout *fwt.Workout) error {
+	workout.UpdatedAt = tx.now
+
+	query := `
+	UPDATE workout SET status = ?, started_at = ?, finished_at = ?, updated_at = ?, version = version + 1
+	WHERE id = ? RETURNING version
+	`
+	args := []interface{}{
+		workout.Status,
+		(*NullTime)(&workout.StartedAt),
+		(*NullTime)(&workout.FinishedAt),
+		(*NullTime)(&workout.UpdatedAt),
+		workout.ID,
+	}
+
+	return tx.QueryRowxContext(ctx, query, args...).Scan(&workout.Version)
+}
+
+// completeWorkout completes the workout of a workout exercise once all of
+// its exercises are completed. Workouts that are already finished or
+// skipped are left alone.
+func completeWorkout(ctx context.Context, tx *Tx, workoutExerciseID uint) error {
+	we, err := findWorkoutExerciseByID(ctx, tx, workoutExerciseID)
+	if err != nil {
+		return err
+	}
+
+	statuses, _, err := findWEStatuses(ctx, tx, fwt.WEStatusFilter{WorkoutID: &we.WorkoutID})
+	if err != nil {
+		return err
+	} else if !fwt.AllExercisesCompleted(statuses) {
+		return nil
+	}
+
+	workout, err := findWorkoutByID(ctx, tx, we.WorkoutID)
+	if err != nil {
+		return err
+	} else if err := workout.Transition(fwt.WorkoutStatusCompleted, tx.now); err != nil {
+		return nil
+	}
+
+	return updateWorkoutStatus(ctx, tx, workout)
+}
+
+func removeExerciseFromWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout, exercise *fwt.Exercise) error {
+	a, _, err := findWorkoutExercises(ctx, tx, fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID, ExerciseID: &exercise.ID})
+	if err != nil {
+		return err
+	} else if len(a) == 0 {
+		return fwt.Errorf(fwt.ENOTFOUND, "Workout_Exercise not found.")
+	}
+
+	return deleteWorkoutExercise(ctx, tx, a[0].ID)
+}
+
+func addExerciseToWorkout(ctx context.Context, tx *Tx, workout *fwt.Workout, exercise *fwt.Exercise) error {
+	return createWorkoutExercise(ctx, tx, &fwt.WorkoutExercise{
+		WorkoutID:  workout.ID,
+		ExerciseID: exercise.ID,
+		Order:      1, // Hard-code for now ...
+	})
+}
+
+func deleteWorkout(ctx context.Context, tx *Tx, id uint, version *uint) error {
+	workout, err := findWorkoutByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if workout.UserID != fwt.UserIDFromContext(ctx) {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You are not allowed to delete this workout.")
+	}
+
+	workoutExercises, _, err := findWorkoutExercises(ctx, tx, fwt.WorkoutExerciseFilter{WorkoutID: &workout.ID})
+	if err != nil {
+		return err
+	}
+
+	for _, we := range workoutExercises {
+		if err := deleteWorkoutExercise(ctx, tx, we.ID); err != nil {
+			return err
+		}
+	}
+
+	if version == nil {
+		version = &workout.Version
+	}
+
+	query := `
+	DELETE FROM workout WHERE id = ? AND user_id = ? AND version = ?
+	`
+	result, err := tx.ExecContext(ctx, query, workout.ID, workout.UserID, *version)
+	if err != nil {
+		return err
+	} else if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fwt.Errorf(fwt.ESTALE, "Workout has been modified since it was last fetched.")
+	}
+
+	return nil
+}
+
+func hasExercise(exs []*fwt.Exercise, ex *fwt.Exercise) bool {
+	for _, value := range exs {
+		if value.Name == ex.Name {
+			return true
+		}
+	}
+	return false
+}
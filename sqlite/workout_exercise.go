@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WorkoutExerciseService = (*WorkoutExerciseService)(nil)
+
+type WorkoutExerciseService struct {
+	db *DB
+}
+
+func NewWorkoutExerciseService(db *DB) *WorkoutExerciseService {
+	return &WorkoutExerciseService{db: db}
+}
+
+func (s *WorkoutExerciseService) FindWorkoutExerciseByID(ctx context.Context, id uint) (*fwt.WorkoutExercise, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findWorkoutExerciseByID(ctx, tx, id)
+}
+
+func (s *WorkoutExerciseService) FindWorkoutExercises(ctx context.Context, filter fwt.WorkoutExerciseFilter) ([]*fwt.WorkoutExercise, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findWorkoutExercises(ctx, tx, filter)
+}
+
+func (s *WorkoutExerciseService) CreateWorkoutExercise(ctx context.Context, workoutExercise *fwt.WorkoutExercise) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createWorkoutExercise(ctx, tx, workoutExercise); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *WorkoutExerciseService) UpdateWorkoutExercise(ctx context.Context, id uint, upd fwt.WorkoutExerciseUpdate) (*fwt.WorkoutExercise, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	we, err := updateWorkoutExercise(ctx, tx, id, upd)
+	if err != nil {
+		return we, err
+	} else if err := tx.Commit(); err != nil {
+		return we, err
+	}
+
+	return we, nil
+}
+
+func (s *WorkoutExerciseService) DeleteWorkoutExercise(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteWorkoutExercise(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// createWorkoutExercise links an exercise to a workout and gives it an
+// initial "pending" status.
+func createWorkoutExercise(ctx context.Context, tx *Tx, workoutExercise *fwt.WorkoutExercise) error {
+	workoutExercise.CreatedAt = tx.now
+	workoutExercise.UpdatedAt = workoutExercise.CreatedAt
+
+	if err := workoutExercise.Validate(); err != nil {
+		return err
+	}
+
+	exercise, err := findExerciseByID(ctx, tx, workoutExercise.ExerciseID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO workout_exercise (workout_id, exercise_id, "order", created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		workoutExercise.WorkoutID,
+		exercise.ID,
+		workoutExercise.Order,
+		(*NullTime)(&workoutExercise.CreatedAt),
+		(*NullTime)(&workoutExercise.UpdatedAt),
+	}
+
+	if err := tx.QueryRowxContext(ctx, query, args...).Scan(&workoutExercise.ID); err != nil {
+		return err
+	}
+
+	return createWEStatus(ctx, tx, &fwt.WEStatus{
+		WorkoutExerciseID: workoutExercise.ID,
+		Status:            fwt.WEStatusPending,
+	})
+}
+
+func findWorkoutExercises(ctx context.Context, tx *Tx, filter fwt.WorkoutExerciseFilter) (_ []*fwt.WorkoutExercise, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.WorkoutID; v != nil {
+		where, args = append(where, "workout_id = ?"), append(args, *v)
+	}
+	if v := filter.ExerciseID; v != nil {
+		where, args = append(where, "exercise_id = ?"), append(args, *v)
+	}
+	if v := filter.Order; v != nil {
+		where, args = append(where, `"order" = ?`), append(args, *v)
+	}
+
+	query := `
+	SELECT id, workout_id, exercise_id, "order", created_at, updated_at, COUNT(*) OVER()
+	FROM workout_exercise` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	workoutExercises := make([]*fwt.WorkoutExercise, 0)
+	for rows.Next() {
+		var workoutExercise fwt.WorkoutExercise
+		if err := rows.Scan(
+			&workoutExercise.ID,
+			&workoutExercise.WorkoutID,
+			&workoutExercise.ExerciseID,
+			&workoutExercise.Order,
+			(*NullTime)(&workoutExercise.CreatedAt),
+			(*NullTime)(&workoutExercise.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		workoutExercises = append(workoutExercises, &workoutExercise)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return workoutExercises, n, nil
+}
+
+func findWorkoutExerciseByID(ctx context.Context, tx *Tx, id uint) (*fwt.WorkoutExercise, error) {
+	a, _, err := findWorkoutExercises(ctx, tx, fwt.WorkoutExerciseFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "Workout Exercise not found.")
+	}
+	return a[0], nil
+}
+
+func updateWorkoutExercise(ctx context.Context, tx *Tx, id uint, upd fwt.WorkoutExerciseUpdate) (*fwt.WorkoutExercise, error) {
+	we, err := findWorkoutExerciseByID(ctx, tx, id)
+	if err != nil {
+		return we, err
+	}
+
+	if v := upd.Order; v != nil {
+		we.Order = *v
+	}
+	we.UpdatedAt = tx.now
+
+	if err := we.Validate(); err != nil {
+		return we, err
+	}
+
+	query := `
+	UPDATE workout_exercise SET "order" = ?, updated_at = ?
+	WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, we.Order, (*NullTime)(&we.UpdatedAt), we.ID); err != nil {
+		return we, err
+	}
+
+	return we, nil
+}
+
+func deleteWorkoutExercise(ctx context.Context, tx *Tx, id uint) error {
+	workoutExercise, err := findWorkoutExerciseByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	DELETE FROM exercise_set WHERE workout_exercise_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, workoutExercise.ID); err != nil {
+		return err
+	}
+
+	query = `
+	DELETE FROM workout_exercise_status WHERE workout_exercise_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, workoutExercise.ID); err != nil {
+		return err
+	}
+
+	query = `
+	DELETE FROM workout_exercise WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/maliByatzes/fwt"
+)
+
+var _ fwt.WEStatusService = (*WEStatusService)(nil)
+
+type WEStatusService struct {
+	db *DB
+}
+
+func NewWEStatusService(db *DB) *WEStatusService {
+	return &WEStatusService{db: db}
+}
+
+func (s *WEStatusService) FindWEStatusByID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findWEStatusByID(ctx, tx, id)
+}
+
+func (s *WEStatusService) FindWEStatusByWEID(ctx context.Context, id uint) (*fwt.WEStatus, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return findWEStatusByWEID(ctx, tx, id)
+}
+
+func (s *WEStatusService) FindWEStatuses(ctx context.Context, filter fwt.WEStatusFilter) ([]*fwt.WEStatus, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	return findWEStatuses(ctx, tx, filter)
+}
+
+func (s *WEStatusService) CreateWEStatus(ctx context.Context, we *fwt.WEStatus) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createWEStatus(ctx, tx, we); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *WEStatusService) UpdateWEStatus(ctx context.Context, id uint, upd fwt.WEStatusUpdate) (*fwt.WEStatus, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	we, err := updateWEStatus(ctx, tx, id, upd)
+	if err != nil {
+		return nil, err
+	} else if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return we, nil
+}
+
+func (s *WEStatusService) DeleteWEStatus(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteWEStatus(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createWEStatus(ctx context.Context, tx *Tx, we *fwt.WEStatus) error {
+	if fwt.UserFromContext(ctx) == nil {
+		return fwt.Errorf(fwt.ENOTAUTHORIZED, "You must logged in to create westatus.")
+	}
+
+	we.CreatedAt = tx.now
+	we.UpdatedAt = we.CreatedAt
+
+	if err := we.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO workout_exercise_status (workout_exercise_id, status, comments, completed_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?) RETURNING id
+	`
+	args := []interface{}{
+		we.WorkoutExerciseID,
+		we.Status,
+		we.Comments,
+		(*NullTime)(&we.CompletedAt),
+		(*NullTime)(&we.CreatedAt),
+		(*NullTime)(&we.UpdatedAt),
+	}
+
+	err := tx.QueryRowxContext(ctx, query, args...).Scan(&we.ID)
+	if isUniqueViolation(err, "workout_exercise_status.workout_exercise_id") {
+		return fwt.Errorf(fwt.ECONFLICT, "WEStatus already exists.")
+	}
+	return err
+}
+
+func findWEStatuses(ctx context.Context, tx *Tx, filter fwt.WEStatusFilter) (_ []*fwt.WEStatus, n int, err error) {
+	where, args := []string{}, []interface{}{}
+
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.WorkoutExerciseID; v != nil {
+		where, args = append(where, "workout_exercise_id = ?"), append(args, *v)
+	}
+	if v := filter.Status; v != nil {
+		where, args = append(where, "status = ?"), append(args, *v)
+	}
+	if v := filter.WorkoutID; v != nil {
+		where, args = append(where, "workout_exercise_id IN (SELECT id FROM workout_exercise WHERE workout_id = ?)"), append(args, *v)
+	}
+
+	query := `
+	SELECT id, workout_exercise_id, status, comments, completed_at, created_at, updated_at, COUNT(*) OVER()
+	FROM workout_exercise_status` + formatWhereClause(where) + ` ORDER BY id ASC` + formatLimitOffset(filter.Limit, filter.Offset)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, n, err
+	}
+	defer rows.Close()
+
+	weStatuses := make([]*fwt.WEStatus, 0)
+	for rows.Next() {
+		var weStatus fwt.WEStatus
+		if err := rows.Scan(
+			&weStatus.ID,
+			&weStatus.WorkoutExerciseID,
+			&weStatus.Status,
+			&weStatus.Comments,
+			(*NullTime)(&weStatus.CompletedAt),
+			(*NullTime)(&weStatus.CreatedAt),
+			(*NullTime)(&weStatus.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, n, err
+		}
+
+		weStatuses = append(weStatuses, &weStatus)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return weStatuses, n, nil
+}
+
+func findWEStatusByID(ctx context.Context, tx *Tx, id uint) (*fwt.WEStatus, error) {
+	a, _, err := findWEStatuses(ctx, tx, fwt.WEStatusFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "WEStatus not found.")
+	}
+	return a[0], nil
+}
+
+func findWEStatusByWEID(ctx context.Context, tx *Tx, id uint) (*fwt.WEStatus, error) {
+	a, _, err := findWEStatuses(ctx, tx, fwt.WEStatusFilter{WorkoutExerciseID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(a) == 0 {
+		return nil, fwt.Errorf(fwt.ENOTFOUND, "WEStatus not found.")
+	}
+	return a[0], nil
+}
+
+func updateWEStatus(ctx context.Context, tx *Tx, id uint, upd fwt.WEStatusUpdate) (*fwt.WEStatus, error) {
+	we, err := findWEStatusByID(ctx, tx, id)
+	if err != nil {
+		return we, err
+	}
+
+	if v := upd.Status; v != nil {
+		we.Status = *v
+	}
+	if v := upd.Comments; v != nil {
+		we.Comments = *v
+	}
+	if v := upd.CompletedAt; v != nil {
+		we.CompletedAt = *v
+	}
+
+	we.UpdatedAt = tx.now
+
+	if err := we.Validate(); err != nil {
+		return we, err
+	}
+
+	query := `
+	UPDATE workout_exercise_status SET status = ?, comments = ?, completed_at = ?, updated_at = ?
+	WHERE id = ?
+	`
+	args := []interface{}{
+		we.Status,
+		we.Comments,
+		(*NullTime)(&we.CompletedAt),
+		(*NullTime)(&we.UpdatedAt),
+		we.ID,
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return we, err
+	}
+
+	// Statuses are returned with their workout, so any change to them is a
+	// new version of it.
+	workoutExercise, err := findWorkoutExerciseByID(ctx, tx, we.WorkoutExerciseID)
+	if err != nil {
+		return we, err
+	} else if err := touchWorkout(ctx, tx, &fwt.Workout{ID: workoutExercise.WorkoutID}); err != nil {
+		return we, err
+	}
+
+	if we.Status == fwt.WEStatusCompleted {
+		if err := completeWorkout(ctx, tx, we.WorkoutExerciseID); err != nil {
+			return we, err
+		}
+	}
+
+	return we, nil
+}
+
+func deleteWEStatus(ctx context.Context, tx *Tx, id uint) error {
+	if _, err := findWEStatusByID(ctx, tx, id); err != nil {
+		return err
+	}
+
+	query := `
+	DELETE FROM workout_exercise_status WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package fwt
+
+import (
+	"time"
+
+	// Embed the timezone database so that every IANA name can be loaded
+	// whatever the host has installed.
+	_ "time/tzdata"
+)
+
+// DefaultTimezone is the timezone of users who have not chosen one.
+const DefaultTimezone = "UTC"
+
+// LoadLocation returns the location of an IANA timezone name. An empty
+// name stands for DefaultTimezone.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, Errorf(EINVALID, "Timezone is invalid.")
+	}
+	return loc, nil
+}
+
+// Date returns the calendar date of t in t's own location, as midnight UTC.
+// Dates such as ScheduledDate are stored this way, so two dates compare
+// equal whatever timezone they were entered in.
+func Date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,27 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone, ServiceName: "fwt"})
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("Stdout", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), Config{Exporter: ExporterStdout, ServiceName: "fwt"})
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("ErrUnknownExporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), Config{Exporter: "jaeger"})
+		require.Error(t, err)
+	})
+}
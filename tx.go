@@ -0,0 +1,32 @@
+package fwt
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TxRunner runs several service calls as one unit of work. Service calls
+// made with the context passed to fn join a single transaction, which is
+// committed when fn returns nil and rolled back otherwise. A RunInTx call
+// nested inside another joins the outer transaction.
+//
+// The context passed to fn must not be used concurrently.
+type TxRunner interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// RunInTxOptions is like RunInTx but starts the transaction with opts.
+	// The options are ignored when ctx already carries a transaction.
+	RunInTxOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error
+}
+
+// TxOptions configures the transaction of a unit of work. Backends that
+// only offer one isolation level ignore Isolation.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	// Retry runs fn again, with backoff, when the transaction is aborted by
+	// a serialization failure or a deadlock. Only set it when fn has no
+	// effects outside of the transaction.
+	Retry bool
+}
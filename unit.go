@@ -0,0 +1,153 @@
+package fwt
+
+import "strings"
+
+// Quantities are stored in kilograms, centimetres and kilometres. Units
+// only affect how they are read from and written to API clients.
+const (
+	kilogramsPerPound  = 0.45359237
+	centimetresPerInch = 2.54
+	kilometresPerMile  = 1.609344
+)
+
+type WeightUnit string
+
+const (
+	Kilogram WeightUnit = "kg"
+	Pound    WeightUnit = "lb"
+)
+
+// ToKilograms converts v from u to kilograms.
+func (u WeightUnit) ToKilograms(v float64) float64 {
+	if u == Pound {
+		return v * kilogramsPerPound
+	}
+	return v
+}
+
+// FromKilograms converts v from kilograms to u.
+func (u WeightUnit) FromKilograms(v float64) float64 {
+	if u == Pound {
+		return v / kilogramsPerPound
+	}
+	return v
+}
+
+type LengthUnit string
+
+const (
+	Centimetre LengthUnit = "cm"
+	Inch       LengthUnit = "in"
+)
+
+// ToCentimetres converts v from u to centimetres.
+func (u LengthUnit) ToCentimetres(v float64) float64 {
+	if u == Inch {
+		return v * centimetresPerInch
+	}
+	return v
+}
+
+// FromCentimetres converts v from centimetres to u.
+func (u LengthUnit) FromCentimetres(v float64) float64 {
+	if u == Inch {
+		return v / centimetresPerInch
+	}
+	return v
+}
+
+type DistanceUnit string
+
+const (
+	Kilometre DistanceUnit = "km"
+	Mile      DistanceUnit = "mi"
+)
+
+// ToKilometres converts v from u to kilometres.
+func (u DistanceUnit) ToKilometres(v float64) float64 {
+	if u == Mile {
+		return v * kilometresPerMile
+	}
+	return v
+}
+
+// FromKilometres converts v from kilometres to u.
+func (u DistanceUnit) FromKilometres(v float64) float64 {
+	if u == Mile {
+		return v / kilometresPerMile
+	}
+	return v
+}
+
+// Units is a choice of unit for each kind of quantity. An empty field
+// leaves the choice to another Units it is merged onto.
+type Units struct {
+	Weight   WeightUnit   `json:"weight"`
+	Length   LengthUnit   `json:"length"`
+	Distance DistanceUnit `json:"distance"`
+}
+
+var (
+	MetricUnits   = Units{Weight: Kilogram, Length: Centimetre, Distance: Kilometre}
+	ImperialUnits = Units{Weight: Pound, Length: Inch, Distance: Mile}
+)
+
+func (u Units) Validate() error {
+	if u.Weight != Kilogram && u.Weight != Pound {
+		return Errorf(EINVALID, "Weight unit is invalid.")
+	}
+
+	if u.Length != Centimetre && u.Length != Inch {
+		return Errorf(EINVALID, "Length unit is invalid.")
+	}
+
+	if u.Distance != Kilometre && u.Distance != Mile {
+		return Errorf(EINVALID, "Distance unit is invalid.")
+	}
+
+	return nil
+}
+
+// Merge returns u with the non-empty fields of other replacing its own.
+func (u Units) Merge(other Units) Units {
+	if other.Weight != "" {
+		u.Weight = other.Weight
+	}
+	if other.Length != "" {
+		u.Length = other.Length
+	}
+	if other.Distance != "" {
+		u.Distance = other.Distance
+	}
+	return u
+}
+
+// ParseUnits parses a comma separated list of unit systems ("metric",
+// "imperial") and unit symbols such as "lb" or "cm". Later entries win,
+// so "metric,lb" selects pounds with otherwise metric units. Kinds of
+// quantity that are not mentioned are left empty.
+func ParseUnits(s string) (Units, error) {
+	var u Units
+	if strings.TrimSpace(s) == "" {
+		return u, nil
+	}
+
+	for _, v := range strings.Split(s, ",") {
+		switch v = strings.ToLower(strings.TrimSpace(v)); v {
+		case "metric":
+			u = u.Merge(MetricUnits)
+		case "imperial":
+			u = u.Merge(ImperialUnits)
+		case string(Kilogram), string(Pound):
+			u.Weight = WeightUnit(v)
+		case string(Centimetre), string(Inch):
+			u.Length = LengthUnit(v)
+		case string(Kilometre), string(Mile):
+			u.Distance = DistanceUnit(v)
+		default:
+			return Units{}, Errorf(EINVALID, "Unit is invalid.")
+		}
+	}
+
+	return u, nil
+}
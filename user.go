@@ -7,6 +7,12 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// UserDeletionGracePeriod is how long a deleted account can be restored
+// before it is removed for good with all the data it owns.
+const UserDeletionGracePeriod = 30 * 24 * time.Hour
+
+// User is an account. DeletedAt is set while the account is scheduled for
+// deletion; such a user cannot sign in until the account is restored.
 type User struct {
 	ID             uint      `json:"id"`
 	Username       string    `json:"username,omitempty"`
@@ -14,6 +20,20 @@ type User struct {
 	HashedPassword string    `json:"-" db:"hashed_password"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	DeletedAt      time.Time `json:"deleted_at"`
+}
+
+// Deleted reports whether the account is scheduled for deletion.
+func (u *User) Deleted() bool {
+	return !u.DeletedAt.IsZero()
+}
+
+// PurgeAt returns when an account scheduled for deletion is removed.
+func (u *User) PurgeAt() time.Time {
+	if !u.Deleted() {
+		return time.Time{}
+	}
+	return u.DeletedAt.Add(UserDeletionGracePeriod)
 }
 
 func (u *User) Validate() error {
@@ -46,7 +66,22 @@ type UserService interface {
 	FindUsers(ctx context.Context, filter UserFilter) ([]*User, int, error)
 	CreateUser(ctx context.Context, user *User) error
 	UpdateUser(ctx context.Context, id uint, upd UserUpdate) (*User, error)
+
+	// DeleteUser removes the user at once, together with their profile,
+	// workouts and everything else they own, in a single transaction.
 	DeleteUser(ctx context.Context, id uint) error
+
+	// ScheduleUserDeletion marks the user as deleted. RestoreUser cancels
+	// it until PurgeAt; after that PurgeDeletedUser removes the user as
+	// DeleteUser does.
+	ScheduleUserDeletion(ctx context.Context, id uint) (*User, error)
+	RestoreUser(ctx context.Context, id uint) (*User, error)
+
+	// PurgeDeletedUser removes the user if they were deleted before the
+	// given time, in a transaction of its own, and fails with ECONFLICT
+	// otherwise. It needs no user in ctx. The users that are due can be
+	// found with the DeletedBefore filter.
+	PurgeDeletedUser(ctx context.Context, id uint, before time.Time) error
 }
 
 type UserFilter struct {
@@ -54,6 +89,10 @@ type UserFilter struct {
 	Username *string `json:"username"`
 	Email    *string `json:"email"`
 
+	// DeletedBefore restricts the users to those scheduled for deletion
+	// at or before the time.
+	DeletedBefore *time.Time `json:"deleted_before"`
+
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 }
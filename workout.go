@@ -2,20 +2,97 @@ package fwt
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
 	"time"
 )
 
+const (
+	WorkoutStatusPlanned            = "planned"
+	WorkoutStatusInProgress         = "in_progress"
+	WorkoutStatusCompleted          = "completed"
+	WorkoutStatusPartiallyCompleted = "partially_completed"
+	WorkoutStatusSkipped            = "skipped"
+)
+
+// workoutTransitions lists the statuses a workout may move to from each
+// status. Completed, partially completed and skipped workouts are final.
+var workoutTransitions = map[string][]string{
+	WorkoutStatusPlanned:    {WorkoutStatusInProgress, WorkoutStatusCompleted, WorkoutStatusSkipped},
+	WorkoutStatusInProgress: {WorkoutStatusCompleted, WorkoutStatusPartiallyCompleted},
+}
+
 type Workout struct {
 	ID            uint        `json:"id"`
 	UserID        uint        `json:"user_id"`
 	Name          string      `json:"name"`
 	ScheduledDate time.Time   `json:"scheduled_date"`
+	Status        string      `json:"status"`
+	StartedAt     time.Time   `json:"started_at"`
+	FinishedAt    time.Time   `json:"finished_at"`
+	Version       uint        `json:"version"`
 	CreatedAt     time.Time   `json:"created_at"`
 	UpdatedAt     time.Time   `json:"updated_at"`
 	Exercises     []*Exercise `json:"exercises"`
 }
 
-func (w *Workout) Validate() error {
+// Duration returns how long the workout took, or zero if it has not been
+// both started and finished.
+func (w *Workout) Duration() time.Duration {
+	if w.StartedAt.IsZero() || w.FinishedAt.IsZero() {
+		return 0
+	}
+	return w.FinishedAt.Sub(w.StartedAt)
+}
+
+func (w Workout) MarshalJSON() ([]byte, error) {
+	type workout Workout
+	return json.Marshal(struct {
+		workout
+		Duration int64 `json:"duration_seconds"`
+	}{workout(w), int64(w.Duration().Seconds())})
+}
+
+// Transition moves the workout to status at now, recording when it was
+// started and finished. It fails with ECONFLICT if the move is not allowed.
+func (w *Workout) Transition(status string, now time.Time) error {
+	if !slices.Contains(workoutTransitions[w.Status], status) {
+		return Errorf(ECONFLICT, "Workout cannot be moved from %s to %s.", w.Status, status)
+	}
+
+	switch status {
+	case WorkoutStatusInProgress:
+		w.StartedAt = now
+	case WorkoutStatusCompleted, WorkoutStatusPartiallyCompleted:
+		if w.StartedAt.IsZero() {
+			w.StartedAt = now
+		}
+		w.FinishedAt = now
+	}
+	w.Status = status
+
+	return nil
+}
+
+// FinishedWorkoutStatus returns the status a workout is finished with given
+// the statuses of its exercises.
+func FinishedWorkoutStatus(statuses []*WEStatus) string {
+	for _, wes := range statuses {
+		if wes.Status != WEStatusCompleted {
+			return WorkoutStatusPartiallyCompleted
+		}
+	}
+	return WorkoutStatusCompleted
+}
+
+// AllExercisesCompleted reports whether every exercise status is completed.
+func AllExercisesCompleted(statuses []*WEStatus) bool {
+	return len(statuses) > 0 && FinishedWorkoutStatus(statuses) == WorkoutStatusCompleted
+}
+
+// Validate checks the workout at now, which must be in the user's
+// timezone so that "today" is the user's today.
+func (w *Workout) Validate(now time.Time) error {
 	if w.UserID <= uint(0) {
 		return Errorf(EINVALID, "UserID is required.")
 	}
@@ -28,7 +105,18 @@ func (w *Workout) Validate() error {
 		return Errorf(EINVALID, "Scheduled Date is required.")
 	}
 
-	if !w.ScheduledDate.After(time.Now()) {
+	if !isWorkoutStatus(w.Status) {
+		return Errorf(EINVALID, "Status is invalid.")
+	}
+
+	// Only planned workouts must not lie in the past and must name their
+	// exercises; logged and quick started workouts are recorded as they
+	// happen.
+	if w.Status != WorkoutStatusPlanned {
+		return nil
+	}
+
+	if Date(w.ScheduledDate).Before(Date(now)) {
 		return Errorf(EINVALID, "Scheduled Date is invalid.")
 	}
 
@@ -39,6 +127,50 @@ func (w *Workout) Validate() error {
 	return nil
 }
 
+// Log prepares a workout that already happened to be recorded at now, in
+// the user's timezone. Exercises without a status are completed, and the
+// workout is completed when all of its exercises are and partially
+// completed otherwise.
+func (w *Workout) Log(now time.Time) error {
+	w.ScheduledDate = Date(w.ScheduledDate)
+	if w.ScheduledDate.After(Date(now)) {
+		return Errorf(EINVALID, "Scheduled Date of a logged workout cannot be in the future.")
+	}
+
+	if len(w.Exercises) == 0 {
+		return Errorf(EINVALID, "Exercises must contain at least 1 exercise.")
+	}
+
+	if !w.StartedAt.IsZero() && w.FinishedAt.Before(w.StartedAt) {
+		return Errorf(EINVALID, "Finished At cannot be before Started At.")
+	}
+
+	statuses := make([]*WEStatus, 0, len(w.Exercises))
+	for _, ex := range w.Exercises {
+		if ex.Status == nil {
+			ex.Status = &WEStatus{Status: WEStatusCompleted}
+		} else if ex.Status.Status == WEStatusPending {
+			return Errorf(EINVALID, "Exercises of a logged workout cannot be pending.")
+		}
+		statuses = append(statuses, ex.Status)
+	}
+	w.Status = FinishedWorkoutStatus(statuses)
+
+	return nil
+}
+
+// QuickStart prepares an ad-hoc workout scheduled for the day of now, in
+// the user's timezone, and started right away.
+func (w *Workout) QuickStart(now time.Time) {
+	if w.Name == "" {
+		w.Name = "Quick workout"
+	}
+	w.ScheduledDate = Date(now)
+	w.Status = WorkoutStatusInProgress
+	w.StartedAt = now
+	w.FinishedAt = time.Time{}
+}
+
 type WorkoutService interface {
 	FindWorkoutByID(context.Context, uint) (*Workout, error)
 	FindWorkoutByIDUserID(context.Context, uint, uint) (*Workout, error)
@@ -47,14 +179,44 @@ type WorkoutService interface {
 	UpdateWorkout(context.Context, uint, WorkoutUpdate) (*Workout, error)
 	RemoveExercisesFromWorkout(context.Context, uint, []string) (*Workout, error)
 	AddExercisesToWorkout(context.Context, uint, []string) (*Workout, error)
-	DeleteWorkout(context.Context, uint) error
+
+	// DeleteWorkout deletes the workout. When the version is set it must
+	// match the current version of the workout or the delete fails with
+	// ESTALE.
+	DeleteWorkout(ctx context.Context, id uint, version *uint) error
+
+	// StartWorkout, FinishWorkout and SkipWorkout move a workout through
+	// its lifecycle. A finished workout is completed when all of its
+	// exercises are completed and partially completed otherwise.
+	StartWorkout(context.Context, uint) (*Workout, error)
+	FinishWorkout(context.Context, uint) (*Workout, error)
+	SkipWorkout(context.Context, uint) (*Workout, error)
+
+	// LogWorkout records a workout that already happened together with the
+	// statuses and sets of its exercises. QuickStartWorkout creates a
+	// workout for today and starts it right away.
+	LogWorkout(context.Context, *Workout) error
+	QuickStartWorkout(context.Context, *Workout) error
+}
+
+func isWorkoutStatus(status string) bool {
+	switch status {
+	case WorkoutStatusPlanned, WorkoutStatusInProgress, WorkoutStatusCompleted, WorkoutStatusPartiallyCompleted, WorkoutStatusSkipped:
+		return true
+	}
+	return false
 }
 
+// WorkoutFilter selects workouts. From and To bound ScheduledDate
+// inclusively.
 type WorkoutFilter struct {
 	ID            *uint      `json:"id"`
 	UserID        *uint      `json:"user_id"`
 	Name          *string    `json:"name"`
 	ScheduledDate *time.Time `json:"scheduled_date"`
+	From          *time.Time `json:"from"`
+	To            *time.Time `json:"to"`
+	Status        *string    `json:"status"`
 
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
@@ -63,4 +225,8 @@ type WorkoutFilter struct {
 type WorkoutUpdate struct {
 	Name          *string    `json:"name"`
 	ScheduledDate *time.Time `json:"scheduled_date"`
+
+	// Version, when set, must match the current version of the workout
+	// or the update fails with ESTALE.
+	Version *uint `json:"version"`
 }
@@ -5,10 +5,17 @@ import (
 	"time"
 )
 
+const (
+	WEStatusPending            = "pending"
+	WEStatusCompleted          = "completed"
+	WEStatusPartiallyCompleted = "partially_completed"
+	WEStatusSkipped            = "skipped"
+)
+
 type WEStatus struct {
 	ID                uint      `json:"id"`
 	WorkoutExerciseID uint      `json:"workout_exercise_id"`
-	Status            string    `json:"status"` // `binding:"oneof=pending,completed"`
+	Status            string    `json:"status"`
 	Comments          string    `json:"comments"`
 	CompletedAt       time.Time `json:"completed_at"`
 	CreatedAt         time.Time `json:"created_at"`
@@ -20,8 +27,12 @@ func (wes *WEStatus) Validate() error {
 		return Errorf(EINVALID, "WorkoutExerciseID is required.")
 	}
 
-	if wes.Status == "" {
+	switch wes.Status {
+	case "":
 		return Errorf(EINVALID, "Status is required.")
+	case WEStatusPending, WEStatusCompleted, WEStatusPartiallyCompleted, WEStatusSkipped:
+	default:
+		return Errorf(EINVALID, "Status is invalid.")
 	}
 
 	return nil
@@ -39,6 +50,7 @@ type WEStatusService interface {
 type WEStatusFilter struct {
 	ID                *uint   `json:"id"`
 	WorkoutExerciseID *uint   `json:"workout_exercise_id"`
+	WorkoutID         *uint   `json:"workout_id"`
 	Status            *string `json:"status"`
 
 	Offset int `json:"offset"`